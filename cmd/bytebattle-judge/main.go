@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"bytebattle/internal/config"
+	"bytebattle/internal/executor"
+)
+
+func main() {
+	cfg := config.LoadJudge()
+
+	execCfg := executor.LoadConfigOrDefault(cfg.ExecutorConfig)
+	if execCfg.Backend == executor.BackendRemote {
+		log.Fatalf("judge worker cannot use the %q executor backend", executor.BackendRemote)
+	}
+	exec, err := executor.New(execCfg)
+	if err != nil {
+		log.Fatalf("failed to create executor: %v", err)
+	}
+	if cfg.Token == "" && !isLoopback(cfg.HTTPAddr) {
+		log.Fatalf("JUDGE_TOKEN must be set when the judge listens on %s", cfg.HTTPAddr)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	srv := &http.Server{
+		Addr:              cfg.HTTPAddr,
+		Handler:           executor.NewJudgeHandler(exec, cfg.Token),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		log.Printf("Judge started on %s", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("server error: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer shutdownCancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("shutdown error: %v", err)
+	}
+	log.Printf("Judge shut down")
+}
+
+// isLoopback reports whether addr only accepts connections from this host.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -42,9 +42,10 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
+	router := app.NewRouter(pool, cfg)
 	srv := &http.Server{
 		Addr:              cfg.HTTPAddr,
-		Handler:           app.NewRouter(pool, cfg),
+		Handler:           router,
 		ReadHeaderTimeout: 10 * time.Second,
 	}
 
@@ -62,5 +63,8 @@ func main() {
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		log.Printf("shutdown error: %v", err)
 	}
+	if err := router.Shutdown(shutdownCtx); err != nil {
+		log.Printf("submission workers shutdown error: %v", err)
+	}
 	log.Printf("Server shut down")
 }
@@ -42,6 +42,16 @@ export interface UserStats {
   wins: number
   games_played: number
   problems_solved: number
+  rating: number
+  rating_history: RatingChange[]
+}
+
+export interface RatingChange {
+  game_id: number
+  place: number
+  old_rating: number
+  new_rating: number
+  created_at: string
 }
 
 export const getMyStats = () => apiFetch<UserStats>('/auth/me/stats')
@@ -1,10 +1,12 @@
-import { apiFetch } from './client'
+import { apiFetch, ApiError } from './client'
 
 export interface ExecuteResponse {
   stdout: string
   stderr: string
   exit_code: number
   time_used_ms: number
+  cpu_time_ms: number
+  memory_used_kb: number
 }
 
 export const runCode = (code: string, language: string, input: string) =>
@@ -12,3 +14,67 @@ export const runCode = (code: string, language: string, input: string) =>
     method: 'POST',
     body: JSON.stringify({ code, language, input }),
   })
+
+export interface OutputChunk {
+  stream: 'stdout' | 'stderr'
+  data: string
+}
+
+// runCodeStream runs code like runCode, calling onOutput with the program's
+// output while it runs. The endpoint answers with server-sent events.
+export async function runCodeStream(
+  code: string,
+  language: string,
+  input: string,
+  onOutput: (chunk: OutputChunk) => void,
+): Promise<ExecuteResponse> {
+  const token = localStorage.getItem('token')
+  const res = await fetch('/api/execute/stream', {
+    method: 'POST',
+    headers: {
+      'Content-Type': 'application/json',
+      ...(token ? { Authorization: `Bearer ${token}` } : {}),
+    },
+    body: JSON.stringify({ code, language, input }),
+  })
+
+  if (res.status === 401) {
+    localStorage.removeItem('token')
+    window.dispatchEvent(new Event('unauthorized'))
+    throw new ApiError('UNAUTHORIZED', 'Unauthorized', 401)
+  }
+  if (!res.ok || !res.body) {
+    const body = await res.json().catch(() => ({}))
+    throw new ApiError(
+      body.error_code ?? 'UNKNOWN_ERROR',
+      body.message ?? 'Unknown error',
+      res.status,
+    )
+  }
+
+  const reader = res.body.pipeThrough(new TextDecoderStream()).getReader()
+  let buffer = ''
+  for (;;) {
+    const { value, done } = await reader.read()
+    if (done) break
+    buffer += value
+    let end
+    while ((end = buffer.indexOf('\n\n')) >= 0) {
+      const raw = buffer.slice(0, end)
+      buffer = buffer.slice(end + 2)
+      let event = ''
+      let data = ''
+      for (const line of raw.split('\n')) {
+        if (line.startsWith('event: ')) event = line.slice(7)
+        else if (line.startsWith('data: ')) data = line.slice(6)
+      }
+      const payload = JSON.parse(data)
+      if (event === 'output') onOutput(payload as OutputChunk)
+      else if (event === 'result') return payload as ExecuteResponse
+      else if (event === 'error') {
+        throw new ApiError(payload.error_code ?? 'UNKNOWN_ERROR', payload.message ?? 'Unknown error', 200)
+      }
+    }
+  }
+  throw new ApiError('UNKNOWN_ERROR', 'Соединение прервано', 0)
+}
@@ -3,8 +3,12 @@ import { apiFetch } from './client'
 export interface GameParticipant {
   id: string
   name?: string | null
+  // Best score per problem, only in scoring games.
+  scores?: number[] | null
 }
 
+export type GameMode = 'race' | 'scoring'
+
 export interface Game {
   id: number
   problem_ids: string[]
@@ -12,6 +16,7 @@ export interface Game {
   status: 'pending' | 'active' | 'finished' | 'cancelled'
   is_public: boolean
   is_solo: boolean
+  mode: GameMode
   invite_token?: string | null
   time_limit_minutes?: number | null
   participants: GameParticipant[]
@@ -34,6 +39,7 @@ export const createGame = (
   isPublic = true,
   isSolo = false,
   timeLimitMinutes?: number | null,
+  mode: GameMode = 'race',
 ) =>
   apiFetch<{ game: Game }>('/games', {
     method: 'POST',
@@ -42,6 +48,7 @@ export const createGame = (
       is_public: isPublic,
       is_solo: isSolo,
       time_limit_minutes: timeLimitMinutes ?? undefined,
+      mode,
     }),
   })
 
@@ -1,5 +1,15 @@
 import { apiFetch } from './client'
 
+export interface ProblemSample {
+  input: string
+  output: string
+}
+
+export interface ProblemGroup {
+  name: string
+  points: number
+}
+
 export interface Problem {
   id: string
   title: string
@@ -8,6 +18,11 @@ export interface Problem {
   time_limit_ms: number
   memory_limit_mb: number
   test_count?: number
+  samples?: ProblemSample[]
+  max_score?: number
+  groups?: ProblemGroup[]
+  // Set for problems where players implement a function, keyed by language.
+  starter_code?: Record<string, string>
 }
 
 export interface MyProblem {
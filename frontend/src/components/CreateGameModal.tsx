@@ -1,6 +1,6 @@
 import { useCallback, useEffect, useRef, useState } from 'react'
 import { listProblems, listMyProblems, getProblem, type Problem, type MyProblem } from '@/api/problems'
-import { createGame } from '@/api/games'
+import { createGame, type GameMode } from '@/api/games'
 import { ApiError } from '@/api/client'
 import { errorMessage } from '@/lib/errors'
 import { difficultyLabel, difficultyClass } from '@/lib/difficulty'
@@ -36,6 +36,7 @@ export function CreateGameModal({ open, onClose, onCreated, initialSelected, def
 
   const [isSolo, setIsSolo] = useState(false)
   const [isPublic, setIsPublic] = useState(true)
+  const [mode, setMode] = useState<GameMode>('race')
   const [timerOption, setTimerOption] = useState<TimerOption>(null)
   const [customMinutes, setCustomMinutes] = useState('')
   const [creating, setCreating] = useState(false)
@@ -54,6 +55,7 @@ export function CreateGameModal({ open, onClose, onCreated, initialSelected, def
     setDropdownVisible(false)
     setIsSolo(defaultIsSolo ?? false)
     setIsPublic(true)
+    setMode('race')
     setTimerOption(null)
     setCustomMinutes('')
     setCreating(false)
@@ -144,7 +146,7 @@ export function CreateGameModal({ open, onClose, onCreated, initialSelected, def
   }, [])
 
   const resolvedTimeLimitMinutes = (): number | null => {
-    if (!isSolo || timerOption === null) return null
+    if ((!isSolo && mode !== 'scoring') || timerOption === null) return null
     if (timerOption === 'custom') {
       const n = parseInt(customMinutes, 10)
       return isNaN(n) ? null : n
@@ -159,6 +161,9 @@ export function CreateGameModal({ open, onClose, onCreated, initialSelected, def
       return !isNaN(n) && n >= 1 && n <= 300
     })()
 
+  // Scoring games end when time runs out, so they need a timer.
+  const timerMissing = !isSolo && mode === 'scoring' && timerOption === null
+
   const handleCreate = async () => {
     if (selectedItems.length === 0) return
     setCreating(true)
@@ -169,6 +174,7 @@ export function CreateGameModal({ open, onClose, onCreated, initialSelected, def
         isSolo ? false : isPublic,
         isSolo,
         resolvedTimeLimitMinutes(),
+        isSolo ? 'race' : mode,
       )
       onCreated(res.game.id, isSolo, res.game.invite_token ?? undefined)
     } catch (err) {
@@ -177,6 +183,55 @@ export function CreateGameModal({ open, onClose, onCreated, initialSelected, def
     }
   }
 
+  const timerPicker = (allowNone: boolean) => (
+    <div className="flex flex-col gap-2 mt-4">
+      <label className="text-sm text-muted-foreground">Таймер</label>
+      <div className="flex flex-wrap gap-2">
+        {([15, 30, 60, 90, 120] as const).map(m => (
+          <button
+            key={m}
+            type="button"
+            onClick={() => setTimerOption(m)}
+            className={`px-3 py-1.5 rounded-md text-xs border transition-colors ${
+              timerOption === m
+                ? 'border-primary bg-primary/10 text-foreground'
+                : 'border-border text-muted-foreground hover:text-foreground'
+            }`}
+          >
+            {formatTimer(m)}
+          </button>
+        ))}
+        <input
+          type="text"
+          inputMode="numeric"
+          pattern="[0-9]*"
+          value={customMinutes}
+          onChange={e => { setCustomMinutes(e.target.value.replace(/\D/g, '')); setTimerOption('custom') }}
+          onFocus={() => setTimerOption('custom')}
+          placeholder="__ мин"
+          className={`w-20 px-3 py-1.5 rounded-md text-xs border transition-colors focus:outline-none ${
+            timerOption === 'custom'
+              ? 'border-primary bg-primary/10 text-foreground'
+              : 'border-border text-muted-foreground placeholder:text-muted-foreground/50'
+          }`}
+        />
+        {allowNone && (
+          <button
+            type="button"
+            onClick={() => setTimerOption(null)}
+            className={`px-3 py-1.5 rounded-md text-xs border transition-colors ${
+              timerOption === null
+                ? 'border-primary bg-primary/10 text-foreground'
+                : 'border-border text-muted-foreground hover:text-foreground'
+            }`}
+          >
+            Без таймера
+          </button>
+        )}
+      </div>
+    </div>
+  )
+
   if (!open) return null
 
   return (
@@ -272,7 +327,7 @@ export function CreateGameModal({ open, onClose, onCreated, initialSelected, def
               <button
                 key={String(solo)}
                 type="button"
-                onClick={() => { setIsSolo(solo); setTimerOption(null); setCustomMinutes('') }}
+                onClick={() => { setIsSolo(solo); setMode('race'); setTimerOption(null); setCustomMinutes('') }}
                 className={`flex-1 py-1.5 rounded-md text-xs border transition-colors ${
                   isSolo === solo
                     ? 'border-primary bg-primary/10 text-foreground'
@@ -287,73 +342,57 @@ export function CreateGameModal({ open, onClose, onCreated, initialSelected, def
 
         <div className="min-h-[7.5rem]">
         {isSolo ? (
+          timerPicker(true)
+        ) : (
+          <>
           <div className="flex flex-col gap-2 mt-4">
-            <label className="text-sm text-muted-foreground">Таймер</label>
-            <div className="flex flex-wrap gap-2">
-              {([15, 30, 60, 90, 120] as const).map(m => (
+            <label className="text-sm text-muted-foreground">Доступ</label>
+            <div className="flex gap-2">
+              {([true, false] as const).map(pub => (
                 <button
-                  key={m}
+                  key={String(pub)}
                   type="button"
-                  onClick={() => setTimerOption(m)}
-                  className={`px-3 py-1.5 rounded-md text-xs border transition-colors ${
-                    timerOption === m
+                  onClick={() => setIsPublic(pub)}
+                  className={`flex-1 py-1.5 rounded-md text-xs border transition-colors ${
+                    isPublic === pub
                       ? 'border-primary bg-primary/10 text-foreground'
                       : 'border-border text-muted-foreground hover:text-foreground'
                   }`}
                 >
-                  {formatTimer(m)}
+                  {pub ? 'Публичная' : 'Приватная'}
                 </button>
               ))}
-              <input
-                type="text"
-                inputMode="numeric"
-                pattern="[0-9]*"
-                value={customMinutes}
-                onChange={e => { setCustomMinutes(e.target.value.replace(/\D/g, '')); setTimerOption('custom') }}
-                onFocus={() => setTimerOption('custom')}
-                placeholder="__ мин"
-                className={`w-20 px-3 py-1.5 rounded-md text-xs border transition-colors focus:outline-none ${
-                  timerOption === 'custom'
-                    ? 'border-primary bg-primary/10 text-foreground'
-                    : 'border-border text-muted-foreground placeholder:text-muted-foreground/50'
-                }`}
-              />
-              <button
-                type="button"
-                onClick={() => setTimerOption(null)}
-                className={`px-3 py-1.5 rounded-md text-xs border transition-colors ${
-                  timerOption === null
-                    ? 'border-primary bg-primary/10 text-foreground'
-                    : 'border-border text-muted-foreground hover:text-foreground'
-                }`}
-              >
-                Без таймера
-              </button>
             </div>
+            <p className="text-xs text-muted-foreground/60">
+              {isPublic ? 'Видна в списке открытых игр' : 'Только по ссылке-приглашению'}
+            </p>
           </div>
-        ) : (
           <div className="flex flex-col gap-2 mt-4">
-            <label className="text-sm text-muted-foreground">Доступ</label>
+            <label className="text-sm text-muted-foreground">Подсчёт</label>
             <div className="flex gap-2">
-              {([true, false] as const).map(pub => (
+              {(['race', 'scoring'] as const).map(m => (
                 <button
-                  key={String(pub)}
+                  key={m}
                   type="button"
-                  onClick={() => setIsPublic(pub)}
+                  onClick={() => { setMode(m); setTimerOption(m === 'scoring' ? 30 : null); setCustomMinutes('') }}
                   className={`flex-1 py-1.5 rounded-md text-xs border transition-colors ${
-                    isPublic === pub
+                    mode === m
                       ? 'border-primary bg-primary/10 text-foreground'
                       : 'border-border text-muted-foreground hover:text-foreground'
                   }`}
                 >
-                  {pub ? 'Публичная' : 'Приватная'}
+                  {m === 'race' ? 'Гонка' : 'На очки'}
                 </button>
               ))}
             </div>
             <p className="text-xs text-muted-foreground/60">
-              {isPublic ? 'Видна в списке открытых игр' : 'Только по ссылке-приглашению'}
+              {mode === 'race'
+                ? 'Задачи решаются по очереди, побеждает первый решивший все'
+                : 'Задачи в любом порядке, за подзадачи начисляются баллы'}
             </p>
           </div>
+          {mode === 'scoring' && timerPicker(false)}
+          </>
         )}
         </div>
 
@@ -370,7 +409,7 @@ export function CreateGameModal({ open, onClose, onCreated, initialSelected, def
           <Button
             className="flex-1"
             onClick={handleCreate}
-            disabled={creating || selectedItems.length === 0 || !customMinutesValid}
+            disabled={creating || selectedItems.length === 0 || !customMinutesValid || timerMissing}
           >
             {creating ? 'Создаём...' : 'Создать →'}
           </Button>
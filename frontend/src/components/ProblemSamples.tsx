@@ -0,0 +1,35 @@
+import type { ProblemSample } from '@/api/problems'
+
+interface Props {
+  samples: ProblemSample[]
+}
+
+export function ProblemSamples({ samples }: Props) {
+  if (samples.length === 0) return null
+  return (
+    <div className="mt-4 space-y-3">
+      {samples.map((s, i) => (
+        <div key={i}>
+          <h3 className="text-sm font-semibold text-foreground/80 mb-1.5">
+            Пример {samples.length > 1 ? i + 1 : ''}
+          </h3>
+          <div className="grid grid-cols-2 gap-2">
+            <SampleBlock label="Ввод" text={s.input} />
+            <SampleBlock label="Вывод" text={s.output} />
+          </div>
+        </div>
+      ))}
+    </div>
+  )
+}
+
+export function SampleBlock({ label, text }: { label: string; text: string }) {
+  return (
+    <div>
+      <div className="text-xs text-muted-foreground mb-1">{label}</div>
+      <pre className="rounded-md bg-muted border border-border px-3 py-2 text-xs font-mono overflow-x-auto whitespace-pre">
+        {text}
+      </pre>
+    </div>
+  )
+}
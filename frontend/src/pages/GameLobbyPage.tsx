@@ -45,11 +45,42 @@ export function GameLobbyPage() {
     fetchGame()
   }, []) // eslint-disable-line react-hooks/exhaustive-deps
 
+  // Lobby changes arrive over the game's WebSocket; while it is down the game
+  // is refetched every few seconds instead.
   useEffect(() => {
-    if (game?.status !== 'pending') return
-    const timer = setInterval(fetchGame, 3000)
-    return () => clearInterval(timer)
-  }, [game?.status, fetchGame])
+    if (game?.status !== 'pending' || !authToken) return
+
+    let stopped = false
+    let ws: WebSocket | null = null
+    let retryTimeout: ReturnType<typeof setTimeout> | null = null
+
+    const connect = () => {
+      if (stopped) return
+      const proto = location.protocol === 'https:' ? 'wss:' : 'ws:'
+      ws = new WebSocket(`${proto}//${location.host}/api/games/${game.id}/ws`, [authToken])
+      ws.onmessage = (e) => {
+        try {
+          const msg = JSON.parse(e.data) as { type: string }
+          if (['participant_joined', 'participant_left', 'game_started', 'game_cancelled'].includes(msg.type)) {
+            fetchGame()
+          }
+        } catch { /* ignore malformed */ }
+      }
+      ws.onclose = () => {
+        if (stopped) return
+        fetchGame()
+        retryTimeout = setTimeout(connect, 3000)
+      }
+    }
+
+    connect()
+
+    return () => {
+      stopped = true
+      if (retryTimeout) clearTimeout(retryTimeout)
+      ws?.close()
+    }
+  }, [game?.status, game?.id, authToken, fetchGame])
 
   const handleJoin = async () => {
     if (!token) return
@@ -3,13 +3,14 @@ import { Link, useNavigate, useParams } from 'react-router-dom'
 import Editor from '@monaco-editor/react'
 import { getGame, timeoutGame, type Game } from '@/api/games'
 import { getProblem, type Problem } from '@/api/problems'
-import { runCode } from '@/api/execute'
+import { runCodeStream } from '@/api/execute'
 import { ApiError } from '@/api/client'
 import { errorMessage } from '@/lib/errors'
 import { pluralize, displayName } from '@/lib/utils'
 import { useAuth } from '@/context/AuthContext'
 import { Button } from '@/components/ui/button'
 import { ProblemDescription } from '@/components/ProblemDescription'
+import { ProblemSamples, SampleBlock } from '@/components/ProblemSamples'
 
 const LANGUAGES = [
   { value: 'python', label: 'Python', monaco: 'python' },
@@ -29,12 +30,32 @@ const DEFAULT_CODE: Record<LangValue, string> = {
   java: 'public class Main {\n    public static void main(String[] args) {\n        System.out.println("Hello, ByteBattle!");\n    }\n}\n',
 }
 
+interface GroupResult {
+  name: string
+  points: number
+  max_points: number
+  passed: boolean
+}
+
 interface SubmissionResult {
   accepted: boolean
   stdout: string
   stderr: string
   failed_test?: number
+  // input and expected are only sent when the failed test is a sample.
+  input?: string
+  expected?: string
   user_id: string
+  problem_index: number
+  score?: number
+  max_score?: number
+  groups?: GroupResult[]
+}
+
+interface RunResult {
+  verdict?: string
+  stdout: string
+  stderr: string
 }
 
 interface GameFinished {
@@ -52,6 +73,13 @@ interface PlayerState {
   problem_id: string
   problem_index: number
   progress: Record<string, number>
+  // Best score per problem of every player, only in scoring games.
+  scores?: Record<string, number[]>
+}
+
+interface ScoreUpdated {
+  user_id: string
+  scores: Record<string, number[]>
 }
 
 interface ServerError {
@@ -60,6 +88,16 @@ interface ServerError {
 }
 
 
+function loadCode(key: string): Record<LangValue, string> {
+  try {
+    const saved = localStorage.getItem(key)
+    if (saved) return { ...DEFAULT_CODE, ...JSON.parse(saved) }
+  } catch { /* ignore */ }
+  return { ...DEFAULT_CODE }
+}
+
+const sum = (xs: number[] | undefined) => (xs ?? []).reduce((a, b) => a + b, 0)
+
 function formatSeconds(totalSeconds: number): string {
   const m = Math.floor(totalSeconds / 60)
   const s = totalSeconds % 60
@@ -80,19 +118,18 @@ export function GamePage() {
 
   const [language, setLanguage] = useState<LangValue>('python')
   const languageRef = useRef<LangValue>('python')
+  // In scoring games players pick any problem; each keeps its own code.
+  const [problems, setProblems] = useState<Problem[]>([])
+  const [problemIndex, setProblemIndex] = useState(0)
+  const [scores, setScores] = useState<Record<string, number[]>>({})
   const storageKey = `bb_code_${gameId}`
-  const [codePerLang, setCodePerLang] = useState<Record<LangValue, string>>(() => {
-    try {
-      const saved = localStorage.getItem(storageKey)
-      if (saved) return { ...DEFAULT_CODE, ...JSON.parse(saved) }
-    } catch { /* ignore */ }
-    return { ...DEFAULT_CODE }
-  })
+  const codeKey = (index: number) => (index > 0 ? `${storageKey}_${index}` : storageKey)
+  const [codePerLang, setCodePerLang] = useState<Record<LangValue, string>>(() => loadCode(storageKey))
 
   const code = codePerLang[language]
   const setCode = (val: string) => setCodePerLang((prev) => {
     const next = { ...prev, [language]: val }
-    try { localStorage.setItem(storageKey, JSON.stringify(next)) } catch { /* ignore */ }
+    try { localStorage.setItem(codeKey(problemIndex), JSON.stringify(next)) } catch { /* ignore */ }
     return next
   })
   const [stdin, setStdin] = useState('')
@@ -114,6 +151,8 @@ export function GamePage() {
   const [countdown, setCountdown] = useState<number | null>(null)
 
   const wsRef = useRef<WebSocket | null>(null)
+  // Highest room event seq seen, sent as last_seq on reconnect to replay missed events.
+  const lastSeqRef = useRef<number | null>(null)
   const userIdRef = useRef<string | null>(userId)
   const gameRef = useRef<Game | null>(null)
   const notifTimerRef = useRef<ReturnType<typeof setTimeout> | null>(null)
@@ -123,6 +162,20 @@ export function GamePage() {
   useEffect(() => { userIdRef.current = userId }, [userId])
   useEffect(() => { gameRef.current = game }, [game])
 
+  // Function problems come with starter code, which replaces the code of
+  // languages the player has not touched yet.
+  useEffect(() => {
+    const starter = problem?.starter_code
+    if (!starter) return
+    setCodePerLang((prev) => {
+      const next = { ...prev }
+      for (const lang of Object.keys(next) as LangValue[]) {
+        if (starter[lang] && next[lang] === DEFAULT_CODE[lang]) next[lang] = starter[lang]
+      }
+      return next
+    })
+  }, [problem])
+
   const fetchGame = useCallback(async () => {
     try {
       const res = await getGame(gameId)
@@ -140,6 +193,13 @@ export function GamePage() {
         return
       }
       setGame(g)
+      if (g.mode === 'scoring') {
+        const all = await Promise.all(g.problem_ids.map((pid) => getProblem(pid)))
+        setProblems(all.map((r) => r.problem))
+        setProblem(all[0].problem)
+        setScores(Object.fromEntries(g.participants.map((p) => [p.id, p.scores ?? []])))
+        return
+      }
       const pRes = await getProblem(g.problem_ids[0])
       setProblem((prev) => {
         if (prev !== null && prev.id !== pRes.problem.id) {
@@ -188,7 +248,7 @@ export function GamePage() {
   }, [game?.started_at, game?.status])
 
   useEffect(() => {
-    if (!game?.is_solo || game.status !== 'active') return
+    if (game?.status !== 'active') return
 
     if (!game.started_at || game.time_limit_minutes == null) return
     const startedAt = new Date(game.started_at).getTime()
@@ -200,8 +260,11 @@ export function GamePage() {
       setSoloTimeDisplay(formatSeconds(remaining))
       setSoloRemainingSeconds(remaining)
       if (remaining === 0 && !timeoutCalledRef.current) {
-        timeoutCalledRef.current = true
         setTimedOut(true)
+        // Multiplayer games are finished by the server, which announces
+        // the winner with game_finished.
+        if (!game.is_solo) return
+        timeoutCalledRef.current = true
         timeoutGame(gameId).catch(() => {})
         setTimeout(() => navigate(`/games/${gameId}/results`, { replace: true }), 2000)
       }
@@ -212,6 +275,15 @@ export function GamePage() {
     return () => clearInterval(interval)
   }, [game?.is_solo, game?.status, game?.started_at, game?.time_limit_minutes, gameId, navigate])
 
+  const selectProblem = (index: number) => {
+    if (index === problemIndex) return
+    setProblemIndex(index)
+    setProblem(problems[index])
+    setCodePerLang(loadCode(codeKey(index)))
+    setSubmissionResult(null)
+    setRunOutput(null)
+  }
+
   useEffect(() => {
     if (game?.status !== 'active' || !token) return
 
@@ -222,18 +294,33 @@ export function GamePage() {
       if (stopped) return
 
       const proto = location.protocol === 'https:' ? 'wss:' : 'ws:'
-      const ws = new WebSocket(`${proto}//${location.host}/api/games/${gameId}/ws`, [token])
+      const query = lastSeqRef.current !== null ? `?last_seq=${lastSeqRef.current}` : ''
+      const ws = new WebSocket(`${proto}//${location.host}/api/games/${gameId}/ws${query}`, [token])
       wsRef.current = ws
 
       ws.onmessage = (e) => {
         try {
-          const msg = JSON.parse(e.data) as { type: string } & SubmissionResult & GameFinished & PlayerAdvanced & PlayerState & ServerError
+          const msg = JSON.parse(e.data) as { type: string; seq?: number } & SubmissionResult & RunResult & GameFinished & PlayerAdvanced & PlayerState & ScoreUpdated & ServerError
+          if (msg.seq !== undefined) {
+            if (lastSeqRef.current !== null && msg.seq <= lastSeqRef.current) return
+            lastSeqRef.current = msg.seq
+          }
           if (msg.type === 'submission_result') {
             setSubmissionResult(msg)
             setSubmitting(false)
+          } else if (msg.type === 'run_result') {
+            const verdict = msg.verdict && msg.verdict !== 'AC' ? `Вердикт: ${msg.verdict}\n` : ''
+            setRunOutput({ stdout: msg.stdout ?? '', stderr: verdict + (msg.stderr ?? '') })
+            setRunning(false)
           } else if (msg.type === 'player_state') {
             setPlayerProgress(msg.progress ?? {})
-            getProblem(msg.problem_id).then((res) => setProblem(res.problem)).catch(() => {})
+            if (gameRef.current?.mode === 'scoring') {
+              setScores(msg.scores ?? {})
+            } else {
+              getProblem(msg.problem_id).then((res) => setProblem(res.problem)).catch(() => {})
+            }
+          } else if (msg.type === 'score_updated') {
+            setScores(msg.scores)
           } else if (msg.type === 'player_advanced') {
             setPlayerProgress(msg.progress ?? {})
             if (msg.user_id !== userIdRef.current) {
@@ -272,6 +359,7 @@ export function GamePage() {
             setSubmitting(false)
           } else if (msg.type === 'error') {
             setSubmitting(false)
+            setRunning(false)
             setActionError(errorMessage(msg.error_code, msg.message))
             if (msg.error_code === 'EXECUTION_RATE_LIMITED') {
               setSubmitCooldown(5)
@@ -313,9 +401,41 @@ export function GamePage() {
     setRunning(true)
     setRunOutput(null)
     setActionError('')
+    if (problem?.starter_code) {
+      // The function is called by a harness the server adds, so function
+      // problems are run through the game: on stdin as the arguments, or on
+      // the samples when it is empty.
+      const ws = wsRef.current
+      if (!ws || ws.readyState !== WebSocket.OPEN) {
+        setActionError('WebSocket не подключён')
+        setRunning(false)
+        return
+      }
+      const scoring = game?.mode === 'scoring'
+      ws.send(JSON.stringify({
+        type: 'run',
+        code,
+        language,
+        input: stdin.trim() ? stdin : undefined,
+        problem_index: scoring ? problemIndex : undefined,
+      }))
+      return
+    }
     try {
-      const res = await runCode(code, language, stdin)
-      setRunOutput({ stdout: res.stdout, stderr: res.stderr })
+      // Output is shown as it arrives. The result's output is truncated, so
+      // it only replaces what was streamed when it is longer, e.g. when it
+      // has compiler errors or a timeout note.
+      const res = await runCodeStream(code, language, stdin, (chunk) => {
+        setRunOutput((prev) => {
+          const out = prev ?? { stdout: '', stderr: '' }
+          return { ...out, [chunk.stream]: out[chunk.stream] + chunk.data }
+        })
+      })
+      const longer = (a: string, b: string) => (b.length > a.length ? b : a)
+      setRunOutput((prev) => ({
+        stdout: longer(prev?.stdout ?? '', res.stdout),
+        stderr: longer(prev?.stderr ?? '', res.stderr),
+      }))
     } catch (err) {
       setActionError(err instanceof ApiError ? errorMessage(err.errorCode, err.message) : String(err))
     } finally {
@@ -332,7 +452,8 @@ export function GamePage() {
     setSubmitting(true)
     setSubmissionResult(null)
     setActionError('')
-    ws.send(JSON.stringify({ type: 'submit', code, language }))
+    const scoring = game?.mode === 'scoring'
+    ws.send(JSON.stringify({ type: 'submit', code, language, problem_index: scoring ? problemIndex : undefined }))
   }
 
   if (loading) return (
@@ -351,6 +472,9 @@ export function GamePage() {
   const blocked = isFinished || timedOut
   const totalProblems = game.problem_ids.length
   const myProgress = playerProgress[userId ?? ''] ?? 0
+  const isScoring = game.mode === 'scoring'
+  const myScores = scores[userId ?? '']
+  const maxTotal = sum(problems.map((p) => p.max_score ?? 100))
   const monacoLang = LANGUAGES.find((l) => l.value === language)?.monaco ?? 'python'
   const winnerParticipant = winner?.winner_id
     ? game.participants.find((p) => p.id === winner.winner_id)
@@ -368,7 +492,9 @@ export function GamePage() {
           </Link>
           <span className="text-muted-foreground/40">|</span>
           <span className="text-xs text-muted-foreground">
-            Задача {myProgress + 1}/{totalProblems}
+            {isScoring
+              ? `Баллы ${sum(myScores)}/${maxTotal}`
+              : `Задача ${myProgress + 1}/${totalProblems}`}
           </span>
         </div>
         <div className="flex items-center gap-3">
@@ -409,6 +535,31 @@ export function GamePage() {
 
       <div className="flex gap-4 flex-1 min-h-0">
         <div className="w-2/5 flex flex-col gap-3 overflow-y-auto">
+          {isScoring && (
+            <div className="flex flex-wrap gap-1.5 flex-shrink-0">
+              {problems.map((p, i) => {
+                const best = myScores?.[i] ?? 0
+                const max = p.max_score ?? 100
+                return (
+                  <button
+                    key={i}
+                    type="button"
+                    onClick={() => selectProblem(i)}
+                    className={`px-3 py-1.5 rounded-md text-xs border transition-colors ${
+                      i === problemIndex
+                        ? 'border-primary bg-primary/10 text-foreground'
+                        : 'border-border text-muted-foreground hover:text-foreground'
+                    }`}
+                  >
+                    {i + 1}.{' '}
+                    <span className={`font-mono ${best === max ? 'text-green-400' : best > 0 ? 'text-amber-400' : ''}`}>
+                      {best}/{max}
+                    </span>
+                  </button>
+                )
+              })}
+            </div>
+          )}
           <div className="rounded-lg border border-border bg-card p-5 flex-shrink-0 shadow-sm">
             <h2 className="text-base font-semibold mb-3">{problem.title}</h2>
             <div className="flex items-center gap-2 flex-wrap mb-4">
@@ -420,6 +571,20 @@ export function GamePage() {
               </span>
             </div>
             <ProblemDescription content={problem.description} />
+            {isScoring && problem.groups && problem.groups.length > 0 && (
+              <div className="mt-4">
+                <p className="text-xs font-medium text-muted-foreground uppercase tracking-wide mb-2">Подзадачи</p>
+                <div className="flex flex-col gap-1 text-sm">
+                  {problem.groups.map((g) => (
+                    <div key={g.name} className="flex justify-between">
+                      <span>{g.name}</span>
+                      <span className="font-mono text-muted-foreground">{g.points}</span>
+                    </div>
+                  ))}
+                </div>
+              </div>
+            )}
+            <ProblemSamples samples={problem.samples ?? []} />
           </div>
 
           {!game.is_solo && (
@@ -438,7 +603,9 @@ export function GamePage() {
                     </span>
                     <div className="ml-auto flex items-center gap-2">
                       <span className="text-xs font-mono text-muted-foreground">
-                        {playerProgress[p.id] ?? 0}/{totalProblems}
+                        {isScoring
+                          ? `${sum(scores[p.id])}/${maxTotal}`
+                          : `${playerProgress[p.id] ?? 0}/${totalProblems}`}
                       </span>
                       {game.winner_id === p.id && (
                         <span className="text-xs text-yellow-400 font-medium">Победитель</span>
@@ -521,7 +688,7 @@ export function GamePage() {
 
           <div className="flex-shrink-0 border-t border-border/60">
             <textarea
-              placeholder="stdin"
+              placeholder={problem?.starter_code ? 'аргументы в JSON, по одному на строку' : 'stdin'}
               value={stdin}
               onChange={(e) => setStdin(e.target.value)}
               disabled={!isActive || timedOut}
@@ -531,6 +698,16 @@ export function GamePage() {
             <div className="min-h-[80px] max-h-40 overflow-y-auto px-3 py-2 text-xs font-mono bg-background">
               {submissionResult ? (
                 <div className={submissionResult.accepted ? 'text-green-400' : 'text-red-400'}>
+                  {submissionResult.score != null && isScoring && (
+                    <div className="mb-1 text-foreground">
+                      Баллы: {submissionResult.score}/{submissionResult.max_score}
+                      {submissionResult.groups?.map((g) => (
+                        <div key={g.name} className={g.passed ? 'text-green-400' : 'text-muted-foreground'}>
+                          {g.passed ? '✓' : '✗'} {g.name}: {g.points}/{g.max_points}
+                        </div>
+                      ))}
+                    </div>
+                  )}
                   {submissionResult.accepted ? (
                     'Принято!'
                   ) : (
@@ -538,6 +715,13 @@ export function GamePage() {
                       Неверно
                       {submissionResult.failed_test != null &&
                         ` — тест #${submissionResult.failed_test + 1}`}
+                      {submissionResult.expected != null && (
+                        <div className="mt-2 grid grid-cols-3 gap-2 text-foreground">
+                          <SampleBlock label="Ввод" text={submissionResult.input ?? ''} />
+                          <SampleBlock label="Ожидалось" text={submissionResult.expected} />
+                          <SampleBlock label="Получено" text={submissionResult.stdout} />
+                        </div>
+                      )}
                       {submissionResult.stderr && (
                         <pre className="mt-1 opacity-80 whitespace-pre-wrap">
                           {submissionResult.stderr}
@@ -602,9 +786,13 @@ export function GamePage() {
           <div className="bg-card border border-border/60 rounded-xl p-8 w-full max-w-sm shadow-2xl shadow-black/40 mx-4 text-center">
             <h2 className="text-xl font-semibold mb-1 text-red-400">Время вышло</h2>
             <p className="text-sm text-muted-foreground mb-5">
-              Решено {myProgress}/{totalProblems} {pluralize(myProgress, 'задача', 'задачи', 'задач')}
+              {isScoring
+                ? `Набрано ${sum(myScores)}/${maxTotal} ${pluralize(sum(myScores), 'балл', 'балла', 'баллов')}`
+                : `Решено ${myProgress}/${totalProblems} ${pluralize(myProgress, 'задача', 'задачи', 'задач')}`}
+            </p>
+            <p className="text-xs text-muted-foreground">
+              {game.is_solo ? 'Переходим к результатам...' : 'Подводим итоги...'}
             </p>
-            <p className="text-xs text-muted-foreground">Переходим к результатам...</p>
           </div>
         </div>
       )}
@@ -626,7 +814,8 @@ export function GamePage() {
                 </h2>
                 <p className="text-sm text-muted-foreground mb-5">
                   {winner.winner_id === userId
-                    ? 'Ты решил все задачи первым!'
+                    ? isScoring ? 'Ты набрал больше всех баллов!' : 'Ты решил все задачи первым!'
+                    : !winner.winner_id ? 'Победителя нет'
                     : `Победил ${winnerParticipant ? displayName(winnerParticipant.name, winnerParticipant.id) : winner.winner_id?.slice(0, 8)}`}
                 </p>
               </>
@@ -6,6 +6,7 @@ import { errorMessage } from '@/lib/errors'
 import { difficultyLabel, difficultyClass } from '@/lib/difficulty'
 import { Button } from '@/components/ui/button'
 import { ProblemDescription } from '@/components/ProblemDescription'
+import { ProblemSamples } from '@/components/ProblemSamples'
 import { CreateGameModal } from '@/components/CreateGameModal'
 
 export function ProblemPage() {
@@ -67,6 +68,7 @@ export function ProblemPage() {
 
       <div className="rounded-lg border border-border/60 bg-card/50 p-6">
         <ProblemDescription content={problem.description} />
+        <ProblemSamples samples={problem.samples ?? []} />
       </div>
 
       <CreateGameModal
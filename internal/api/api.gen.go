@@ -51,6 +51,54 @@ func (e GameStatus) Valid() bool {
 	}
 }
 
+// Defines values for GameMode.
+const (
+	Race    GameMode = "race"
+	Scoring GameMode = "scoring"
+)
+
+// Valid indicates whether the value is a known member of the GameMode enum.
+func (e GameMode) Valid() bool {
+	switch e {
+	case Race:
+		return true
+	case Scoring:
+		return true
+	default:
+		return false
+	}
+}
+
+// Defines values for GameSubmissionVerdict.
+const (
+	AC  GameSubmissionVerdict = "AC"
+	CE  GameSubmissionVerdict = "CE"
+	MLE GameSubmissionVerdict = "MLE"
+	RE  GameSubmissionVerdict = "RE"
+	TLE GameSubmissionVerdict = "TLE"
+	WA  GameSubmissionVerdict = "WA"
+)
+
+// Valid indicates whether the value is a known member of the GameSubmissionVerdict enum.
+func (e GameSubmissionVerdict) Valid() bool {
+	switch e {
+	case AC:
+		return true
+	case CE:
+		return true
+	case MLE:
+		return true
+	case RE:
+		return true
+	case TLE:
+		return true
+	case WA:
+		return true
+	default:
+		return false
+	}
+}
+
 // Defines values for MyProblemStatus.
 const (
 	Archived  MyProblemStatus = "archived"
@@ -166,10 +214,13 @@ type ConfirmRequest struct {
 
 // CreateGameRequest defines model for CreateGameRequest.
 type CreateGameRequest struct {
-	IsPublic         *bool    `json:"is_public,omitempty"`
-	IsSolo           *bool    `json:"is_solo,omitempty"`
-	ProblemIds       []string `json:"problem_ids"`
-	TimeLimitMinutes *int     `json:"time_limit_minutes,omitempty"`
+	IsPublic *bool `json:"is_public,omitempty"`
+	IsSolo   *bool `json:"is_solo,omitempty"`
+
+	// Mode In a race the problems are solved in order and the first player to solve them all wins. In a scoring game any problem may be submitted, test groups earn points and the highest total wins when time runs out.
+	Mode             *GameMode `json:"mode,omitempty"`
+	ProblemIds       []string  `json:"problem_ids"`
+	TimeLimitMinutes *int      `json:"time_limit_minutes,omitempty"`
 }
 
 // DeletedResponse defines model for DeletedResponse.
@@ -197,20 +248,30 @@ type ExecuteRequest struct {
 
 // ExecuteResponse defines model for ExecuteResponse.
 type ExecuteResponse struct {
-	ExitCode   int    `json:"exit_code"`
-	Stderr     string `json:"stderr"`
-	Stdout     string `json:"stdout"`
-	TimeUsedMs int    `json:"time_used_ms"`
+	// CpuTimeMs CPU time of the run, 0 when it could not be measured
+	CpuTimeMs int `json:"cpu_time_ms"`
+	ExitCode  int `json:"exit_code"`
+
+	// MemoryUsedKb Peak memory of the run, 0 when it could not be measured
+	MemoryUsedKb int    `json:"memory_used_kb"`
+	Stderr       string `json:"stderr"`
+	Stdout       string `json:"stdout"`
+
+	// TimeUsedMs Wall-clock time of the run, compilation excluded
+	TimeUsedMs int `json:"time_used_ms"`
 }
 
 // Game defines model for Game.
 type Game struct {
-	CreatedAt        time.Time           `json:"created_at"`
-	CreatorId        openapi_types.UUID  `json:"creator_id"`
-	Id               int                 `json:"id"`
-	InviteToken      *openapi_types.UUID `json:"invite_token,omitempty"`
-	IsPublic         bool                `json:"is_public"`
-	IsSolo           bool                `json:"is_solo"`
+	CreatedAt   time.Time           `json:"created_at"`
+	CreatorId   openapi_types.UUID  `json:"creator_id"`
+	Id          int                 `json:"id"`
+	InviteToken *openapi_types.UUID `json:"invite_token,omitempty"`
+	IsPublic    bool                `json:"is_public"`
+	IsSolo      bool                `json:"is_solo"`
+
+	// Mode In a race the problems are solved in order and the first player to solve them all wins. In a scoring game any problem may be submitted, test groups earn points and the highest total wins when time runs out.
+	Mode             GameMode            `json:"mode"`
 	Participants     []GameParticipant   `json:"participants"`
 	ProblemIds       []string            `json:"problem_ids"`
 	StartedAt        *time.Time          `json:"started_at,omitempty"`
@@ -223,10 +284,16 @@ type Game struct {
 // GameStatus defines model for Game.Status.
 type GameStatus string
 
+// GameMode In a race the problems are solved in order and the first player to solve them all wins. In a scoring game any problem may be submitted, test groups earn points and the highest total wins when time runs out.
+type GameMode string
+
 // GameParticipant defines model for GameParticipant.
 type GameParticipant struct {
 	Id   openapi_types.UUID `json:"id"`
 	Name *string            `json:"name,omitempty"`
+
+	// Scores Best score per problem, in a scoring game
+	Scores *[]int `json:"scores,omitempty"`
 }
 
 // GameResponse defines model for GameResponse.
@@ -249,6 +316,49 @@ type GameSolutionsResponse struct {
 	Solutions []GameSolution `json:"solutions"`
 }
 
+// GameSubmission defines model for GameSubmission.
+type GameSubmission struct {
+	Code string `json:"code"`
+
+	// FailedTest 0-based index of the first failed test
+	FailedTest *int    `json:"failed_test,omitempty"`
+	Id         int64   `json:"id"`
+	Language   string  `json:"language"`
+	MemoryKb   *int    `json:"memory_kb,omitempty"`
+	Name       *string `json:"name,omitempty"`
+	ProblemId  string  `json:"problem_id"`
+
+	// Score Points scored by the submission
+	Score       *int                  `json:"score,omitempty"`
+	SubmittedAt time.Time             `json:"submitted_at"`
+	TimeMs      *int                  `json:"time_ms,omitempty"`
+	UserId      openapi_types.UUID    `json:"user_id"`
+	Verdict     GameSubmissionVerdict `json:"verdict"`
+}
+
+// GameSubmissionVerdict defines model for GameSubmission.Verdict.
+type GameSubmissionVerdict string
+
+// GameSubmissionsResponse defines model for GameSubmissionsResponse.
+type GameSubmissionsResponse struct {
+	Submissions []GameSubmission `json:"submissions"`
+}
+
+// LeaderboardEntry defines model for LeaderboardEntry.
+type LeaderboardEntry struct {
+	Name       *string            `json:"name,omitempty"`
+	Rank       int                `json:"rank"`
+	RatedGames int                `json:"rated_games"`
+	Rating     int                `json:"rating"`
+	UserId     openapi_types.UUID `json:"user_id"`
+}
+
+// LeaderboardResponse defines model for LeaderboardResponse.
+type LeaderboardResponse struct {
+	Entries []LeaderboardEntry `json:"entries"`
+	Total   int64              `json:"total"`
+}
+
 // ListGamesResponse defines model for ListGamesResponse.
 type ListGamesResponse struct {
 	Games []Game `json:"games"`
@@ -307,10 +417,22 @@ type PatchProblemResponseVisibility string
 
 // Problem defines model for Problem.
 type Problem struct {
-	Description   string            `json:"description"`
-	Difficulty    ProblemDifficulty `json:"difficulty"`
-	Id            string            `json:"id"`
-	MemoryLimitMb int               `json:"memory_limit_mb"`
+	Description string            `json:"description"`
+	Difficulty  ProblemDifficulty `json:"difficulty"`
+
+	// Groups Subtasks scored separately; absent when the problem is solved all or nothing
+	Groups *[]ProblemGroup `json:"groups,omitempty"`
+	Id     string          `json:"id"`
+
+	// MaxScore Points the problem is worth in a scoring game
+	MaxScore      *int `json:"max_score,omitempty"`
+	MemoryLimitMb int  `json:"memory_limit_mb"`
+
+	// Samples Sample tests; the other tests are hidden
+	Samples *[]ProblemSample `json:"samples,omitempty"`
+
+	// StarterCode Code to start from by language; present for problems where players implement a function rather than a whole program
+	StarterCode *map[string]string `json:"starter_code,omitempty"`
 
 	// TestCount Number of test cases
 	TestCount   *int   `json:"test_count,omitempty"`
@@ -321,11 +443,36 @@ type Problem struct {
 // ProblemDifficulty defines model for Problem.Difficulty.
 type ProblemDifficulty string
 
+// ProblemGroup defines model for ProblemGroup.
+type ProblemGroup struct {
+	Name   string `json:"name"`
+	Points int    `json:"points"`
+}
+
 // ProblemResponse defines model for ProblemResponse.
 type ProblemResponse struct {
 	Problem Problem `json:"problem"`
 }
 
+// ProblemSample defines model for ProblemSample.
+type ProblemSample struct {
+	Input string `json:"input"`
+
+	// Output Expected output
+	Output string `json:"output"`
+}
+
+// RatingChange defines model for RatingChange.
+type RatingChange struct {
+	CreatedAt time.Time `json:"created_at"`
+	GameId    int       `json:"game_id"`
+	NewRating int       `json:"new_rating"`
+	OldRating int       `json:"old_rating"`
+
+	// Place 1-based final standing; equal places are draws
+	Place int `json:"place"`
+}
+
 // StatusResponse defines model for StatusResponse.
 type StatusResponse struct {
 	Status string `json:"status"`
@@ -349,7 +496,11 @@ type UpdateMeRequest struct {
 type UserStatsResponse struct {
 	GamesPlayed    int `json:"games_played"`
 	ProblemsSolved int `json:"problems_solved"`
-	Wins           int `json:"wins"`
+	Rating         int `json:"rating"`
+
+	// RatingHistory Most recent rating changes, newest first
+	RatingHistory []RatingChange `json:"rating_history"`
+	Wins          int            `json:"wins"`
 }
 
 // GameID defines model for GameID.
@@ -364,6 +515,12 @@ type ListGamesParams struct {
 	Offset *int `form:"offset,omitempty" json:"offset,omitempty"`
 }
 
+// GetLeaderboardParams defines parameters for GetLeaderboard.
+type GetLeaderboardParams struct {
+	Limit  *int `form:"limit,omitempty" json:"limit,omitempty"`
+	Offset *int `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
 // ListProblemsParams defines parameters for ListProblems.
 type ListProblemsParams struct {
 	Q      *string `form:"q,omitempty" json:"q,omitempty"`
@@ -453,9 +610,15 @@ type ServerInterface interface {
 	// Start a pending game
 	// (POST /games/{id}/start)
 	StartGame(w http.ResponseWriter, r *http.Request, id GameID)
+	// Get every submission attempt for a game
+	// (GET /games/{id}/submissions)
+	GetGameSubmissions(w http.ResponseWriter, r *http.Request, id GameID)
 	// Finish a solo game when the timer expires
 	// (POST /games/{id}/timeout)
 	TimeoutGame(w http.ResponseWriter, r *http.Request, id GameID)
+	// List players ranked by rating
+	// (GET /leaderboard)
+	GetLeaderboard(w http.ResponseWriter, r *http.Request, params GetLeaderboardParams)
 	// List published public problems with optional search
 	// (GET /problems)
 	ListProblems(w http.ResponseWriter, r *http.Request, params ListProblemsParams)
@@ -582,12 +745,24 @@ func (_ Unimplemented) StartGame(w http.ResponseWriter, r *http.Request, id Game
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
+// Get every submission attempt for a game
+// (GET /games/{id}/submissions)
+func (_ Unimplemented) GetGameSubmissions(w http.ResponseWriter, r *http.Request, id GameID) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // Finish a solo game when the timer expires
 // (POST /games/{id}/timeout)
 func (_ Unimplemented) TimeoutGame(w http.ResponseWriter, r *http.Request, id GameID) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
+// List players ranked by rating
+// (GET /leaderboard)
+func (_ Unimplemented) GetLeaderboard(w http.ResponseWriter, r *http.Request, params GetLeaderboardParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // List published public problems with optional search
 // (GET /problems)
 func (_ Unimplemented) ListProblems(w http.ResponseWriter, r *http.Request, params ListProblemsParams) {
@@ -1083,6 +1258,37 @@ func (siw *ServerInterfaceWrapper) StartGame(w http.ResponseWriter, r *http.Requ
 	handler.ServeHTTP(w, r)
 }
 
+// GetGameSubmissions operation middleware
+func (siw *ServerInterfaceWrapper) GetGameSubmissions(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id GameID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true, Type: "integer", Format: ""})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetGameSubmissions(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 // TimeoutGame operation middleware
 func (siw *ServerInterfaceWrapper) TimeoutGame(w http.ResponseWriter, r *http.Request) {
 
@@ -1114,6 +1320,41 @@ func (siw *ServerInterfaceWrapper) TimeoutGame(w http.ResponseWriter, r *http.Re
 	handler.ServeHTTP(w, r)
 }
 
+// GetLeaderboard operation middleware
+func (siw *ServerInterfaceWrapper) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetLeaderboardParams
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "limit", r.URL.Query(), &params.Limit, runtime.BindQueryParameterOptions{Type: "integer", Format: ""})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "offset", r.URL.Query(), &params.Offset, runtime.BindQueryParameterOptions{Type: "integer", Format: ""})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "offset", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetLeaderboard(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 // ListProblems operation middleware
 func (siw *ServerInterfaceWrapper) ListProblems(w http.ResponseWriter, r *http.Request) {
 
@@ -1413,9 +1654,15 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/games/{id}/start", wrapper.StartGame)
 	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/games/{id}/submissions", wrapper.GetGameSubmissions)
+	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/games/{id}/timeout", wrapper.TimeoutGame)
 	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/leaderboard", wrapper.GetLeaderboard)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/problems", wrapper.ListProblems)
 	})
@@ -2098,6 +2345,50 @@ func (response StartGame404JSONResponse) VisitStartGameResponse(w http.ResponseW
 	return json.NewEncoder(w).Encode(response)
 }
 
+type GetGameSubmissionsRequestObject struct {
+	Id GameID `json:"id"`
+}
+
+type GetGameSubmissionsResponseObject interface {
+	VisitGetGameSubmissionsResponse(w http.ResponseWriter) error
+}
+
+type GetGameSubmissions200JSONResponse GameSubmissionsResponse
+
+func (response GetGameSubmissions200JSONResponse) VisitGetGameSubmissionsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetGameSubmissions401JSONResponse struct{ ErrorJSONResponse }
+
+func (response GetGameSubmissions401JSONResponse) VisitGetGameSubmissionsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetGameSubmissions403JSONResponse ErrorResponse
+
+func (response GetGameSubmissions403JSONResponse) VisitGetGameSubmissionsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(403)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetGameSubmissions404JSONResponse ErrorResponse
+
+func (response GetGameSubmissions404JSONResponse) VisitGetGameSubmissionsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
 type TimeoutGameRequestObject struct {
 	Id GameID `json:"id"`
 }
@@ -2151,6 +2442,23 @@ func (response TimeoutGame404JSONResponse) VisitTimeoutGameResponse(w http.Respo
 	return json.NewEncoder(w).Encode(response)
 }
 
+type GetLeaderboardRequestObject struct {
+	Params GetLeaderboardParams
+}
+
+type GetLeaderboardResponseObject interface {
+	VisitGetLeaderboardResponse(w http.ResponseWriter) error
+}
+
+type GetLeaderboard200JSONResponse LeaderboardResponse
+
+func (response GetLeaderboard200JSONResponse) VisitGetLeaderboardResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
 type ListProblemsRequestObject struct {
 	Params ListProblemsParams
 }
@@ -2330,9 +2638,15 @@ type StrictServerInterface interface {
 	// Start a pending game
 	// (POST /games/{id}/start)
 	StartGame(ctx context.Context, request StartGameRequestObject) (StartGameResponseObject, error)
+	// Get every submission attempt for a game
+	// (GET /games/{id}/submissions)
+	GetGameSubmissions(ctx context.Context, request GetGameSubmissionsRequestObject) (GetGameSubmissionsResponseObject, error)
 	// Finish a solo game when the timer expires
 	// (POST /games/{id}/timeout)
 	TimeoutGame(ctx context.Context, request TimeoutGameRequestObject) (TimeoutGameResponseObject, error)
+	// List players ranked by rating
+	// (GET /leaderboard)
+	GetLeaderboard(ctx context.Context, request GetLeaderboardRequestObject) (GetLeaderboardResponseObject, error)
 	// List published public problems with optional search
 	// (GET /problems)
 	ListProblems(ctx context.Context, request ListProblemsRequestObject) (ListProblemsResponseObject, error)
@@ -2870,6 +3184,32 @@ func (sh *strictHandler) StartGame(w http.ResponseWriter, r *http.Request, id Ga
 	}
 }
 
+// GetGameSubmissions operation middleware
+func (sh *strictHandler) GetGameSubmissions(w http.ResponseWriter, r *http.Request, id GameID) {
+	var request GetGameSubmissionsRequestObject
+
+	request.Id = id
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.GetGameSubmissions(ctx, request.(GetGameSubmissionsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetGameSubmissions")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(GetGameSubmissionsResponseObject); ok {
+		if err := validResponse.VisitGetGameSubmissionsResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
 // TimeoutGame operation middleware
 func (sh *strictHandler) TimeoutGame(w http.ResponseWriter, r *http.Request, id GameID) {
 	var request TimeoutGameRequestObject
@@ -2896,6 +3236,32 @@ func (sh *strictHandler) TimeoutGame(w http.ResponseWriter, r *http.Request, id
 	}
 }
 
+// GetLeaderboard operation middleware
+func (sh *strictHandler) GetLeaderboard(w http.ResponseWriter, r *http.Request, params GetLeaderboardParams) {
+	var request GetLeaderboardRequestObject
+
+	request.Params = params
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.GetLeaderboard(ctx, request.(GetLeaderboardRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetLeaderboard")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(GetLeaderboardResponseObject); ok {
+		if err := validResponse.VisitGetLeaderboardResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
 // ListProblems operation middleware
 func (sh *strictHandler) ListProblems(w http.ResponseWriter, r *http.Request, params ListProblemsParams) {
 	var request ListProblemsRequestObject
@@ -3010,48 +3376,64 @@ func (sh *strictHandler) PatchProblem(w http.ResponseWriter, r *http.Request, pr
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+RbW2/buBL+KwTPeWgBtXbangOs33rbogdpN9i25yUIDFoa2WwlUiUpJ27g/77gRXdK",
-	"slsrm2DfbIsazsw3Nw7HtzjkacYZMCXx4hZnRJAUFAjz7R1J4f0b/YkyvMAZURscYEZSwAtMIxxgAd9z",
-	"KiDCCyVyCLAMN5AS/YbaZWYVU7AGgff7vV4tM84kGOJvheBCfwg5U8CU/kiyLKEhUZSz2VfJmf6tIvlv",
-	"ATFe4H/NKp5n9qmcGWp/Ovp2twhkKGimieGF3Q6JakXBrGHmNU+zBBRoif+E7zlIw08meAZCUcvxNWUM",
-	"xJJG+kvMRUoUXuA8N5pw8kolKFtjK22hm8vaq1flUr76CqHC+wC/5iymIu3dOOQR1HRa7BFgSAlNPE9a",
-	"u9tlgaXjZUAAGRGeymWWrxIa6i8RxCRPVIG6o7fiPAHCNEEql5InvLE2Jon0Ls4EXyWQLmlkdoIbosHQ",
-	"jM/nZ0/UNX8i8xQHeD5/9iSmP36s8h8/tBhUQSq9eknJzXv78Nk8wCll7ttZuT0Rguz0UkVTWCY0pWqZ",
-	"UpYrK21Kbmiap3jxfG4J2G9nAWZ5kpCVZq8he2XnTdXXZfNp/g1os4tKy+3oPbILamKWmmttVaz0bfOW",
-	"KRC92B5lRl7yDe/r0tePl4UVl/jidy8/vF1+/OPz8vc/vnx803WiAKcgJVm3XluTFBDjCsU8Z+O+V9u9",
-	"IuiV4gbCXMHxbkhZlivvk4Swde4EGObS8Ve+UFAdZLRX4TdULVvslhYaYKkiEMLLsFQR75HFeEouIVo2",
-	"vK7P8h2lcregxlWLmE/GdybLdCAwgSpaEtWIwRFR8EST9NmQeYcfGLcDTCO/0ijbUgVLxb8B8xHqCQw1",
-	"wvUIOhgyPSGSCEVDmhGXp8vQN5QTtQovqhcNhq3o97eGXqmIGAFzVKlSEZVb3pmO0Jc4AxbphwEmoaJb",
-	"TSWmjMoNaJRCwkJIkkaYbJn4CZNBgPMsOtpgB+uMEY20nNC8Uge54Q6l+urGWVliy+yCuvc1JOvz37rx",
-	"dQuKw9yRuThwtNx9TPVHzbXbasynOruZF/v2+8ST3NagB+eTgaxxsELqsPsjPU+2RxpmLn+y/C1ebDAV",
-	"eHJexdSYOmU/jrJYclSkLHHqhMl2YivJ+3g8p1JpcnLYzo7jzRe6FVckaUBBmfrvCzxaktrtCwJ9MnzY",
-	"XVioBgRxYB4uS0l0VMkl6T7+JuCul7dfUHbJxJC+PwyVckVtPurvBweGX3ZjrwwltD2R/pDUrTOQS9RE",
-	"hBu67c3TKvFHxy0I6YLteHLeUklXNKFq1+FC58GcJVQqsGGLbokCDzO+hGvZa9AvxfXp7oKocOPU13v6",
-	"mIDZGslxtnqjbZKv/VCcnmGzVzDKd58hNjpCHo4jGsc0zJMmx0DkzpwcI2pq4Q0Rfqu0dl6dU5sltOds",
-	"m3KxK8rNlf/UoUDqM1NuK6hmS+tjnq5AIB4jvQqFRNq43iVSK2tlzz49HjVk3nVuGspr79iVdQC20YB+",
-	"cBj3h2Lv3p+Mcw4YeRWrSnj5t9GAOeDzn/U58hRhH24yKkAeVcgdnCrK0+5QEhnWgSXR4DMYzCRfzMHi",
-	"Q38fpuA+JTfnwNZqgxdn7mRWfh9DhvWV7F8kCG0NY/XbMkvIDnp6BUXWX9p61r/omrJD+ihmWdDctLtD",
-	"VxSdYSHMBVW7T9ozLO+vgAgQL3OtJNdbN60G83NlKhulMttFpyy2PQkbIPCrnQL0iiiVAHp58R7XUi6e",
-	"Pz17Otei8QwYyShe4OdP50+fm5Ok2hgGZiRXm1loO95Gt9xirDVsOv/vI7zAF1wqzaVrjburBpDqFY92",
-	"J7s1aDXe903da59o31o8m89PtnszBHjuLLQCgClNHSKt1xd2dx/Rkkt7FWJXvzhm9bPfDl5dsy28uLwK",
-	"sMzTlIgdXuD/g6DxDqVkTUOkD3iIsAitQSEJUtsJsgFB07C2AEyBGLcE08KeyA4a7fE7toJW6vGYwWut",
-	"RQm2g3eMCQzA5IRFpI7UlhJkE0+FTsLXriE8DM+5Xfe3Kuqcr9cQIc2H0dTZT2qqGSYvr/YN1b1lEQpz",
-	"IYCVNl3Tl01Na/Co6h0YTX2AKbVUO0v6TMnxrfPvlDp6B6rUEalHsXLnTB8uPOakf65p6fS+3i4v7tjd",
-	"h/GxzEU1fI4L+NOgabkqAdXMoUzwmCbQsPyZrnfluP2b+mpKJ+gWcT5dazE0x1QqGsq7coe8va1WINgb",
-	"veEw6679psqBzdvPO3aL9pWmb4LDLNEVhACZJ+r++Idjvip3BKhcMJ2IstyW4rOy6et1jbJrbC9dyhmc",
-	"Szd68z0Hsatmb8w5GtfHbcopi7O5ryfpJ8PjWEIPHR+ZqwkNoNs39yV4KhXiMbLKvDf4G7YMT+iaqg3K",
-	"yJoyUlwm+B26GrmZ6mzTmek5yKXPTsZA47LNA6Z+jtyV4v3B0qoNEcTg2mBac9/ZV07Z7LY+CbAfynZa",
-	"wle7z64B4vPr1khdfcRgaLhurG0/paMeBKudzDnyDDpwXNEJ1Iz9UBZztNohqyp7lkSPGEcamsf97vY/",
-	"Ttk/Eg4tOETOko9zm6M7CPPfJnFJLQIiFv8W9HXnvKXRvpqY65qAHbVzEbeFvo/nasnMDcJOCmR7ErDP",
-	"tYoxv0nBPAYey3gJj9ZTMBgS76X6jw1rx2n++T3AqYyhDqSm58zsZFT/CeS1ef6w8aumvyatN6bD0ILg",
-	"gmEXQjfFPgBibc7912CcohnfncG/41PoYUbk+Hy4RuQEKHKqObMQZMcOO0aVANkOWNS5fvxgo8I5xOqn",
-	"a6PnD66SMmAhgtyMrIWfSP1LfU64ZQGNebqhxF7O5t1bW+hODw50GSq5H3DGz4iUEFWyoJiLvvxhBrL7",
-	"Xf2TfvywCwA3cv5QI7dBoOW/HRQVTWHwyu6zXfDAS3E306+r2ULiyVG9D079uxEcEe3S3CXwDTCkNmAU",
-	"IZCbtLGGUZ+C7W09X1RTqgd0n7/7O8bYNw51dP/6Pw+4f92ZSR5ILiUuQ20vs7icirWfwvJVW7hxQ5kk",
-	"SAIR4aaJ+iylDAahr+a8Tw7+1Or2jKgPKJxfs5rSJ74MKCHi18zFqE3rAvURSRJUTbI+bgF3W/1TYrDH",
-	"XEw7dsDrm0P1NDobf8oY/Uv3neDbHgb1AOuWTNJrdhrRwMkkX6NHFVAo3ED4DRnRIHo8NsvQi8+JcTj9",
-	"edw3lX7H53HvBPqAKbj/pP0zCgE3lVGYas1CH+moIxBniQkrhqjYFoaXiwQv8IxkFO+v9n8FAAD//ylk",
-	"EvQCQgAA",
+	"H4sIAAAAAAAC/+Rc62/ctrL/VwjeC9wEUOx10nuAOp8S1w1yYLdGHqcf0mDBlWZXrClSISmvN8H+7wd8",
+	"6E1Ju4nXtdEvgW1Sw+H85sUhJ99wLLJccOBa4dNvOCeSZKBB2t/ekAze/mJ+ohyf4pzoFEeYkwzwKaYJ",
+	"jrCELwWVkOBTLQuIsIpTyIj5Qm9yO4trWIHE2+3WzFa54Aos8XMphTQ/xIJr4Nr8SPKc0ZhoKvjxX0pw",
+	"87ea5P9KWOJT/D/HNc/HblQdW2rvPH23WgIqljQ3xPCpWw7JekbJrGXmTGQ5Aw1mx+/gSwHK8pNLkYPU",
+	"1HG8ppyDnNPE/LIUMiMan+KisJLw+1VaUr7C221TNp8an36uporFXxBrvI3wmeBLKrPBhWORQEOm5RoR",
+	"hoxQFhjprO6mRY5OkAEJZGLzVM3zYsFobH5JYEkKpkvUPb2FEAwINwSpmivBRGvukjAVnJz57Y3ha3i7",
+	"NPO2kWFswSCb08RyBrfEgGc2OpudPNNr8UwVGY7wbPb82ZJ+/boovn4126YaMhWUY0Zu37rB57MIZ5T7",
+	"304qdomUZGOmaprBnNGM6nlGeaGddDJyS7Miw6cvZo6A++0kwrxgjCwYdGRV20UbqubeQkj9AkZNk0rT",
+	"ezglbkJjm5WkO0uVM0PLnHMNclAX9lK7IPmWtfbpm+F5qfUVvvjNq8vz+W+/f5j/+vvH337pG12EM1CK",
+	"rDqfrUgGiAuNlqLg07baWL0mGNzFLcSFhv3NlvK80MERRviq8BsY59LzV31QUh1ldEjgcV7MrWJnXoea",
+	"nvPs6iMyg0gskU4ByYJHaIbWKXBENYpFwRIr3gWgDIgqDId9RY8w3FI97wilMZxBJuRmXihI5teLPh9X",
+	"QK6Rm/TDrCidgJRBCJROxAA6VkSWv5Cc/iCMPYuZiK/74jIejTIb2BDcxqxIgox1MPasVOw2RdjhJmph",
+	"2BNmSCvekCykCjYUJHOiW1EuIRqeGfIhq7PfiB0jY4RpElYAym+ohrkW18BDhAZcaYNwM0aNBqU7CEJE",
+	"ahrTnPjMqQouUwSu6g+tTnXiy98a3JQmcgL8SRCUJrpwvHMTAz/hHHhiBiNMYk1vDJUl5VSl1gZiwmNg",
+	"rBWIOiZ3h+E2wkWe7K3go5nfhEQ6Nm0/aYLcMp9KfE1lrjXXq2lH+6Km0bY2OGT2l17Z2w7sLUcESRKD",
+	"9VueR4WIBKQEu4EEUY6ETEAiwhM7aUml0ihnZAMSaeHmmZEMEcbQmnJ1hCxdFQsjD2SDMeGbkj7KyMa4",
+	"a1UsMqo1JBHSoDRaSVHkCgGRHOWCcq2qRVO6Ss0ULTRxa7gIYL2uLLhCotBHf3IcVSpodmWE65gIalrX",
+	"NvsZ8G7ejXu3Om0osZAQiCOvzd7sIMpBlnKKEO2KEfetvqHnbdvua+GQbgxnCSu/sykP11vNfji03nvB",
+	"CrfznfOnkSxpD/nXRhik41R+LzdRqO88HpYftpiKAjlezdSUONUwjqqcslfcqnCa0q2a/CCPxtaV2g/0",
+	"JaEMkrn2qXbbZmbPFkRZB5XAbZl5OefkvrNOBe8SHzr4Ua7/9VMwhxzVQp9/uTx2etE7U1rjNgKps3Og",
+	"djRBi40Vj6pR2EUulYPeyyQap4odQvPO9hPhG5AJjXUzz3h1hiP8xysc4Q8X5zjCl/bfd+afs/OAzw9F",
+	"5p0tsVy/I5hplR8zzHrSfqZZfTdtnI0lQrxeAElALgSRyTnXctNncmdVlYRfh0OTtPmJiQpqcIIhEhz7",
+	"bidr+Wki7Fdp8zMhlGHwgGtJYXfgepIOHAZshrOTO+rstWSmJBHcFVXaaI8aj/j7qeKd7sItP7WHy82V",
+	"T1WHN1ImszvvpSI6aVEV6SH+DsDdIG8/IOyKiTF5X46kh1VVcNIz7OxCfjihCu6hgnYgxd/lSGtOZv4A",
+	"S2Sc0pvB86tm4QzhBmSZAU1Hxhuq6IIyqjc9LmLj0zijSoMLW/SGaNgx3Dn2WvSr7YZkd0V0nHrxDdY9",
+	"D8Bsg+Q0W4PhlRWrMBR3z7BdK5rke0gRW+lbgOOELpc0LlibYyBqY2t/CbU1opTIsFa6w3VvIfy+WGii",
+	"rqtMUYG5ENTANi8RWSjg2p+06/oAoqqsDpgTv5Cm/pq6uLqPL3tjWAo5NJq06mCdMlio2jUfz4I7zK+F",
+	"1GnwcD1Yovb1qEU4QVGW05B07YA9iaiXlg2hU5DuD7bMktIkAb6n5BxZvB0q59U3KSRJqOGFsKuWtvXV",
+	"q30BIBKwxR1DDi2lyMwRosyFX6JcglWNpZB11WidggRfGVKIGhYzM4mgZcFjQxlJ4rafEiP8dSqYBWYl",
+	"SYYDxmLENI9FwQPnv9+KbAHSHvxAaRQTBaqm0QCnWU8cKpwMuOwx/9nkpmWd3RX7OjTiF5xNDGbg/cOh",
+	"VfDQrjq8c6fgfv4IB5M5y86ZSjjbGFvb63U/SA/enolC+6HO3f9tDrGGBPkJk6GRu1l+eojHd/bscJYS",
+	"vrqjSxTjdOZDVyMc1vOxQ5Fgyeh4zkgc8IgnvmyypJwwY9+2UP8SwZeCMGQ/co4pkWStdkvY/emZuYpr",
+	"g7HWLlo165CA39skZCSY1zlZFRrE9SS0I7nNB3PvdBfpLdzmVILaC/2dU+LqdmwsWR6XgSPR4jMazZg/",
+	"2huFy+Gb7pL7jNxeAF/pFJ+e+JuZ6vcpZPhQkfijAmm0YeqcOrfBZsCAyrg0d7nK/mUHNzZPqdJCbvqm",
+	"dCmURhJiE+PcXBRb76AixGFtopKtR+4a3FsOJhDbzbXHDp7eTovaAupLo10Lae6zD8g2wgriQlK9eW94",
+	"dQi8BiJBvip0Wr3ZMh8t7J9rhU+1zt3rLMqX7ibWRVv8eqMBvSZaM0Cvrt7ixgEJz45OjmbWzeXASU7x",
+	"KX5xNDt6YXZCdGoZOCaFTo9j95LK/CEXTlONntiL97eJzQGVNlz6J1f+CRso/Vokmzt7jdZ50LVto2Is",
+	"u/sa7vlsdmertx1Z4C2cEQBwbahDYuT602w2RLTi0j2xc7N/2mf28593nt3QLXz66XOEVZFlxJgb/g9I",
+	"utygjKxojExCa68DV6CRAlvPRM6tGRpOF4BrkNOaYJ86HUgPWs+o7lkLOgF0uw3n9Qq43lcFRmDym0Wk",
+	"idQNJciFzxodJlb+mc04PBdu3t8qqAuxWrns0Unq5Dsl1XaTnz5vW6I75wmKCymBVzrdkJcLsCsIiOoN",
+	"WEldwiGldAljEjrzfBcK5CFl9AZ0JSPS9GLVyrkpBQXUyfy5IaW7t/VuknTP5j6Oj2MuaeCzn8M/DJqO",
+	"qwpQw5wpACwpg5bmHytNtJrWf5slHtII+qloSNZmG4ZjqjSN1X2ZQ9Fd1ggQ3MvPcTfrn4ceKga2X8ne",
+	"s1l0n76GOgPsFFuQAlUw/XDswzNfpzsSdCF5WcawCFdXdEHTqO74cNTq7fjkWzq+FCA3dU+HLUrhZhtH",
+	"9Xr/ZBY6/YfJiOVSwQCdEJnPB1SA/i1nKMBTpU3x0AnzweBv2bI8oTXVKcrJinJSPsIJG3TdynGos02v",
+	"V2Qnkz65MwZaj9QCYJpx5KtLDwdLJzZETDHAYtow3+O/BOXH35rvn7dj0c7s8PXmgy/jhOy606rVIDza",
+	"tDV1yXpIQ90JVtfBsecZdOS4YgKofZFqyhHmVsOJyp0l0RMukIHm6bC5/VtQ/o+Ew2wcEq/J+5nN3hWE",
+	"2c8HMUmzBUQc/h3om8b5jSbburOqrwKuJct73A76IZ7rKce+wfKgQHY7xoZMq2wHOyiY+8DjGK/gMXKK",
+	"Rl3igxT/vm5tP8m/eAA4VT7Ug9S2nGPX3zF8Ajmz448bv7qH5aD5xuEwdCB4Z9iH0HdHj4DY6J/+MRgP",
+	"UYzv93bf8yl0NyXyfD5eJfIbKGOqPbMQ5JqnekrFgNyMaNSFGX60XuEClvq7c6MXjy6TsmAhgnynn4Of",
+	"KPOXZrdjRwNafShjgb3qaXmwutDvuhmpMtT7fsQRPydKQVLvxb7+Gogf9uHYsKm/N8OPOwHwjbOP1XNb",
+	"BDr220ex3Z3iDbZzs7zU9kWfT9upQmWnr30dSrSGLPePHV0xE5Ij9EdKGZgGfqr+5EpTxkwbJzecCM5c",
+	"t1JMGAP5fwqJNW/TsW9sGbiGz7D/aHD+YD1IoEFozIfU0yMkWFK/L3nEPgVuQG4aWytxHvMtmmYwepH8",
+	"wU145AfE0opM757f8cF9zUNQi1/txs3bbMGETyvLF+hGEBL5V2xOMVjdVDWWVDR6r374YuT/H+vFSKCp",
+	"LaB+V/4F9zoVKDVZnntEhohGDIhxRhyQbaCr7XOo2mqdV/kk3LTjOYX2b88sgM1WqMEbrbKjajfsvoTl",
+	"jUNvRf856Ica00biTYXLNL5la5T7KW60BJjzoMhdBwJSYBqn2qgfZ5TDKPSXm4OBf2hxX272EbhJc2qh",
+	"H/iOsYJIrHndIN56WfDE5G91O9PTDnDf6nbp0asrL4I+eEMNPoH7k1Zn9uT/QHgv+HbbJUKO1E05yBVW",
+	"2c602CDTeIae1EChOIX4GtmtQfJ06onUID53jMPdl/lCrYn3XOYLtiGOqIL/n3r+GZmcf+xVqmpDQ58Y",
+	"ryPtUe+pE5cCeVMqXiEZPsXHJKd4+3n73wEAz7VwPLFUAAA=",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -3,7 +3,6 @@ package app
 import (
 	"context"
 	"log"
-	"net/http"
 
 	"bytebattle/internal/config"
 	sqlcdb "bytebattle/internal/db/sqlc"
@@ -16,18 +15,9 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func NewRouter(pool *pgxpool.Pool, cfg config.Config) http.Handler {
-	execCfg := executor.DefaultConfig()
-	if c, err := executor.LoadConfig("executor_config.json"); err == nil {
-		if c.DockerHost != "" {
-			execCfg.DockerHost = c.DockerHost
-		}
-		if len(c.Languages) > 0 {
-			execCfg.Languages = c.Languages
-		}
-	}
-
-	dockerExecutor, err := executor.NewDockerExecutor(execCfg)
+func NewRouter(pool *pgxpool.Pool, cfg config.Config) *server.HTTPServer {
+	execCfg := executor.LoadConfigOrDefault("executor_config.json")
+	exec, err := executor.New(execCfg)
 	if err != nil {
 		log.Fatalf("failed to create executor: %v", err)
 	}
@@ -38,10 +28,10 @@ func NewRouter(pool *pgxpool.Pool, cfg config.Config) http.Handler {
 		log.Fatalf("failed to seed built-in problems: %v", err)
 	}
 
-	return NewRouterWithExecutor(pool, dockerExecutor, store, cfg)
+	return NewRouterWithExecutor(pool, exec, store, cfg)
 }
 
-func NewRouterWithExecutor(pool *pgxpool.Pool, exec executor.Executor, store *problems.Store, cfg config.Config, rlCfg ...service.RateLimitConfig) http.Handler {
+func NewRouterWithExecutor(pool *pgxpool.Pool, exec executor.Executor, store *problems.Store, cfg config.Config, rlCfg ...service.RateLimitConfig) *server.HTTPServer {
 	q := sqlcdb.New(pool)
 
 	userService := service.NewUserService(q)
@@ -54,6 +44,18 @@ func NewRouterWithExecutor(pool *pgxpool.Pool, exec executor.Executor, store *pr
 	mailer := service.NewMailer(cfg.Entrance.ResendAPIKey, cfg.Entrance.FromEmail)
 	entranceService := service.NewEntranceService(q, sessionService, mailer, cfg.Entrance)
 
-	hub := ws.NewHub()
+	hub := newHub(pool, cfg.WSBroadcaster)
 	return server.New(pool, userService, gameService, problemService, sessionService, executionService, submissionService, hub, entranceService)
 }
+
+func newHub(pool *pgxpool.Pool, broadcaster string) *ws.Hub {
+	switch broadcaster {
+	case "", "memory":
+		return ws.NewHub()
+	case "postgres":
+		return ws.NewHub(ws.WithBroadcaster(ws.NewPGBroadcaster(pool)))
+	default:
+		log.Fatalf("unknown WS_BROADCASTER %q", broadcaster)
+		return nil
+	}
+}
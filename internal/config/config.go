@@ -11,7 +11,10 @@ type Config struct {
 	DBDSN       string
 	HTTPAddr    string
 	ProblemsDir string
-	Entrance    EntranceConfig
+	// WSBroadcaster selects how WebSocket messages reach other API
+	// instances: "memory" (single instance) or "postgres" (LISTEN/NOTIFY).
+	WSBroadcaster string
+	Entrance      EntranceConfig
 }
 
 type EntranceConfig struct {
@@ -30,7 +33,8 @@ func Load() Config {
 			getEnv("HTTP_HOST", "0.0.0.0"),
 			getEnv("HTTP_PORT", "8080"),
 		),
-		ProblemsDir: getEnv("PROBLEMS_DIR", "./problems"),
+		ProblemsDir:   getEnv("PROBLEMS_DIR", "./problems"),
+		WSBroadcaster: getEnv("WS_BROADCASTER", "memory"),
 		Entrance: EntranceConfig{
 			ResendAPIKey: getEnv("RESEND_API_KEY", ""),
 			FromEmail:    getEnv("FROM_EMAIL", "noreply@bytebattle.dev"),
@@ -42,6 +46,27 @@ func Load() Config {
 	}
 }
 
+// JudgeConfig configures the standalone judge worker (cmd/bytebattle-judge).
+type JudgeConfig struct {
+	HTTPAddr string
+	// Token must be presented by the API as a bearer token. It may only be
+	// empty when the worker listens on a loopback address.
+	Token string
+	// ExecutorConfig is the executor_config.json the worker runs with.
+	ExecutorConfig string
+}
+
+func LoadJudge() JudgeConfig {
+	return JudgeConfig{
+		HTTPAddr: fmt.Sprintf("%s:%s",
+			getEnv("JUDGE_HOST", "127.0.0.1"),
+			getEnv("JUDGE_PORT", "8090"),
+		),
+		Token:          getEnv("JUDGE_TOKEN", ""),
+		ExecutorConfig: getEnv("EXECUTOR_CONFIG", "executor_config.json"),
+	}
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -0,0 +1,19 @@
+-- name: AppendGameEvent :one
+-- Numbers the event with the game's next sequence number and returns the
+-- payload with its "seq" field set.
+WITH next AS (
+    UPDATE games
+    SET last_event_seq = last_event_seq + 1
+    WHERE id = @game_id
+    RETURNING last_event_seq
+)
+INSERT INTO game_events (game_id, seq, payload)
+SELECT @game_id, next.last_event_seq, @payload::jsonb || jsonb_build_object('seq', next.last_event_seq)
+FROM next
+RETURNING payload;
+
+-- name: ListGameEventsAfter :many
+SELECT payload FROM game_events
+WHERE game_id = @game_id AND seq > @after_seq
+ORDER BY seq
+LIMIT @max_events;
@@ -41,3 +41,16 @@ RETURNING current_problem_index;
 -- name: GetAllParticipantsProblemIndices :many
 SELECT user_id, current_problem_index FROM game_participants
 WHERE game_id = $1;
+
+-- name: GetLeadingParticipant :one
+-- The participant furthest ahead leads; ties go to whoever got there first.
+SELECT gp.user_id
+FROM game_participants gp
+LEFT JOIN LATERAL (
+    SELECT max(s.created_at) AS last_solved_at
+    FROM solutions s
+    WHERE s.game_id = gp.game_id AND s.user_id = gp.user_id
+) ls ON true
+WHERE gp.game_id = $1 AND gp.current_problem_index > 0
+ORDER BY gp.current_problem_index DESC, ls.last_solved_at ASC NULLS LAST, gp.id
+LIMIT 1;
@@ -26,7 +26,7 @@ WHERE game_id = $1 AND problem_index = $2
 LIMIT 1;
 
 -- name: GetGameProblemByIndex :one
-SELECT gp.problem_id, gp.problem_version_id, pv.artifact_path
+SELECT gp.problem_id, gp.problem_version_id, pv.artifact_path, pv.limits_time_ms, pv.limits_memory_kb
 FROM game_problems gp
 JOIN problem_versions pv ON pv.id = gp.problem_version_id
 WHERE gp.game_id = $1 AND gp.problem_index = $2
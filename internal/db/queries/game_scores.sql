@@ -0,0 +1,28 @@
+-- name: RecordGameScore :execrows
+-- Keeps the player's best score on the problem; no row is affected unless
+-- score beats it.
+INSERT INTO game_scores (game_id, user_id, problem_index, score)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (game_id, user_id, problem_index) DO UPDATE
+SET score = EXCLUDED.score, updated_at = NOW()
+WHERE game_scores.score < EXCLUDED.score;
+
+-- name: GetGameScores :many
+SELECT user_id, problem_index, score FROM game_scores
+WHERE game_id = $1
+ORDER BY user_id, problem_index;
+
+-- name: GetParticipantTotalScore :one
+SELECT COALESCE(SUM(score), 0)::int AS total FROM game_scores
+WHERE game_id = $1 AND user_id = $2;
+
+-- name: GetLeadingScorer :one
+-- The participant with the most points leads; ties go to whoever got there
+-- first.
+SELECT user_id
+FROM game_scores
+WHERE game_id = $1
+GROUP BY user_id
+HAVING SUM(score) > 0
+ORDER BY SUM(score) DESC, max(updated_at) ASC, user_id
+LIMIT 1;
@@ -1,6 +1,6 @@
 -- name: CreateGame :one
-INSERT INTO games (creator_id, status, is_public, is_solo, time_limit_minutes)
-VALUES ($1, 'pending', $2, $3, $4)
+INSERT INTO games (creator_id, status, is_public, is_solo, time_limit_minutes, mode)
+VALUES ($1, 'pending', $2, $3, $4, $5)
 RETURNING *;
 
 -- name: GetGameByID :one
@@ -68,3 +68,10 @@ RETURNING *;
 
 -- name: DeleteGame :execrows
 DELETE FROM games WHERE id = $1;
+
+-- name: ListExpiredActiveGames :many
+SELECT id FROM games
+WHERE status = 'active'
+  AND time_limit_minutes IS NOT NULL
+  AND started_at + make_interval(mins => time_limit_minutes) <= NOW()
+ORDER BY id;
@@ -0,0 +1,38 @@
+-- name: GetGameStandingsForUpdate :many
+-- Locks the participants' user rows in a fixed order so concurrent rating
+-- updates of overlapping games cannot deadlock. total_score is only set in
+-- scoring games.
+SELECT gp.user_id, gp.current_problem_index, u.rating,
+       (SELECT COALESCE(SUM(gs.score), 0) FROM game_scores gs
+        WHERE gs.game_id = gp.game_id AND gs.user_id = gp.user_id)::int AS total_score
+FROM game_participants gp
+JOIN users u ON u.id = gp.user_id
+WHERE gp.game_id = $1
+ORDER BY u.id
+FOR UPDATE OF u;
+
+-- name: UpdateUserRating :exec
+UPDATE users SET rating = $2, updated_at = NOW() WHERE id = $1;
+
+-- name: InsertRatingHistory :exec
+INSERT INTO rating_history (user_id, game_id, place, old_rating, new_rating)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ListUserRatingHistory :many
+SELECT game_id, place, old_rating, new_rating, created_at
+FROM rating_history
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2;
+
+-- name: GetLeaderboard :many
+-- Only users who have played at least one rated game are ranked.
+SELECT u.id, u.name, u.rating, count(rh.id)::int AS rated_games
+FROM users u
+JOIN rating_history rh ON rh.user_id = u.id
+GROUP BY u.id
+ORDER BY u.rating DESC, u.id
+LIMIT $1 OFFSET $2;
+
+-- name: CountLeaderboard :one
+SELECT count(DISTINCT user_id) FROM rating_history;
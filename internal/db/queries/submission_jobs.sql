@@ -0,0 +1,50 @@
+-- name: InsertSubmissionJob :one
+INSERT INTO submission_jobs (game_id, user_id, problem_index, code, language, instance_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id;
+
+-- name: ClaimSubmissionJob :one
+-- An instance takes the jobs it queued itself, so the result can be pushed to
+-- the player's connection, and any job left waiting since orphaned_before.
+UPDATE submission_jobs
+SET status = 'running',
+    attempts = attempts + 1,
+    instance_id = @instance_id,
+    locked_at = NOW(),
+    updated_at = NOW()
+WHERE id = (
+    SELECT j.id FROM submission_jobs j
+    WHERE j.status = 'pending'
+      AND j.run_after <= NOW()
+      AND (j.instance_id = @instance_id OR j.run_after <= @orphaned_before::timestamptz)
+    ORDER BY j.run_after, j.id
+    FOR UPDATE SKIP LOCKED
+    LIMIT 1
+)
+RETURNING *;
+
+-- name: FinishSubmissionJob :exec
+UPDATE submission_jobs
+SET status = 'done', result = $2, last_error = $3, updated_at = NOW()
+WHERE id = $1;
+
+-- name: RetrySubmissionJob :exec
+UPDATE submission_jobs
+SET status = 'pending', run_after = $2, last_error = $3, updated_at = NOW()
+WHERE id = $1;
+
+-- name: RequeueStaleSubmissionJobs :execrows
+-- Jobs whose worker died while running them go back to the queue.
+UPDATE submission_jobs
+SET status = 'pending', run_after = NOW(), updated_at = NOW()
+WHERE status = 'running' AND locked_at < $1;
+
+-- name: ListUndeliveredSubmissionJobs :many
+SELECT id, result FROM submission_jobs
+WHERE game_id = $1 AND user_id = $2
+  AND status = 'done' AND delivered_at IS NULL AND result IS NOT NULL
+ORDER BY id;
+
+-- name: MarkSubmissionJobDelivered :execrows
+UPDATE submission_jobs SET delivered_at = NOW()
+WHERE id = $1 AND delivered_at IS NULL;
@@ -0,0 +1,32 @@
+-- name: InsertSubmission :exec
+INSERT INTO submissions (
+    user_id, game_id, problem_id, problem_version_id, code, language,
+    verdict, failed_test, execution_time, memory_used, test_results, score, job_id
+)
+VALUES (
+    @user_id, @game_id, @problem_id, @problem_version_id, @code, @language,
+    @verdict, @failed_test, @execution_time, @memory_used, @test_results, @score, @job_id
+)
+ON CONFLICT (job_id) DO NOTHING;
+
+-- name: ListGameSubmissions :many
+-- A NULL user_id returns the submissions of every participant.
+SELECT
+    s.id,
+    s.user_id,
+    s.problem_id,
+    s.code,
+    s.language,
+    s.verdict,
+    s.failed_test,
+    s.execution_time,
+    s.memory_used,
+    s.score,
+    s.created_at,
+    u.username,
+    u.name
+FROM submissions s
+JOIN users u ON u.id = s.user_id
+WHERE s.game_id = @game_id
+  AND (sqlc.narg(user_id)::uuid IS NULL OR s.user_id = sqlc.narg(user_id)::uuid)
+ORDER BY s.created_at, s.id;
@@ -27,7 +27,8 @@ UPDATE users SET email_verified = true WHERE id = $1;
 SELECT
     COUNT(*) FILTER (WHERE g.winner_id = @user_id AND g.is_solo = false)::int AS wins,
     COUNT(*)::int AS games_played,
-    COALESCE(SUM(gp.current_problem_index), 0)::int AS problems_solved
+    COALESCE(SUM(gp.current_problem_index), 0)::int AS problems_solved,
+    (SELECT u.rating FROM users u WHERE u.id = @user_id)::int AS rating
 FROM game_participants gp
 JOIN games g ON g.id = gp.game_id
 WHERE gp.user_id = @user_id
@@ -0,0 +1,15 @@
+-- name: NotifyWSEvent :exec
+SELECT pg_notify(@channel::text, @payload::text);
+
+-- name: InsertWSPayload :one
+INSERT INTO ws_payloads (payload)
+VALUES ($1)
+RETURNING id;
+
+-- name: GetWSPayload :one
+SELECT payload FROM ws_payloads
+WHERE id = $1;
+
+-- name: DeleteWSPayloadsBefore :execrows
+DELETE FROM ws_payloads
+WHERE created_at < $1;
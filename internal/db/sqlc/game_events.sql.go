@@ -0,0 +1,70 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: game_events.sql
+
+package sqlcdb
+
+import (
+	"context"
+)
+
+const appendGameEvent = `-- name: AppendGameEvent :one
+WITH next AS (
+    UPDATE games
+    SET last_event_seq = last_event_seq + 1
+    WHERE id = $1
+    RETURNING last_event_seq
+)
+INSERT INTO game_events (game_id, seq, payload)
+SELECT $1, next.last_event_seq, $2::jsonb || jsonb_build_object('seq', next.last_event_seq)
+FROM next
+RETURNING payload
+`
+
+type AppendGameEventParams struct {
+	GameID  int32  `json:"game_id"`
+	Payload []byte `json:"payload"`
+}
+
+// Numbers the event with the game's next sequence number and returns the
+// payload with its "seq" field set.
+func (q *Queries) AppendGameEvent(ctx context.Context, arg AppendGameEventParams) ([]byte, error) {
+	row := q.db.QueryRow(ctx, appendGameEvent, arg.GameID, arg.Payload)
+	var payload []byte
+	err := row.Scan(&payload)
+	return payload, err
+}
+
+const listGameEventsAfter = `-- name: ListGameEventsAfter :many
+SELECT payload FROM game_events
+WHERE game_id = $1 AND seq > $2
+ORDER BY seq
+LIMIT $3
+`
+
+type ListGameEventsAfterParams struct {
+	GameID    int32 `json:"game_id"`
+	AfterSeq  int64 `json:"after_seq"`
+	MaxEvents int32 `json:"max_events"`
+}
+
+func (q *Queries) ListGameEventsAfter(ctx context.Context, arg ListGameEventsAfterParams) ([][]byte, error) {
+	rows, err := q.db.Query(ctx, listGameEventsAfter, arg.GameID, arg.AfterSeq, arg.MaxEvents)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := [][]byte{}
+	for rows.Next() {
+		var payload []byte
+		if err := rows.Scan(&payload); err != nil {
+			return nil, err
+		}
+		items = append(items, payload)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -87,6 +87,27 @@ func (q *Queries) GetAllParticipantsProblemIndices(ctx context.Context, gameID i
 	return items, nil
 }
 
+const getLeadingParticipant = `-- name: GetLeadingParticipant :one
+SELECT gp.user_id
+FROM game_participants gp
+LEFT JOIN LATERAL (
+    SELECT max(s.created_at) AS last_solved_at
+    FROM solutions s
+    WHERE s.game_id = gp.game_id AND s.user_id = gp.user_id
+) ls ON true
+WHERE gp.game_id = $1 AND gp.current_problem_index > 0
+ORDER BY gp.current_problem_index DESC, ls.last_solved_at ASC NULLS LAST, gp.id
+LIMIT 1
+`
+
+// The participant furthest ahead leads; ties go to whoever got there first.
+func (q *Queries) GetLeadingParticipant(ctx context.Context, gameID int32) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, getLeadingParticipant, gameID)
+	var user_id uuid.UUID
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
 const getParticipantProblemIndex = `-- name: GetParticipantProblemIndex :one
 SELECT current_problem_index FROM game_participants
 WHERE game_id = $1 AND user_id = $2
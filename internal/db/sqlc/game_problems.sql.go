@@ -45,7 +45,7 @@ func (q *Queries) CountGameProblems(ctx context.Context, gameID int32) (int64, e
 }
 
 const getGameProblemByIndex = `-- name: GetGameProblemByIndex :one
-SELECT gp.problem_id, gp.problem_version_id, pv.artifact_path
+SELECT gp.problem_id, gp.problem_version_id, pv.artifact_path, pv.limits_time_ms, pv.limits_memory_kb
 FROM game_problems gp
 JOIN problem_versions pv ON pv.id = gp.problem_version_id
 WHERE gp.game_id = $1 AND gp.problem_index = $2
@@ -61,12 +61,20 @@ type GetGameProblemByIndexRow struct {
 	ProblemID        string `json:"problem_id"`
 	ProblemVersionID int64  `json:"problem_version_id"`
 	ArtifactPath     string `json:"artifact_path"`
+	LimitsTimeMs     int32  `json:"limits_time_ms"`
+	LimitsMemoryKb   int32  `json:"limits_memory_kb"`
 }
 
 func (q *Queries) GetGameProblemByIndex(ctx context.Context, arg GetGameProblemByIndexParams) (GetGameProblemByIndexRow, error) {
 	row := q.db.QueryRow(ctx, getGameProblemByIndex, arg.GameID, arg.ProblemIndex)
 	var i GetGameProblemByIndexRow
-	err := row.Scan(&i.ProblemID, &i.ProblemVersionID, &i.ArtifactPath)
+	err := row.Scan(
+		&i.ProblemID,
+		&i.ProblemVersionID,
+		&i.ArtifactPath,
+		&i.LimitsTimeMs,
+		&i.LimitsMemoryKb,
+	)
 	return i, err
 }
 
@@ -0,0 +1,110 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: game_scores.sql
+
+package sqlcdb
+
+import (
+	"context"
+
+	uuid "github.com/google/uuid"
+)
+
+const getGameScores = `-- name: GetGameScores :many
+SELECT user_id, problem_index, score FROM game_scores
+WHERE game_id = $1
+ORDER BY user_id, problem_index
+`
+
+type GetGameScoresRow struct {
+	UserID       uuid.UUID `json:"user_id"`
+	ProblemIndex int32     `json:"problem_index"`
+	Score        int32     `json:"score"`
+}
+
+func (q *Queries) GetGameScores(ctx context.Context, gameID int32) ([]GetGameScoresRow, error) {
+	rows, err := q.db.Query(ctx, getGameScores, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetGameScoresRow{}
+	for rows.Next() {
+		var i GetGameScoresRow
+		if err := rows.Scan(&i.UserID, &i.ProblemIndex, &i.Score); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLeadingScorer = `-- name: GetLeadingScorer :one
+SELECT user_id
+FROM game_scores
+WHERE game_id = $1
+GROUP BY user_id
+HAVING SUM(score) > 0
+ORDER BY SUM(score) DESC, max(updated_at) ASC, user_id
+LIMIT 1
+`
+
+// The participant with the most points leads; ties go to whoever got there
+// first.
+func (q *Queries) GetLeadingScorer(ctx context.Context, gameID int32) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, getLeadingScorer, gameID)
+	var user_id uuid.UUID
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
+const getParticipantTotalScore = `-- name: GetParticipantTotalScore :one
+SELECT COALESCE(SUM(score), 0)::int AS total FROM game_scores
+WHERE game_id = $1 AND user_id = $2
+`
+
+type GetParticipantTotalScoreParams struct {
+	GameID int32     `json:"game_id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) GetParticipantTotalScore(ctx context.Context, arg GetParticipantTotalScoreParams) (int32, error) {
+	row := q.db.QueryRow(ctx, getParticipantTotalScore, arg.GameID, arg.UserID)
+	var total int32
+	err := row.Scan(&total)
+	return total, err
+}
+
+const recordGameScore = `-- name: RecordGameScore :execrows
+INSERT INTO game_scores (game_id, user_id, problem_index, score)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (game_id, user_id, problem_index) DO UPDATE
+SET score = EXCLUDED.score, updated_at = NOW()
+WHERE game_scores.score < EXCLUDED.score
+`
+
+type RecordGameScoreParams struct {
+	GameID       int32     `json:"game_id"`
+	UserID       uuid.UUID `json:"user_id"`
+	ProblemIndex int32     `json:"problem_index"`
+	Score        int32     `json:"score"`
+}
+
+// Keeps the player's best score on the problem; no row is affected unless
+// score beats it.
+func (q *Queries) RecordGameScore(ctx context.Context, arg RecordGameScoreParams) (int64, error) {
+	result, err := q.db.Exec(ctx, recordGameScore,
+		arg.GameID,
+		arg.UserID,
+		arg.ProblemIndex,
+		arg.Score,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
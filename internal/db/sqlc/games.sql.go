@@ -17,7 +17,7 @@ UPDATE games
 SET status = 'cancelled',
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, creator_id, winner_id, status, started_at, completed_at, created_at, updated_at, is_public, invite_token, is_solo, time_limit_minutes
+RETURNING id, creator_id, winner_id, status, started_at, completed_at, created_at, updated_at, is_public, invite_token, is_solo, time_limit_minutes, last_event_seq, mode
 `
 
 func (q *Queries) CancelGame(ctx context.Context, id int32) (Game, error) {
@@ -36,6 +36,8 @@ func (q *Queries) CancelGame(ctx context.Context, id int32) (Game, error) {
 		&i.InviteToken,
 		&i.IsSolo,
 		&i.TimeLimitMinutes,
+		&i.LastEventSeq,
+		&i.Mode,
 	)
 	return i, err
 }
@@ -47,7 +49,7 @@ SET status = 'finished',
     completed_at = NOW(),
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, creator_id, winner_id, status, started_at, completed_at, created_at, updated_at, is_public, invite_token, is_solo, time_limit_minutes
+RETURNING id, creator_id, winner_id, status, started_at, completed_at, created_at, updated_at, is_public, invite_token, is_solo, time_limit_minutes, last_event_seq, mode
 `
 
 type CompleteGameParams struct {
@@ -71,6 +73,8 @@ func (q *Queries) CompleteGame(ctx context.Context, arg CompleteGameParams) (Gam
 		&i.InviteToken,
 		&i.IsSolo,
 		&i.TimeLimitMinutes,
+		&i.LastEventSeq,
+		&i.Mode,
 	)
 	return i, err
 }
@@ -93,9 +97,9 @@ func (q *Queries) CountGamesForUser(ctx context.Context, userID uuid.UUID) (int6
 }
 
 const createGame = `-- name: CreateGame :one
-INSERT INTO games (creator_id, status, is_public, is_solo, time_limit_minutes)
-VALUES ($1, 'pending', $2, $3, $4)
-RETURNING id, creator_id, winner_id, status, started_at, completed_at, created_at, updated_at, is_public, invite_token, is_solo, time_limit_minutes
+INSERT INTO games (creator_id, status, is_public, is_solo, time_limit_minutes, mode)
+VALUES ($1, 'pending', $2, $3, $4, $5)
+RETURNING id, creator_id, winner_id, status, started_at, completed_at, created_at, updated_at, is_public, invite_token, is_solo, time_limit_minutes, last_event_seq, mode
 `
 
 type CreateGameParams struct {
@@ -103,6 +107,7 @@ type CreateGameParams struct {
 	IsPublic         bool        `json:"is_public"`
 	IsSolo           bool        `json:"is_solo"`
 	TimeLimitMinutes pgtype.Int2 `json:"time_limit_minutes"`
+	Mode             string      `json:"mode"`
 }
 
 func (q *Queries) CreateGame(ctx context.Context, arg CreateGameParams) (Game, error) {
@@ -111,6 +116,7 @@ func (q *Queries) CreateGame(ctx context.Context, arg CreateGameParams) (Game, e
 		arg.IsPublic,
 		arg.IsSolo,
 		arg.TimeLimitMinutes,
+		arg.Mode,
 	)
 	var i Game
 	err := row.Scan(
@@ -126,6 +132,8 @@ func (q *Queries) CreateGame(ctx context.Context, arg CreateGameParams) (Game, e
 		&i.InviteToken,
 		&i.IsSolo,
 		&i.TimeLimitMinutes,
+		&i.LastEventSeq,
+		&i.Mode,
 	)
 	return i, err
 }
@@ -143,7 +151,7 @@ func (q *Queries) DeleteGame(ctx context.Context, id int32) (int64, error) {
 }
 
 const getGameByID = `-- name: GetGameByID :one
-SELECT id, creator_id, winner_id, status, started_at, completed_at, created_at, updated_at, is_public, invite_token, is_solo, time_limit_minutes FROM games WHERE id = $1 LIMIT 1
+SELECT id, creator_id, winner_id, status, started_at, completed_at, created_at, updated_at, is_public, invite_token, is_solo, time_limit_minutes, last_event_seq, mode FROM games WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetGameByID(ctx context.Context, id int32) (Game, error) {
@@ -162,12 +170,14 @@ func (q *Queries) GetGameByID(ctx context.Context, id int32) (Game, error) {
 		&i.InviteToken,
 		&i.IsSolo,
 		&i.TimeLimitMinutes,
+		&i.LastEventSeq,
+		&i.Mode,
 	)
 	return i, err
 }
 
 const getGameByInviteToken = `-- name: GetGameByInviteToken :one
-SELECT id, creator_id, winner_id, status, started_at, completed_at, created_at, updated_at, is_public, invite_token, is_solo, time_limit_minutes FROM games WHERE invite_token = $1 LIMIT 1
+SELECT id, creator_id, winner_id, status, started_at, completed_at, created_at, updated_at, is_public, invite_token, is_solo, time_limit_minutes, last_event_seq, mode FROM games WHERE invite_token = $1 LIMIT 1
 `
 
 func (q *Queries) GetGameByInviteToken(ctx context.Context, inviteToken uuid.UUID) (Game, error) {
@@ -186,12 +196,14 @@ func (q *Queries) GetGameByInviteToken(ctx context.Context, inviteToken uuid.UUI
 		&i.InviteToken,
 		&i.IsSolo,
 		&i.TimeLimitMinutes,
+		&i.LastEventSeq,
+		&i.Mode,
 	)
 	return i, err
 }
 
 const getGameForUpdate = `-- name: GetGameForUpdate :one
-SELECT id, creator_id, winner_id, status, started_at, completed_at, created_at, updated_at, is_public, invite_token, is_solo, time_limit_minutes FROM games WHERE id = $1 LIMIT 1 FOR UPDATE
+SELECT id, creator_id, winner_id, status, started_at, completed_at, created_at, updated_at, is_public, invite_token, is_solo, time_limit_minutes, last_event_seq, mode FROM games WHERE id = $1 LIMIT 1 FOR UPDATE
 `
 
 func (q *Queries) GetGameForUpdate(ctx context.Context, id int32) (Game, error) {
@@ -210,12 +222,42 @@ func (q *Queries) GetGameForUpdate(ctx context.Context, id int32) (Game, error)
 		&i.InviteToken,
 		&i.IsSolo,
 		&i.TimeLimitMinutes,
+		&i.LastEventSeq,
+		&i.Mode,
 	)
 	return i, err
 }
 
+const listExpiredActiveGames = `-- name: ListExpiredActiveGames :many
+SELECT id FROM games
+WHERE status = 'active'
+  AND time_limit_minutes IS NOT NULL
+  AND started_at + make_interval(mins => time_limit_minutes) <= NOW()
+ORDER BY id
+`
+
+func (q *Queries) ListExpiredActiveGames(ctx context.Context) ([]int32, error) {
+	rows, err := q.db.Query(ctx, listExpiredActiveGames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int32{}
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listGamesForUser = `-- name: ListGamesForUser :many
-SELECT id, creator_id, winner_id, status, started_at, completed_at, created_at, updated_at, is_public, invite_token, is_solo, time_limit_minutes FROM games
+SELECT id, creator_id, winner_id, status, started_at, completed_at, created_at, updated_at, is_public, invite_token, is_solo, time_limit_minutes, last_event_seq, mode FROM games
 WHERE is_public = true
    OR creator_id = $3::uuid
    OR EXISTS (
@@ -253,6 +295,8 @@ func (q *Queries) ListGamesForUser(ctx context.Context, arg ListGamesForUserPara
 			&i.InviteToken,
 			&i.IsSolo,
 			&i.TimeLimitMinutes,
+			&i.LastEventSeq,
+			&i.Mode,
 		); err != nil {
 			return nil, err
 		}
@@ -270,7 +314,7 @@ SET status = 'active',
     started_at = NOW(),
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, creator_id, winner_id, status, started_at, completed_at, created_at, updated_at, is_public, invite_token, is_solo, time_limit_minutes
+RETURNING id, creator_id, winner_id, status, started_at, completed_at, created_at, updated_at, is_public, invite_token, is_solo, time_limit_minutes, last_event_seq, mode
 `
 
 func (q *Queries) StartGame(ctx context.Context, id int32) (Game, error) {
@@ -289,6 +333,8 @@ func (q *Queries) StartGame(ctx context.Context, id int32) (Game, error) {
 		&i.InviteToken,
 		&i.IsSolo,
 		&i.TimeLimitMinutes,
+		&i.LastEventSeq,
+		&i.Mode,
 	)
 	return i, err
 }
@@ -299,7 +345,7 @@ SET status = 'finished',
     completed_at = NOW(),
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, creator_id, winner_id, status, started_at, completed_at, created_at, updated_at, is_public, invite_token, is_solo, time_limit_minutes
+RETURNING id, creator_id, winner_id, status, started_at, completed_at, created_at, updated_at, is_public, invite_token, is_solo, time_limit_minutes, last_event_seq, mode
 `
 
 func (q *Queries) TimeoutGame(ctx context.Context, id int32) (Game, error) {
@@ -318,6 +364,8 @@ func (q *Queries) TimeoutGame(ctx context.Context, id int32) (Game, error) {
 		&i.InviteToken,
 		&i.IsSolo,
 		&i.TimeLimitMinutes,
+		&i.LastEventSeq,
+		&i.Mode,
 	)
 	return i, err
 }
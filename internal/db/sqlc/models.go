@@ -22,6 +22,15 @@ type Game struct {
 	InviteToken      uuid.UUID          `json:"invite_token"`
 	IsSolo           bool               `json:"is_solo"`
 	TimeLimitMinutes pgtype.Int2        `json:"time_limit_minutes"`
+	LastEventSeq     int64              `json:"last_event_seq"`
+	Mode             string             `json:"mode"`
+}
+
+type GameEvent struct {
+	GameID    int32              `json:"game_id"`
+	Seq       int64              `json:"seq"`
+	Payload   []byte             `json:"payload"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
 type GameParticipant struct {
@@ -38,6 +47,14 @@ type GameProblem struct {
 	ProblemVersionID int64  `json:"problem_version_id"`
 }
 
+type GameScore struct {
+	GameID       int32              `json:"game_id"`
+	UserID       uuid.UUID          `json:"user_id"`
+	ProblemIndex int32              `json:"problem_index"`
+	Score        int32              `json:"score"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+}
+
 type Problem struct {
 	ID               int64              `json:"id"`
 	Slug             string             `json:"slug"`
@@ -66,6 +83,16 @@ type ProblemVersion struct {
 	Difficulty        string             `json:"difficulty"`
 }
 
+type RatingHistory struct {
+	ID        int64              `json:"id"`
+	UserID    uuid.UUID          `json:"user_id"`
+	GameID    int32              `json:"game_id"`
+	Place     int32              `json:"place"`
+	OldRating int32              `json:"old_rating"`
+	NewRating int32              `json:"new_rating"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
 type Session struct {
 	ID        int32              `json:"id"`
 	UserID    uuid.UUID          `json:"user_id"`
@@ -88,6 +115,45 @@ type Solution struct {
 	CreatedAt        pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
 	ProblemVersionID int64              `json:"problem_version_id"`
+	Verdict          string             `json:"verdict"`
+	TestResults      []byte             `json:"test_results"`
+}
+
+type Submission struct {
+	ID               int64              `json:"id"`
+	UserID           uuid.UUID          `json:"user_id"`
+	GameID           pgtype.Int4        `json:"game_id"`
+	ProblemID        string             `json:"problem_id"`
+	ProblemVersionID int64              `json:"problem_version_id"`
+	Code             string             `json:"code"`
+	Language         string             `json:"language"`
+	Verdict          string             `json:"verdict"`
+	FailedTest       pgtype.Int4        `json:"failed_test"`
+	ExecutionTime    pgtype.Int4        `json:"execution_time"`
+	MemoryUsed       pgtype.Int4        `json:"memory_used"`
+	TestResults      []byte             `json:"test_results"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	Score            pgtype.Int4        `json:"score"`
+	JobID            pgtype.Int8        `json:"job_id"`
+}
+
+type SubmissionJob struct {
+	ID           int64              `json:"id"`
+	GameID       int32              `json:"game_id"`
+	UserID       uuid.UUID          `json:"user_id"`
+	ProblemIndex int32              `json:"problem_index"`
+	Code         string             `json:"code"`
+	Language     string             `json:"language"`
+	Status       string             `json:"status"`
+	Attempts     int32              `json:"attempts"`
+	InstanceID   string             `json:"instance_id"`
+	RunAfter     pgtype.Timestamptz `json:"run_after"`
+	LockedAt     pgtype.Timestamptz `json:"locked_at"`
+	LastError    pgtype.Text        `json:"last_error"`
+	Result       []byte             `json:"result"`
+	DeliveredAt  pgtype.Timestamptz `json:"delivered_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
 }
 
 type User struct {
@@ -96,7 +162,7 @@ type User struct {
 	Email         string             `json:"email"`
 	PasswordHash  pgtype.Text        `json:"password_hash"`
 	EmailVerified bool               `json:"email_verified"`
-	Rating        pgtype.Int4        `json:"rating"`
+	Rating        int32              `json:"rating"`
 	CreatedAt     pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
 	Name          pgtype.Text        `json:"name"`
@@ -109,3 +175,9 @@ type VerificationCode struct {
 	Attempts  int32              `json:"attempts"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
+
+type WsPayload struct {
+	ID        int64              `json:"id"`
+	Payload   []byte             `json:"payload"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
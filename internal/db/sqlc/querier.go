@@ -15,11 +15,18 @@ type Querier interface {
 	AddGameParticipant(ctx context.Context, arg AddGameParticipantParams) error
 	AddGameProblem(ctx context.Context, arg AddGameProblemParams) error
 	AdvanceParticipantProblem(ctx context.Context, arg AdvanceParticipantProblemParams) (int32, error)
+	// Numbers the event with the game's next sequence number and returns the
+	// payload with its "seq" field set.
+	AppendGameEvent(ctx context.Context, arg AppendGameEventParams) ([]byte, error)
 	CancelGame(ctx context.Context, id int32) (Game, error)
+	// An instance takes the jobs it queued itself, so the result can be pushed to
+	// the player's connection, and any job left waiting since orphaned_before.
+	ClaimSubmissionJob(ctx context.Context, arg ClaimSubmissionJobParams) (SubmissionJob, error)
 	CompleteGame(ctx context.Context, arg CompleteGameParams) (Game, error)
 	CountGameParticipants(ctx context.Context, gameID int32) (int64, error)
 	CountGameProblems(ctx context.Context, gameID int32) (int64, error)
 	CountGamesForUser(ctx context.Context, userID uuid.UUID) (int64, error)
+	CountLeaderboard(ctx context.Context) (int64, error)
 	CountProblemVersions(ctx context.Context, problemID int64) (int64, error)
 	CountPublicProblems(ctx context.Context, dollar_1 string) (int64, error)
 	CountUserProblems(ctx context.Context, ownerUserID uuid.NullUUID) (int64, error)
@@ -34,6 +41,8 @@ type Querier interface {
 	DeleteSession(ctx context.Context, id int32) (int64, error)
 	DeleteSessionsByUserID(ctx context.Context, userID uuid.UUID) (int64, error)
 	DeleteVerificationCode(ctx context.Context, email string) error
+	DeleteWSPayloadsBefore(ctx context.Context, createdAt pgtype.Timestamptz) (int64, error)
+	FinishSubmissionJob(ctx context.Context, arg FinishSubmissionJobParams) error
 	GetAllParticipantsProblemIndices(ctx context.Context, gameID int32) ([]GetAllParticipantsProblemIndicesRow, error)
 	GetGameByID(ctx context.Context, id int32) (Game, error)
 	GetGameByInviteToken(ctx context.Context, inviteToken uuid.UUID) (Game, error)
@@ -42,9 +51,22 @@ type Querier interface {
 	GetGameProblemIDByIndex(ctx context.Context, arg GetGameProblemIDByIndexParams) (string, error)
 	GetGameProblemIDs(ctx context.Context, gameID int32) ([]string, error)
 	GetGameProblemIDsByGameIDs(ctx context.Context, dollar_1 []int32) ([]GetGameProblemIDsByGameIDsRow, error)
+	GetGameScores(ctx context.Context, gameID int32) ([]GetGameScoresRow, error)
 	GetGameSolutions(ctx context.Context, gameID pgtype.Int4) ([]GetGameSolutionsRow, error)
+	// Locks the participants' user rows in a fixed order so concurrent rating
+	// updates of overlapping games cannot deadlock. total_score is only set in
+	// scoring games.
+	GetGameStandingsForUpdate(ctx context.Context, gameID int32) ([]GetGameStandingsForUpdateRow, error)
+	// Only users who have played at least one rated game are ranked.
+	GetLeaderboard(ctx context.Context, arg GetLeaderboardParams) ([]GetLeaderboardRow, error)
+	// The participant furthest ahead leads; ties go to whoever got there first.
+	GetLeadingParticipant(ctx context.Context, gameID int32) (uuid.UUID, error)
+	// The participant with the most points leads; ties go to whoever got there
+	// first.
+	GetLeadingScorer(ctx context.Context, gameID int32) (uuid.UUID, error)
 	GetMaxProblemVersion(ctx context.Context, problemID int64) (int32, error)
 	GetParticipantProblemIndex(ctx context.Context, arg GetParticipantProblemIndexParams) (int32, error)
+	GetParticipantTotalScore(ctx context.Context, arg GetParticipantTotalScoreParams) (int32, error)
 	GetParticipants(ctx context.Context, gameID int32) ([]GetParticipantsRow, error)
 	GetParticipantsByGameIDs(ctx context.Context, dollar_1 []int32) ([]GetParticipantsByGameIDsRow, error)
 	GetProblemCatalogBySlug(ctx context.Context, slug string) (Problem, error)
@@ -57,16 +79,35 @@ type Querier interface {
 	GetUserByUsername(ctx context.Context, username string) (User, error)
 	GetUserStats(ctx context.Context, userID uuid.NullUUID) (GetUserStatsRow, error)
 	GetVerificationCode(ctx context.Context, email string) (VerificationCode, error)
+	GetWSPayload(ctx context.Context, id int64) ([]byte, error)
 	IncrementAttemptsIfBelowLimit(ctx context.Context, arg IncrementAttemptsIfBelowLimitParams) (VerificationCode, error)
+	InsertRatingHistory(ctx context.Context, arg InsertRatingHistoryParams) error
 	InsertSolution(ctx context.Context, arg InsertSolutionParams) error
+	InsertSubmission(ctx context.Context, arg InsertSubmissionParams) error
+	InsertSubmissionJob(ctx context.Context, arg InsertSubmissionJobParams) (int64, error)
+	InsertWSPayload(ctx context.Context, payload []byte) (int64, error)
 	IsGameParticipant(ctx context.Context, arg IsGameParticipantParams) (bool, error)
+	ListExpiredActiveGames(ctx context.Context) ([]int32, error)
+	ListGameEventsAfter(ctx context.Context, arg ListGameEventsAfterParams) ([][]byte, error)
+	// A NULL user_id returns the submissions of every participant.
+	ListGameSubmissions(ctx context.Context, arg ListGameSubmissionsParams) ([]ListGameSubmissionsRow, error)
 	ListGamesForUser(ctx context.Context, arg ListGamesForUserParams) ([]Game, error)
 	ListMyProblems(ctx context.Context, arg ListMyProblemsParams) ([]ListMyProblemsRow, error)
 	ListPublicProblemsSearch(ctx context.Context, arg ListPublicProblemsSearchParams) ([]ListPublicProblemsSearchRow, error)
 	ListPublishedPublicProblems(ctx context.Context) ([]Problem, error)
 	ListPublishedPublicProblemsWithArtifact(ctx context.Context) ([]ListPublishedPublicProblemsWithArtifactRow, error)
+	ListUndeliveredSubmissionJobs(ctx context.Context, arg ListUndeliveredSubmissionJobsParams) ([]ListUndeliveredSubmissionJobsRow, error)
+	ListUserRatingHistory(ctx context.Context, arg ListUserRatingHistoryParams) ([]ListUserRatingHistoryRow, error)
 	LockProblemForUpdate(ctx context.Context, id int64) (int64, error)
+	MarkSubmissionJobDelivered(ctx context.Context, id int64) (int64, error)
+	NotifyWSEvent(ctx context.Context, arg NotifyWSEventParams) error
+	// Keeps the player's best score on the problem; no row is affected unless
+	// score beats it.
+	RecordGameScore(ctx context.Context, arg RecordGameScoreParams) (int64, error)
 	RemoveGameParticipant(ctx context.Context, arg RemoveGameParticipantParams) (int64, error)
+	// Jobs whose worker died while running them go back to the queue.
+	RequeueStaleSubmissionJobs(ctx context.Context, lockedAt pgtype.Timestamptz) (int64, error)
+	RetrySubmissionJob(ctx context.Context, arg RetrySubmissionJobParams) error
 	SetEmailVerified(ctx context.Context, id uuid.UUID) error
 	SetProblemCurrentVersion(ctx context.Context, arg SetProblemCurrentVersionParams) error
 	StartGame(ctx context.Context, id int32) (Game, error)
@@ -75,6 +116,7 @@ type Querier interface {
 	UpdateProblemVisibility(ctx context.Context, arg UpdateProblemVisibilityParams) error
 	UpdateSessionExpiry(ctx context.Context, arg UpdateSessionExpiryParams) (Session, error)
 	UpdateUserName(ctx context.Context, arg UpdateUserNameParams) (User, error)
+	UpdateUserRating(ctx context.Context, arg UpdateUserRatingParams) error
 	UpsertVerificationCode(ctx context.Context, arg UpsertVerificationCodeParams) (VerificationCode, error)
 }
 
@@ -0,0 +1,202 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: ratings.sql
+
+package sqlcdb
+
+import (
+	"context"
+
+	uuid "github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const countLeaderboard = `-- name: CountLeaderboard :one
+SELECT count(DISTINCT user_id) FROM rating_history
+`
+
+func (q *Queries) CountLeaderboard(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countLeaderboard)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getGameStandingsForUpdate = `-- name: GetGameStandingsForUpdate :many
+SELECT gp.user_id, gp.current_problem_index, u.rating,
+       (SELECT COALESCE(SUM(gs.score), 0) FROM game_scores gs
+        WHERE gs.game_id = gp.game_id AND gs.user_id = gp.user_id)::int AS total_score
+FROM game_participants gp
+JOIN users u ON u.id = gp.user_id
+WHERE gp.game_id = $1
+ORDER BY u.id
+FOR UPDATE OF u
+`
+
+type GetGameStandingsForUpdateRow struct {
+	UserID              uuid.UUID `json:"user_id"`
+	CurrentProblemIndex int32     `json:"current_problem_index"`
+	Rating              int32     `json:"rating"`
+	TotalScore          int32     `json:"total_score"`
+}
+
+// Locks the participants' user rows in a fixed order so concurrent rating
+// updates of overlapping games cannot deadlock. total_score is only set in
+// scoring games.
+func (q *Queries) GetGameStandingsForUpdate(ctx context.Context, gameID int32) ([]GetGameStandingsForUpdateRow, error) {
+	rows, err := q.db.Query(ctx, getGameStandingsForUpdate, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetGameStandingsForUpdateRow{}
+	for rows.Next() {
+		var i GetGameStandingsForUpdateRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.CurrentProblemIndex,
+			&i.Rating,
+			&i.TotalScore,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLeaderboard = `-- name: GetLeaderboard :many
+SELECT u.id, u.name, u.rating, count(rh.id)::int AS rated_games
+FROM users u
+JOIN rating_history rh ON rh.user_id = u.id
+GROUP BY u.id
+ORDER BY u.rating DESC, u.id
+LIMIT $1 OFFSET $2
+`
+
+type GetLeaderboardParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+type GetLeaderboardRow struct {
+	ID         uuid.UUID   `json:"id"`
+	Name       pgtype.Text `json:"name"`
+	Rating     int32       `json:"rating"`
+	RatedGames int32       `json:"rated_games"`
+}
+
+// Only users who have played at least one rated game are ranked.
+func (q *Queries) GetLeaderboard(ctx context.Context, arg GetLeaderboardParams) ([]GetLeaderboardRow, error) {
+	rows, err := q.db.Query(ctx, getLeaderboard, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetLeaderboardRow{}
+	for rows.Next() {
+		var i GetLeaderboardRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Rating,
+			&i.RatedGames,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertRatingHistory = `-- name: InsertRatingHistory :exec
+INSERT INTO rating_history (user_id, game_id, place, old_rating, new_rating)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+type InsertRatingHistoryParams struct {
+	UserID    uuid.UUID `json:"user_id"`
+	GameID    int32     `json:"game_id"`
+	Place     int32     `json:"place"`
+	OldRating int32     `json:"old_rating"`
+	NewRating int32     `json:"new_rating"`
+}
+
+func (q *Queries) InsertRatingHistory(ctx context.Context, arg InsertRatingHistoryParams) error {
+	_, err := q.db.Exec(ctx, insertRatingHistory,
+		arg.UserID,
+		arg.GameID,
+		arg.Place,
+		arg.OldRating,
+		arg.NewRating,
+	)
+	return err
+}
+
+const listUserRatingHistory = `-- name: ListUserRatingHistory :many
+SELECT game_id, place, old_rating, new_rating, created_at
+FROM rating_history
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2
+`
+
+type ListUserRatingHistoryParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	Limit  int32     `json:"limit"`
+}
+
+type ListUserRatingHistoryRow struct {
+	GameID    int32              `json:"game_id"`
+	Place     int32              `json:"place"`
+	OldRating int32              `json:"old_rating"`
+	NewRating int32              `json:"new_rating"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) ListUserRatingHistory(ctx context.Context, arg ListUserRatingHistoryParams) ([]ListUserRatingHistoryRow, error) {
+	rows, err := q.db.Query(ctx, listUserRatingHistory, arg.UserID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUserRatingHistoryRow{}
+	for rows.Next() {
+		var i ListUserRatingHistoryRow
+		if err := rows.Scan(
+			&i.GameID,
+			&i.Place,
+			&i.OldRating,
+			&i.NewRating,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateUserRating = `-- name: UpdateUserRating :exec
+UPDATE users SET rating = $2, updated_at = NOW() WHERE id = $1
+`
+
+type UpdateUserRatingParams struct {
+	ID     uuid.UUID `json:"id"`
+	Rating int32     `json:"rating"`
+}
+
+func (q *Queries) UpdateUserRating(ctx context.Context, arg UpdateUserRatingParams) error {
+	_, err := q.db.Exec(ctx, updateUserRating, arg.ID, arg.Rating)
+	return err
+}
@@ -66,8 +66,8 @@ func (q *Queries) GetGameSolutions(ctx context.Context, gameID pgtype.Int4) ([]G
 }
 
 const insertSolution = `-- name: InsertSolution :exec
-INSERT INTO solutions (user_id, problem_id, problem_version_id, game_id, code, language, status)
-VALUES ($1, $2, $3, $4, $5, $6, 'passed')
+INSERT INTO solutions (user_id, problem_id, problem_version_id, game_id, code, language, status, verdict, test_results, execution_time, memory_used)
+VALUES ($1, $2, $3, $4, $5, $6, 'passed', $7, $8, $9, $10)
 ON CONFLICT (user_id, game_id, problem_id) DO NOTHING
 `
 
@@ -78,6 +78,10 @@ type InsertSolutionParams struct {
 	GameID           pgtype.Int4 `json:"game_id"`
 	Code             string      `json:"code"`
 	Language         string      `json:"language"`
+	Verdict          string      `json:"verdict"`
+	TestResults      []byte      `json:"test_results"`
+	ExecutionTime    pgtype.Int4 `json:"execution_time"`
+	MemoryUsed       pgtype.Int4 `json:"memory_used"`
 }
 
 func (q *Queries) InsertSolution(ctx context.Context, arg InsertSolutionParams) error {
@@ -88,6 +92,10 @@ func (q *Queries) InsertSolution(ctx context.Context, arg InsertSolutionParams)
 		arg.GameID,
 		arg.Code,
 		arg.Language,
+		arg.Verdict,
+		arg.TestResults,
+		arg.ExecutionTime,
+		arg.MemoryUsed,
 	)
 	return err
 }
@@ -0,0 +1,138 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: submissions.sql
+
+package sqlcdb
+
+import (
+	"context"
+
+	uuid "github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const insertSubmission = `-- name: InsertSubmission :exec
+INSERT INTO submissions (
+    user_id, game_id, problem_id, problem_version_id, code, language,
+    verdict, failed_test, execution_time, memory_used, test_results, score, job_id
+)
+VALUES (
+    $1, $2, $3, $4, $5, $6,
+    $7, $8, $9, $10, $11, $12, $13
+)
+ON CONFLICT (job_id) DO NOTHING
+`
+
+type InsertSubmissionParams struct {
+	UserID           uuid.UUID   `json:"user_id"`
+	GameID           pgtype.Int4 `json:"game_id"`
+	ProblemID        string      `json:"problem_id"`
+	ProblemVersionID int64       `json:"problem_version_id"`
+	Code             string      `json:"code"`
+	Language         string      `json:"language"`
+	Verdict          string      `json:"verdict"`
+	FailedTest       pgtype.Int4 `json:"failed_test"`
+	ExecutionTime    pgtype.Int4 `json:"execution_time"`
+	MemoryUsed       pgtype.Int4 `json:"memory_used"`
+	TestResults      []byte      `json:"test_results"`
+	Score            pgtype.Int4 `json:"score"`
+	JobID            pgtype.Int8 `json:"job_id"`
+}
+
+func (q *Queries) InsertSubmission(ctx context.Context, arg InsertSubmissionParams) error {
+	_, err := q.db.Exec(ctx, insertSubmission,
+		arg.UserID,
+		arg.GameID,
+		arg.ProblemID,
+		arg.ProblemVersionID,
+		arg.Code,
+		arg.Language,
+		arg.Verdict,
+		arg.FailedTest,
+		arg.ExecutionTime,
+		arg.MemoryUsed,
+		arg.TestResults,
+		arg.Score,
+		arg.JobID,
+	)
+	return err
+}
+
+const listGameSubmissions = `-- name: ListGameSubmissions :many
+SELECT
+    s.id,
+    s.user_id,
+    s.problem_id,
+    s.code,
+    s.language,
+    s.verdict,
+    s.failed_test,
+    s.execution_time,
+    s.memory_used,
+    s.score,
+    s.created_at,
+    u.username,
+    u.name
+FROM submissions s
+JOIN users u ON u.id = s.user_id
+WHERE s.game_id = $1
+  AND ($2::uuid IS NULL OR s.user_id = $2::uuid)
+ORDER BY s.created_at, s.id
+`
+
+type ListGameSubmissionsParams struct {
+	GameID pgtype.Int4   `json:"game_id"`
+	UserID uuid.NullUUID `json:"user_id"`
+}
+
+type ListGameSubmissionsRow struct {
+	ID            int64              `json:"id"`
+	UserID        uuid.UUID          `json:"user_id"`
+	ProblemID     string             `json:"problem_id"`
+	Code          string             `json:"code"`
+	Language      string             `json:"language"`
+	Verdict       string             `json:"verdict"`
+	FailedTest    pgtype.Int4        `json:"failed_test"`
+	ExecutionTime pgtype.Int4        `json:"execution_time"`
+	MemoryUsed    pgtype.Int4        `json:"memory_used"`
+	Score         pgtype.Int4        `json:"score"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	Username      string             `json:"username"`
+	Name          pgtype.Text        `json:"name"`
+}
+
+// A NULL user_id returns the submissions of every participant.
+func (q *Queries) ListGameSubmissions(ctx context.Context, arg ListGameSubmissionsParams) ([]ListGameSubmissionsRow, error) {
+	rows, err := q.db.Query(ctx, listGameSubmissions, arg.GameID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListGameSubmissionsRow{}
+	for rows.Next() {
+		var i ListGameSubmissionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ProblemID,
+			&i.Code,
+			&i.Language,
+			&i.Verdict,
+			&i.FailedTest,
+			&i.ExecutionTime,
+			&i.MemoryUsed,
+			&i.Score,
+			&i.CreatedAt,
+			&i.Username,
+			&i.Name,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -136,7 +136,8 @@ const getUserStats = `-- name: GetUserStats :one
 SELECT
     COUNT(*) FILTER (WHERE g.winner_id = $1 AND g.is_solo = false)::int AS wins,
     COUNT(*)::int AS games_played,
-    COALESCE(SUM(gp.current_problem_index), 0)::int AS problems_solved
+    COALESCE(SUM(gp.current_problem_index), 0)::int AS problems_solved,
+    (SELECT u.rating FROM users u WHERE u.id = $1)::int AS rating
 FROM game_participants gp
 JOIN games g ON g.id = gp.game_id
 WHERE gp.user_id = $1
@@ -147,12 +148,18 @@ type GetUserStatsRow struct {
 	Wins           int32 `json:"wins"`
 	GamesPlayed    int32 `json:"games_played"`
 	ProblemsSolved int32 `json:"problems_solved"`
+	Rating         int32 `json:"rating"`
 }
 
 func (q *Queries) GetUserStats(ctx context.Context, userID uuid.NullUUID) (GetUserStatsRow, error) {
 	row := q.db.QueryRow(ctx, getUserStats, userID)
 	var i GetUserStatsRow
-	err := row.Scan(&i.Wins, &i.GamesPlayed, &i.ProblemsSolved)
+	err := row.Scan(
+		&i.Wins,
+		&i.GamesPlayed,
+		&i.ProblemsSolved,
+		&i.Rating,
+	)
 	return i, err
 }
 
@@ -0,0 +1,64 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: ws_payloads.sql
+
+package sqlcdb
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const deleteWSPayloadsBefore = `-- name: DeleteWSPayloadsBefore :execrows
+DELETE FROM ws_payloads
+WHERE created_at < $1
+`
+
+func (q *Queries) DeleteWSPayloadsBefore(ctx context.Context, createdAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteWSPayloadsBefore, createdAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getWSPayload = `-- name: GetWSPayload :one
+SELECT payload FROM ws_payloads
+WHERE id = $1
+`
+
+func (q *Queries) GetWSPayload(ctx context.Context, id int64) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getWSPayload, id)
+	var payload []byte
+	err := row.Scan(&payload)
+	return payload, err
+}
+
+const insertWSPayload = `-- name: InsertWSPayload :one
+INSERT INTO ws_payloads (payload)
+VALUES ($1)
+RETURNING id
+`
+
+func (q *Queries) InsertWSPayload(ctx context.Context, payload []byte) (int64, error) {
+	row := q.db.QueryRow(ctx, insertWSPayload, payload)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const notifyWSEvent = `-- name: NotifyWSEvent :exec
+SELECT pg_notify($1::text, $2::text)
+`
+
+type NotifyWSEventParams struct {
+	Channel string `json:"channel"`
+	Payload string `json:"payload"`
+}
+
+func (q *Queries) NotifyWSEvent(ctx context.Context, arg NotifyWSEventParams) error {
+	_, err := q.db.Exec(ctx, notifyWSEvent, arg.Channel, arg.Payload)
+	return err
+}
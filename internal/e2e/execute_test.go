@@ -1,10 +1,16 @@
 package e2e_test
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
 	"net/http"
 	"strings"
 	"testing"
 
+	"bytebattle/internal/apierr"
+	"bytebattle/internal/executor"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -51,3 +57,98 @@ func TestExecute_Auth(t *testing.T) {
 		resp.Body.Close()
 	})
 }
+
+// streamingExecutor streams two lines of output before returning, or fails
+// with err.
+type streamingExecutor struct {
+	err error
+}
+
+func (e streamingExecutor) Run(ctx context.Context, req executor.ExecutionRequest) (executor.ExecutionResult, error) {
+	return e.RunStream(ctx, req, func(executor.OutputChunk) {})
+}
+
+func (e streamingExecutor) RunStream(_ context.Context, _ executor.ExecutionRequest, onOutput func(executor.OutputChunk)) (executor.ExecutionResult, error) {
+	if e.err != nil {
+		return executor.ExecutionResult{Error: e.err}, e.err
+	}
+	onOutput(executor.OutputChunk{Stream: executor.StreamStdout, Data: "1\n"})
+	onOutput(executor.OutputChunk{Stream: executor.StreamStderr, Data: "warn\n"})
+	return executor.ExecutionResult{Stdout: "1\n", Stderr: "warn\n", ExitCode: 3}, nil
+}
+
+func (streamingExecutor) IsReady() bool { return true }
+
+type sseEvent struct {
+	name string
+	data string
+}
+
+func readSSE(t *testing.T, resp *http.Response) []sseEvent {
+	t.Helper()
+	defer resp.Body.Close()
+	var events []sseEvent
+	var ev sseEvent
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			events = append(events, ev)
+			ev = sseEvent{}
+		case strings.HasPrefix(line, "event: "):
+			ev.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			ev.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func TestExecuteStream(t *testing.T) {
+	srv := newGameServer(t, streamingExecutor{})
+
+	resp := doOnServer(t, srv, http.MethodPost, "/api/execute/stream",
+		map[string]string{"code": "x", "language": "go", "input": ""}, token1)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	events := readSSE(t, resp)
+	require.Len(t, events, 3)
+	assert.Equal(t, sseEvent{"output", `{"stream":"stdout","data":"1\n"}`}, events[0])
+	assert.Equal(t, sseEvent{"output", `{"stream":"stderr","data":"warn\n"}`}, events[1])
+	assert.Equal(t, "result", events[2].name)
+	var result map[string]any
+	require.NoError(t, json.Unmarshal([]byte(events[2].data), &result))
+	assert.Equal(t, "1\n", result["stdout"])
+	assert.InDelta(t, 3, result["exit_code"], 0)
+}
+
+func TestExecuteStream_Errors(t *testing.T) {
+	srv := newGameServer(t, streamingExecutor{})
+
+	t.Run("no auth", func(t *testing.T) {
+		resp := doOnServer(t, srv, http.MethodPost, "/api/execute/stream",
+			map[string]string{"code": "x", "language": "go", "input": ""}, "")
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		assert.Equal(t, "INVALID_TOKEN", errCode(t, resp))
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		resp := doOnServer(t, srv, http.MethodPost, "/api/execute/stream", "not an object", token1)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, "VALIDATION_ERROR", errCode(t, resp))
+	})
+
+	t.Run("run failure", func(t *testing.T) {
+		srv := newGameServer(t, streamingExecutor{err: apierr.New(apierr.ErrExecutorOverloaded, "busy")})
+		resp := doOnServer(t, srv, http.MethodPost, "/api/execute/stream",
+			map[string]string{"code": "x", "language": "go", "input": ""}, token1)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		events := readSSE(t, resp)
+		require.Len(t, events, 1)
+		assert.Equal(t, "error", events[0].name)
+		assert.Contains(t, events[0].data, apierr.ErrExecutorOverloaded)
+	})
+}
@@ -0,0 +1,80 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sqlcdb "bytebattle/internal/db/sqlc"
+	"bytebattle/internal/service"
+)
+
+// startTimedGame creates and starts a two-player game with two problems and
+// moves its start time past the time limit.
+func startTimedGame(t *testing.T) gameResp {
+	t.Helper()
+	resp := doAuth(t, http.MethodPost, "/api/games", map[string]any{
+		"problem_ids":        []string{"test-problem", "test-problem"},
+		"time_limit_minutes": 5,
+	}, token1)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var g gameResp
+	decodeJSON(t, resp, &g)
+
+	require.NotNil(t, g.Game.InviteToken)
+	resp = doAuth(t, http.MethodPost, fmt.Sprintf("/api/games/join/%s", *g.Game.InviteToken), nil, token2)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	resp = doAuth(t, http.MethodPost, fmt.Sprintf("/api/games/%d/start", g.Game.ID), nil, token1)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	_, err := testPool.Exec(context.Background(),
+		`UPDATE games SET started_at = NOW() - INTERVAL '6 minutes' WHERE id = $1`, g.Game.ID)
+	require.NoError(t, err)
+	return g
+}
+
+func getGame(t *testing.T, id int) gameResp {
+	t.Helper()
+	resp := doAuth(t, http.MethodGet, fmt.Sprintf("/api/games/%d", id), nil, token1)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var g gameResp
+	decodeJSON(t, resp, &g)
+	return g
+}
+
+func TestGame_ExpiredGameFinishedByProgress(t *testing.T) {
+	g := startTimedGame(t)
+	_, err := testPool.Exec(context.Background(),
+		`UPDATE game_participants SET current_problem_index = 1 WHERE game_id = $1 AND user_id = $2`, g.Game.ID, user2ID)
+	require.NoError(t, err)
+
+	gameSvc := service.NewGameService(sqlcdb.New(testPool), testPool)
+	// The server's own expiry loop may get there first; either way the
+	// outcome must be the same.
+	_, err = gameSvc.FinishExpiredGames(context.Background())
+	require.NoError(t, err)
+
+	got := getGame(t, g.Game.ID)
+	assert.Equal(t, "finished", got.Game.Status)
+	require.NotNil(t, got.Game.WinnerID)
+	assert.Equal(t, user2ID.String(), *got.Game.WinnerID)
+}
+
+func TestGame_ExpiredGameWithoutProgressHasNoWinner(t *testing.T) {
+	g := startTimedGame(t)
+
+	gameSvc := service.NewGameService(sqlcdb.New(testPool), testPool)
+	_, err := gameSvc.FinishExpiredGames(context.Background())
+	require.NoError(t, err)
+
+	got := getGame(t, g.Game.ID)
+	assert.Equal(t, "finished", got.Game.Status)
+	assert.Nil(t, got.Game.WinnerID)
+}
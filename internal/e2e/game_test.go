@@ -20,9 +20,11 @@ type gameResp struct {
 		InviteToken      *string  `json:"invite_token"`
 		WinnerID         *string  `json:"winner_id"`
 		TimeLimitMinutes *int     `json:"time_limit_minutes"`
+		Mode             string   `json:"mode"`
 		Participants     []struct {
-			ID   string  `json:"id"`
-			Name *string `json:"name"`
+			ID     string  `json:"id"`
+			Name   *string `json:"name"`
+			Scores []int   `json:"scores"`
 		} `json:"participants"`
 	} `json:"game"`
 }
@@ -344,8 +344,14 @@ func newGameServer(t *testing.T, exec executor.Executor, rlCfg ...service.RateLi
 	if len(rlCfg) > 0 {
 		cfg = rlCfg[0]
 	}
-	srv := httptest.NewServer(app.NewRouterWithExecutor(testPool, exec, testStore, config.Load(), cfg))
-	t.Cleanup(srv.Close)
+	router := app.NewRouterWithExecutor(testPool, exec, testStore, config.Load(), cfg)
+	srv := httptest.NewServer(router)
+	t.Cleanup(func() {
+		srv.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		require.NoError(t, router.Shutdown(ctx), "submission workers must stop")
+	})
 	return srv
 }
 
@@ -73,6 +73,13 @@ func TestGame_PrivateGame(t *testing.T) {
 		assert.Equal(t, "NOT_PARTICIPANT", errCode(t, resp))
 	})
 
+	t.Run("GET /games/{id}/submissions: 403 for outsider on private game", func(t *testing.T) {
+		token4 := authToken(t, "private-test-outsider2@test.com")
+		resp := doAuth(t, http.MethodGet, fmt.Sprintf("/api/games/%d/submissions", g.Game.ID), nil, token4)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		assert.Equal(t, "NOT_PARTICIPANT", errCode(t, resp))
+	})
+
 	t.Run("invite_token visible to creator in listing", func(t *testing.T) {
 		resp := doAuth(t, http.MethodGet, "/api/games?limit=100&offset=0", nil, token1)
 		require.Equal(t, http.StatusOK, resp.StatusCode)
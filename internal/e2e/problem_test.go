@@ -23,6 +23,10 @@ type problemResp struct {
 		TimeLimitMs   int    `json:"time_limit_ms"`
 		MemoryLimitMb int    `json:"memory_limit_mb"`
 		TestCount     *int   `json:"test_count"`
+		Samples       []struct {
+			Input  string `json:"input"`
+			Output string `json:"output"`
+		} `json:"samples"`
 	} `json:"problem"`
 }
 
@@ -64,6 +68,9 @@ func TestProblem_GetByID(t *testing.T) {
 	assert.Equal(t, 256, p.Problem.MemoryLimitMb)
 	require.NotNil(t, p.Problem.TestCount)
 	assert.Equal(t, 2, *p.Problem.TestCount)
+	// Only the test in tests/samples/ is shown.
+	require.Len(t, p.Problem.Samples, 1)
+	assert.Equal(t, "1 2", p.Problem.Samples[0].Input)
 }
 
 func TestProblem_GetByID_NotFound(t *testing.T) {
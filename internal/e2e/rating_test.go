@@ -0,0 +1,120 @@
+package e2e_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type statsResp struct {
+	Wins          int `json:"wins"`
+	GamesPlayed   int `json:"games_played"`
+	Rating        int `json:"rating"`
+	RatingHistory []struct {
+		GameID    int `json:"game_id"`
+		Place     int `json:"place"`
+		OldRating int `json:"old_rating"`
+		NewRating int `json:"new_rating"`
+	} `json:"rating_history"`
+}
+
+type leaderboardResp struct {
+	Entries []struct {
+		Rank       int    `json:"rank"`
+		UserID     string `json:"user_id"`
+		Rating     int    `json:"rating"`
+		RatedGames int    `json:"rated_games"`
+	} `json:"entries"`
+	Total int64 `json:"total"`
+}
+
+func getStats(t *testing.T, token string) statsResp {
+	t.Helper()
+	resp := doAuth(t, http.MethodGet, "/api/auth/me/stats", nil, token)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var s statsResp
+	decodeJSON(t, resp, &s)
+	return s
+}
+
+func TestRating_UpdatedWhenGameCompletes(t *testing.T) {
+	before1 := getStats(t, token1)
+	before2 := getStats(t, token2)
+
+	g := createActiveGame(t)
+	resp := doAuth(t, http.MethodPost, fmt.Sprintf("/api/games/%d/complete", g.Game.ID), map[string]any{
+		"winner_id": user1ID.String(),
+	}, token1)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	after1 := getStats(t, token1)
+	after2 := getStats(t, token2)
+	assert.Greater(t, after1.Rating, before1.Rating)
+	assert.Less(t, after2.Rating, before2.Rating)
+
+	require.NotEmpty(t, after1.RatingHistory)
+	assert.Equal(t, g.Game.ID, after1.RatingHistory[0].GameID)
+	assert.Equal(t, 1, after1.RatingHistory[0].Place)
+	assert.Equal(t, before1.Rating, after1.RatingHistory[0].OldRating)
+	assert.Equal(t, after1.Rating, after1.RatingHistory[0].NewRating)
+
+	require.NotEmpty(t, after2.RatingHistory)
+	assert.Equal(t, g.Game.ID, after2.RatingHistory[0].GameID)
+	assert.Equal(t, 2, after2.RatingHistory[0].Place)
+}
+
+func TestRating_SoloGameIsNotRated(t *testing.T) {
+	before := getStats(t, token1)
+
+	resp := doAuth(t, http.MethodPost, "/api/games", map[string]any{
+		"problem_ids": []string{"test-problem"},
+		"is_solo":     true,
+	}, token1)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var g gameResp
+	decodeJSON(t, resp, &g)
+	resp = doAuth(t, http.MethodPost, fmt.Sprintf("/api/games/%d/start", g.Game.ID), nil, token1)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+	resp = doAuth(t, http.MethodPost, fmt.Sprintf("/api/games/%d/complete", g.Game.ID), map[string]any{
+		"winner_id": user1ID.String(),
+	}, token1)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	after := getStats(t, token1)
+	assert.Equal(t, before.Rating, after.Rating)
+	assert.Equal(t, len(before.RatingHistory), len(after.RatingHistory))
+}
+
+func TestLeaderboard(t *testing.T) {
+	g := createActiveGame(t)
+	resp := doAuth(t, http.MethodPost, fmt.Sprintf("/api/games/%d/complete", g.Game.ID), map[string]any{
+		"winner_id": user2ID.String(),
+	}, token1)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	// The leaderboard is public.
+	resp = do(t, http.MethodGet, "/api/leaderboard?limit=100", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var lb leaderboardResp
+	decodeJSON(t, resp, &lb)
+
+	assert.GreaterOrEqual(t, lb.Total, int64(2))
+	ranked := map[string]bool{}
+	for i, e := range lb.Entries {
+		assert.Equal(t, i+1, e.Rank)
+		assert.Positive(t, e.RatedGames)
+		if i > 0 {
+			assert.LessOrEqual(t, e.Rating, lb.Entries[i-1].Rating)
+		}
+		ranked[e.UserID] = true
+	}
+	assert.True(t, ranked[user1ID.String()])
+	assert.True(t, ranked[user2ID.String()])
+}
@@ -0,0 +1,172 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sqlcdb "bytebattle/internal/db/sqlc"
+	"bytebattle/internal/executor"
+	"bytebattle/internal/service"
+	"bytebattle/internal/ws"
+)
+
+// startScoringGame creates and starts a two-player scoring game with two
+// problems on srv.
+func startScoringGame(t *testing.T, srv *httptest.Server) gameResp {
+	t.Helper()
+	resp := doOnServer(t, srv, http.MethodPost, "/api/games", map[string]any{
+		"problem_ids":        []string{"test-problem", "test-problem"},
+		"time_limit_minutes": 30,
+		"mode":               "scoring",
+	}, token1)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var g gameResp
+	decodeJSON(t, resp, &g)
+	require.Equal(t, "scoring", g.Game.Mode)
+	require.NotNil(t, g.Game.InviteToken)
+
+	resp = doOnServer(t, srv, http.MethodPost, fmt.Sprintf("/api/games/join/%s", *g.Game.InviteToken), nil, token2)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	resp = doOnServer(t, srv, http.MethodPost, fmt.Sprintf("/api/games/%d/start", g.Game.ID), nil, token1)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+	return g
+}
+
+func TestScoringGame_RequiresTimeLimit(t *testing.T) {
+	resp := doAuth(t, http.MethodPost, "/api/games", map[string]any{
+		"problem_ids": []string{"test-problem"},
+		"mode":        "scoring",
+	}, token1)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "VALIDATION_ERROR", errCode(t, resp))
+}
+
+func TestScoringGame_SubmitAnyProblemAndWinWithFullScore(t *testing.T) {
+	srv := newGameServer(t, correctExecutor{})
+	g := startScoringGame(t, srv)
+	wsPath := fmt.Sprintf("/api/games/%d/ws", g.Game.ID)
+
+	conn := wsConnectOnServer(t, srv, wsPath, token1)
+	wsReadUntilType(t, conn, ws.TypePlayerState)
+
+	second := int32(1)
+	require.NoError(t, conn.WriteJSON(ws.ClientMessage{
+		Type:         ws.TypeSubmit,
+		Code:         "print('hello')",
+		Language:     "python",
+		ProblemIndex: &second,
+	}))
+	res := wsReadUntilType(t, conn, ws.TypeSubmissionResult)
+	assert.True(t, res.Accepted)
+	assert.Equal(t, 1, res.ProblemIdx)
+	require.NotNil(t, res.Score)
+	assert.Equal(t, 100, *res.Score)
+	assert.Equal(t, 100, res.MaxScore)
+
+	updated := wsReadUntilType(t, conn, ws.TypeScoreUpdated)
+	assert.Equal(t, user1ID, updated.UserID)
+	assert.Equal(t, []int{0, 100}, updated.Scores[user1ID.String()])
+
+	resp := doOnServer(t, srv, http.MethodGet, fmt.Sprintf("/api/games/%d", g.Game.ID), nil, token2)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var got gameResp
+	decodeJSON(t, resp, &got)
+	scores := map[string][]int{}
+	for _, p := range got.Game.Participants {
+		scores[p.ID] = p.Scores
+	}
+	assert.Equal(t, []int{0, 100}, scores[user1ID.String()])
+	assert.Equal(t, []int{0, 0}, scores[user2ID.String()])
+
+	first := int32(0)
+	require.NoError(t, conn.WriteJSON(ws.ClientMessage{
+		Type:         ws.TypeSubmit,
+		Code:         "print('hello')",
+		Language:     "python",
+		ProblemIndex: &first,
+	}))
+	finished := wsReadUntilType(t, conn, ws.TypeGameFinished)
+	assert.Equal(t, user1ID, finished.WinnerID)
+}
+
+func TestScoringGame_SubmitWithoutProblemIndex(t *testing.T) {
+	srv := newGameServer(t, correctExecutor{})
+	g := startScoringGame(t, srv)
+
+	conn := wsConnectOnServer(t, srv, fmt.Sprintf("/api/games/%d/ws", g.Game.ID), token1)
+	wsReadUntilType(t, conn, ws.TypePlayerState)
+	require.NoError(t, conn.WriteJSON(ws.ClientMessage{Type: ws.TypeSubmit, Code: "print('hello')", Language: "python"}))
+
+	msg := wsReadUntilType(t, conn, ws.TypeError)
+	assert.Equal(t, "VALIDATION_ERROR", msg.ErrorCode)
+}
+
+func TestScoringGame_ExpiredGameFinishedByScore(t *testing.T) {
+	srv := newGameServer(t, correctExecutor{})
+	g := startScoringGame(t, srv)
+	_, err := testPool.Exec(context.Background(),
+		`UPDATE games SET started_at = NOW() - INTERVAL '31 minutes' WHERE id = $1`, g.Game.ID)
+	require.NoError(t, err)
+	_, err = testPool.Exec(context.Background(),
+		`INSERT INTO game_scores (game_id, user_id, problem_index, score) VALUES ($1, $2, 0, 40), ($1, $3, 1, 60)`,
+		g.Game.ID, user1ID, user2ID)
+	require.NoError(t, err)
+
+	gameSvc := service.NewGameService(sqlcdb.New(testPool), testPool)
+	_, err = gameSvc.FinishExpiredGames(context.Background())
+	require.NoError(t, err)
+
+	got := getGame(t, g.Game.ID)
+	assert.Equal(t, "finished", got.Game.Status)
+	require.NotNil(t, got.Game.WinnerID)
+	assert.Equal(t, user2ID.String(), *got.Game.WinnerID)
+}
+
+// expiringExecutor accepts every submission, running the game out of time
+// while it is being judged.
+type expiringExecutor struct {
+	correctExecutor
+	gameID atomic.Int64
+}
+
+func (e *expiringExecutor) Run(ctx context.Context, req executor.ExecutionRequest) (executor.ExecutionResult, error) {
+	if _, err := testPool.Exec(ctx,
+		`UPDATE games SET started_at = NOW() - INTERVAL '31 minutes' WHERE id = $1`, e.gameID.Load()); err != nil {
+		return executor.ExecutionResult{}, err
+	}
+	return e.correctExecutor.Run(ctx, req)
+}
+
+func TestScoringGame_ScoreAfterDeadlineNotCounted(t *testing.T) {
+	exec := &expiringExecutor{}
+	srv := newGameServer(t, exec)
+	g := startScoringGame(t, srv)
+	exec.gameID.Store(int64(g.Game.ID))
+	conn := wsConnectOnServer(t, srv, fmt.Sprintf("/api/games/%d/ws", g.Game.ID), token1)
+	wsReadUntilType(t, conn, ws.TypePlayerState)
+
+	first := int32(0)
+	require.NoError(t, conn.WriteJSON(ws.ClientMessage{
+		Type:         ws.TypeSubmit,
+		Code:         "print('hello')",
+		Language:     "python",
+		ProblemIndex: &first,
+	}))
+	res := wsReadUntilType(t, conn, ws.TypeSubmissionResult)
+	assert.True(t, res.Accepted)
+
+	var count int
+	require.NoError(t, testPool.QueryRow(context.Background(),
+		`SELECT COUNT(*) FROM game_scores WHERE game_id = $1`, g.Game.ID).Scan(&count))
+	assert.Zero(t, count)
+}
@@ -0,0 +1,70 @@
+package e2e_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bytebattle/internal/ws"
+)
+
+func TestGameWS_Spectator(t *testing.T) {
+	srv := newGameServer(t, correctExecutor{})
+	g := createActiveGameOnServer(t, srv)
+	wsPath := fmt.Sprintf("/api/games/%d/ws", g.Game.ID)
+
+	conn1 := wsConnectOnServer(t, srv, wsPath, token1)
+	wsReadUntilType(t, conn1, ws.TypePlayerJoined)
+
+	spectatorToken := authToken(t, "spectator@test.com")
+	spec := wsConnectOnServer(t, srv, wsPath, spectatorToken)
+	state := wsReadUntilType(t, spec, ws.TypeSpectatorState)
+	require.NotNil(t, state.SpectatorCount)
+	assert.Equal(t, 1, *state.SpectatorCount)
+	assert.Contains(t, state.Progress, user1ID.String())
+
+	count := wsReadUntilType(t, conn1, ws.TypeSpectators)
+	require.NotNil(t, count.SpectatorCount)
+	assert.Equal(t, 1, *count.SpectatorCount)
+
+	// Spectators cannot submit.
+	require.NoError(t, spec.WriteJSON(ws.ClientMessage{Type: ws.TypeSubmit, Code: "x", Language: "go"}))
+	errMsg := wsReadUntilType(t, spec, ws.TypeError)
+	assert.Equal(t, "NOT_PARTICIPANT", errMsg.ErrorCode)
+
+	// They see the players' events.
+	conn2 := wsConnectOnServer(t, srv, wsPath, token2)
+	joined := wsReadUntilType(t, spec, ws.TypePlayerJoined)
+	assert.Equal(t, user2ID, joined.UserID)
+
+	require.NoError(t, conn2.WriteJSON(ws.ClientMessage{Type: ws.TypeSubmit, Code: "x", Language: "go"}))
+	finished := wsReadUntilType(t, spec, ws.TypeGameFinished)
+	assert.Equal(t, user2ID, finished.WinnerID)
+
+	require.NoError(t, spec.Close())
+	count = wsReadUntilType(t, conn1, ws.TypeSpectators)
+	assert.Equal(t, 0, *count.SpectatorCount)
+}
+
+func TestGameWS_SpectatorPrivateGame(t *testing.T) {
+	resp := doAuth(t, http.MethodPost, "/api/games", map[string]any{
+		"problem_ids": []string{"test-problem"},
+		"is_public":   false,
+	}, token1)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var g gameResp
+	decodeJSON(t, resp, &g)
+	resp = doAuth(t, http.MethodPost, fmt.Sprintf("/api/games/join/%s", *g.Game.InviteToken), nil, token2)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+	resp = doAuth(t, http.MethodPost, fmt.Sprintf("/api/games/%d/start", g.Game.ID), nil, token1)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	_, resp = wsDial(t, fmt.Sprintf("/api/games/%d/ws", g.Game.ID), authToken(t, "private-spectator@test.com"))
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
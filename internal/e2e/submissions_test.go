@@ -0,0 +1,60 @@
+package e2e_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bytebattle/internal/ws"
+)
+
+type submissionsResp struct {
+	Submissions []struct {
+		UserID     string `json:"user_id"`
+		ProblemID  string `json:"problem_id"`
+		Verdict    string `json:"verdict"`
+		FailedTest *int   `json:"failed_test"`
+		Code       string `json:"code"`
+	} `json:"submissions"`
+}
+
+func TestGame_Submissions(t *testing.T) {
+	srv := newGameServer(t, failingExecutor{})
+	g := createActiveGameOnServer(t, srv)
+	path := fmt.Sprintf("/api/games/%d/submissions", g.Game.ID)
+
+	conn := wsConnectOnServer(t, srv, fmt.Sprintf("/api/games/%d/ws", g.Game.ID), token1)
+	require.NoError(t, conn.WriteJSON(ws.ClientMessage{
+		Type:     ws.TypeSubmit,
+		Code:     "wrong solution",
+		Language: "python",
+	}))
+	res := wsReadUntilType(t, conn, ws.TypeSubmissionResult)
+	require.False(t, res.Accepted)
+
+	t.Run("submitter sees their rejected attempt", func(t *testing.T) {
+		r := doOnServer(t, srv, http.MethodGet, path, nil, token1)
+		require.Equal(t, http.StatusOK, r.StatusCode)
+		var body submissionsResp
+		decodeJSON(t, r, &body)
+		require.Len(t, body.Submissions, 1)
+		s := body.Submissions[0]
+		assert.Equal(t, user1ID.String(), s.UserID)
+		assert.Equal(t, "test-problem", s.ProblemID)
+		assert.Equal(t, "WA", s.Verdict)
+		require.NotNil(t, s.FailedTest)
+		assert.Equal(t, 0, *s.FailedTest)
+		assert.Equal(t, "wrong solution", s.Code)
+	})
+
+	t.Run("opponent cannot see attempts while the game is active", func(t *testing.T) {
+		r := doOnServer(t, srv, http.MethodGet, path, nil, token2)
+		require.Equal(t, http.StatusOK, r.StatusCode)
+		var body submissionsResp
+		decodeJSON(t, r, &body)
+		assert.Empty(t, body.Submissions)
+	})
+}
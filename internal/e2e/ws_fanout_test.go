@@ -0,0 +1,78 @@
+package e2e_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"bytebattle/internal/app"
+	"bytebattle/internal/config"
+	"bytebattle/internal/service"
+	"bytebattle/internal/ws"
+)
+
+func newFanoutServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	cfg := config.Load()
+	cfg.WSBroadcaster = "postgres"
+	srv := httptest.NewServer(app.NewRouterWithExecutor(testPool, correctExecutor{}, testStore, cfg,
+		service.RateLimitConfig{Rate: rate.Inf, Burst: 1}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGameWS_FanoutAcrossInstances(t *testing.T) {
+	srvA, srvB := newFanoutServer(t), newFanoutServer(t)
+	g := createActiveGameOnServer(t, srvA)
+	wsPath := fmt.Sprintf("/api/games/%d/ws", g.Game.ID)
+
+	conn1 := wsConnectOnServer(t, srvA, wsPath, token1)
+	wsReadUntilType(t, conn1, ws.TypePlayerJoined)
+	conn2 := wsConnectOnServer(t, srvB, wsPath, token2)
+
+	joined := wsReadUntilType(t, conn1, ws.TypePlayerJoined)
+	assert.Equal(t, user2ID, joined.UserID)
+
+	require.NoError(t, conn1.WriteJSON(ws.ClientMessage{Type: ws.TypeSubmit, Code: "x", Language: "go"}))
+	finished := wsReadUntilType(t, conn2, ws.TypeGameFinished)
+	assert.Equal(t, user1ID, finished.WinnerID)
+}
+
+func TestPGBroadcaster_LargePayload(t *testing.T) {
+	b := ws.NewPGBroadcaster(testPool)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	received := make(chan ws.Envelope, 16)
+	go b.Run(ctx, func(env ws.Envelope) { received <- env })
+
+	origin := uuid.NewString()
+	big, err := json.Marshal(strings.Repeat("x", 20000))
+	require.NoError(t, err)
+
+	// Publish until the listener is up; only then is delivery guaranteed.
+	deadline := time.After(3 * time.Second)
+	for {
+		require.NoError(t, b.Publish(ctx, ws.Envelope{Origin: origin, GameID: 1, Msg: big}))
+		select {
+		case env := <-received:
+			if env.Origin != origin {
+				continue
+			}
+			assert.JSONEq(t, string(big), string(env.Msg))
+			return
+		case <-time.After(100 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("large envelope was not delivered")
+		}
+	}
+}
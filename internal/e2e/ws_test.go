@@ -41,11 +41,44 @@ func TestGameWS_InvalidParams(t *testing.T) {
 	})
 }
 
-func TestGameWS_PendingGame(t *testing.T) {
+func TestGameWS_PendingGameLobbyEvents(t *testing.T) {
 	g := createGame(t)
-	_, resp := wsDial(t, fmt.Sprintf("/api/games/%d/ws", g.Game.ID), token1)
-	require.NotNil(t, resp)
-	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	conn := wsConnect(t, fmt.Sprintf("/api/games/%d/ws", g.Game.ID), token1)
+	wsReadUntilType(t, conn, ws.TypePlayerJoined)
+
+	resp := doAuth(t, http.MethodPost, fmt.Sprintf("/api/games/%d/leave", g.Game.ID), nil, token2)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+	left := wsReadUntilType(t, conn, ws.TypeParticipantLeft)
+	assert.Equal(t, user2ID, left.UserID)
+
+	resp = doAuth(t, http.MethodPost, fmt.Sprintf("/api/games/join/%s", *g.Game.InviteToken), nil, token2)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+	joined := wsReadUntilType(t, conn, ws.TypeParticipantJoined)
+	assert.Equal(t, user2ID, joined.UserID)
+
+	resp = doAuth(t, http.MethodPost, fmt.Sprintf("/api/games/%d/start", g.Game.ID), nil, token1)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+	started := wsReadUntilType(t, conn, ws.TypeGameStarted)
+	require.NotNil(t, started.StartedAt)
+	assert.Greater(t, started.Seq, left.Seq)
+
+	// The problem is only revealed once the game has started.
+	state := wsReadUntilType(t, conn, ws.TypePlayerState)
+	assert.Equal(t, "test-problem", state.ProblemID)
+}
+
+func TestGameWS_PendingGameCancelled(t *testing.T) {
+	g := createGame(t)
+	conn := wsConnect(t, fmt.Sprintf("/api/games/%d/ws", g.Game.ID), token2)
+	wsReadUntilType(t, conn, ws.TypePlayerJoined)
+
+	resp := doAuth(t, http.MethodPost, fmt.Sprintf("/api/games/%d/cancel", g.Game.ID), nil, token1)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+	wsReadUntilTypeNotSeen(t, conn, ws.TypeGameCancelled, ws.TypePlayerState)
 }
 
 func TestGameWS_SubmitResultOnlyToSubmitter(t *testing.T) {
@@ -208,6 +241,11 @@ func TestGameWS_FailedTestIndexIsCorrect(t *testing.T) {
 	if assert.NotNil(t, result.FailedTest) {
 		assert.Equal(t, 1, *result.FailedTest)
 	}
+	assert.Equal(t, "WA", result.Verdict)
+	if assert.Len(t, result.Tests, 2) {
+		assert.Equal(t, "AC", result.Tests[0].Verdict)
+		assert.Equal(t, "WA", result.Tests[1].Verdict)
+	}
 }
 
 func TestGameWS_AcceptedSubmitFinishesGame(t *testing.T) {
@@ -302,6 +340,92 @@ func TestGameWS_ConcurrentSlotRejected(t *testing.T) {
 	assert.Contains(t, errMsg.Message, "in progress")
 }
 
+func TestGameWS_ResultDeliveredAfterReconnect(t *testing.T) {
+	exec := newBlockingExecutor()
+	srv := newGameServer(t, exec)
+
+	g := createActiveGameOnServer(t, srv)
+	wsPath := fmt.Sprintf("/api/games/%d/ws", g.Game.ID)
+	conn := wsConnectOnServer(t, srv, wsPath, token1)
+	wsReadUntilType(t, conn, ws.TypePlayerJoined)
+
+	require.NoError(t, conn.WriteJSON(ws.ClientMessage{Type: ws.TypeSubmit, Code: "x", Language: "go"}))
+	<-exec.started
+
+	// Disconnect while the submission is being judged; give the server a
+	// moment to notice before the result is ready.
+	require.NoError(t, conn.Close())
+	time.Sleep(200 * time.Millisecond)
+	close(exec.unblock)
+
+	conn = wsConnectOnServer(t, srv, wsPath, token1)
+	result := wsReadUntilType(t, conn, ws.TypeSubmissionResult)
+	assert.Equal(t, user1ID, result.UserID)
+	assert.False(t, result.Accepted)
+}
+
+func TestGameWS_ReplayMissedEvents(t *testing.T) {
+	srv := newGameServer(t, correctExecutor{})
+	g := createActiveGameOnServer(t, srv)
+	wsPath := fmt.Sprintf("/api/games/%d/ws", g.Game.ID)
+
+	conn1 := wsConnectOnServer(t, srv, wsPath, token1)
+	joined1 := wsReadUntilType(t, conn1, ws.TypePlayerJoined)
+	require.Positive(t, joined1.Seq)
+	require.NoError(t, conn1.Close())
+
+	// Player 2 joins and wins while player 1 is away.
+	conn2 := wsConnectOnServer(t, srv, wsPath, token2)
+	wsReadUntilType(t, conn2, ws.TypePlayerJoined)
+	require.NoError(t, conn2.WriteJSON(ws.ClientMessage{Type: ws.TypeSubmit, Code: "x", Language: "go"}))
+	finished := wsReadUntilType(t, conn2, ws.TypeGameFinished)
+
+	// The game is over, but player 1 can still replay what they missed.
+	conn1 = wsConnectOnServer(t, srv, fmt.Sprintf("%s?last_seq=%d", wsPath, joined1.Seq), token1)
+	joined2 := wsReadUntilType(t, conn1, ws.TypePlayerJoined)
+	assert.Equal(t, user2ID, joined2.UserID)
+	assert.Greater(t, joined2.Seq, joined1.Seq)
+	replayed := wsReadUntilType(t, conn1, ws.TypeGameFinished)
+	assert.Equal(t, finished.Seq, replayed.Seq)
+	assert.Equal(t, user2ID, replayed.WinnerID)
+}
+
+func TestGameWS_InvalidLastSeq(t *testing.T) {
+	g := createActiveGame(t)
+	_, resp := wsDial(t, fmt.Sprintf("/api/games/%d/ws?last_seq=-1", g.Game.ID), token1)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestGameWS_RunCustomInput(t *testing.T) {
+	srv := newGameServer(t, correctExecutor{})
+	g := createActiveGameOnServer(t, srv)
+	wsPath := fmt.Sprintf("/api/games/%d/ws", g.Game.ID)
+	conn1 := wsConnectOnServer(t, srv, wsPath, token1)
+	wsReadUntilType(t, conn1, ws.TypePlayerJoined)
+	conn2 := wsConnectOnServer(t, srv, wsPath, token2)
+	wsReadUntilType(t, conn2, ws.TypePlayerJoined)
+
+	input := "1 2\n"
+	require.NoError(t, conn1.WriteJSON(ws.ClientMessage{Type: ws.TypeRun, Code: "x", Language: "go", Input: &input}))
+	res := wsReadUntilType(t, conn1, ws.TypeRunResult)
+	assert.Equal(t, "3", res.Stdout)
+	assert.Empty(t, res.Verdict)
+	require.NotNil(t, res.ExitCode)
+	assert.Equal(t, 0, *res.ExitCode)
+
+	// Running on the samples is judged but does not count as a submission.
+	require.NoError(t, conn1.WriteJSON(ws.ClientMessage{Type: ws.TypeRun, Code: "x", Language: "go"}))
+	res = wsReadUntilType(t, conn1, ws.TypeRunResult)
+	assert.True(t, res.Accepted)
+	assert.Equal(t, "AC", res.Verdict)
+	assert.Len(t, res.Tests, 1)
+
+	// Results go to the player who ran the code only, and the game goes on.
+	require.NoError(t, conn2.WriteJSON(ws.ClientMessage{Type: ws.TypeSubmit, Code: "x", Language: "go"}))
+	wsReadUntilTypeNotSeen(t, conn2, ws.TypeSubmissionResult, ws.TypeRunResult)
+}
+
 func TestGameWS_TwoPlayersRace_OnlyOneWins(t *testing.T) {
 	// Use a custom server with unlimited rate to avoid cross-test token exhaustion.
 	srv := newGameServer(t, correctExecutor{})
@@ -3,13 +3,51 @@ package executor
 import (
 	"encoding/json"
 	"os"
+	"time"
+)
+
+const (
+	BackendDocker  = "docker"
+	BackendProcess = "process"
+	BackendRemote  = "remote"
 )
 
 type Config struct {
+	// Backend selects the executor implementation: BackendDocker (the
+	// default), BackendProcess or BackendRemote.
+	Backend    string                    `json:"backend,omitempty"`
 	DockerHost string                    `json:"docker_host"`
+	Process    ProcessConfig             `json:"process"`
+	Remote     RemoteConfig              `json:"remote"`
 	Languages  map[Language]LangSettings `json:"languages"`
 }
 
+// ProcessConfig configures the process backend, which runs solutions as
+// local processes instead of in containers. Images are ignored: the compile
+// and run commands of every language must be available on the host.
+type ProcessConfig struct {
+	// Sandbox is the tool the commands are wrapped in: SandboxNsjail,
+	// SandboxBwrap or SandboxNone. Empty picks the first one installed,
+	// falling back to resource limits only.
+	Sandbox string `json:"sandbox,omitempty"`
+	// SandboxArgs are passed to the sandbox before the command, e.g. a
+	// seccomp policy or cgroup limits for nsjail.
+	SandboxArgs []string `json:"sandbox_args,omitempty"`
+	// SandboxPaths are host paths mounted read-only in the sandbox besides
+	// /usr and the system libraries, e.g. a toolchain installed under /opt.
+	// Nothing else of the host is visible to the programs.
+	SandboxPaths []string `json:"sandbox_paths,omitempty"`
+	// WorkDir is where per-run directories are created; defaults to the
+	// system temp directory.
+	WorkDir string `json:"work_dir,omitempty"`
+	// MaxConcurrent bounds the number of programs compiled or run at once;
+	// defaults to the number of CPUs.
+	MaxConcurrent int `json:"max_concurrent,omitempty"`
+	// Env is added to the minimal environment commands run with, e.g.
+	// GOCACHE to share the Go build cache between runs.
+	Env []string `json:"env,omitempty"`
+}
+
 type LangSettings struct {
 	Image       string   `json:"image"`
 	SourceFile  string   `json:"source_file"`
@@ -19,6 +57,22 @@ type LangSettings struct {
 	MemoryLimit int64    `json:"memory_limit"`
 	TimeLimit   int64    `json:"time_limit"`
 	PoolSize    int      `json:"pool_size,omitempty"`
+	// TimeMultiplier and MemoryMultiplier scale per-request limits for
+	// languages with a slower runtime or a heavier VM. Zero means 1.
+	TimeMultiplier   float64 `json:"time_multiplier,omitempty"`
+	MemoryMultiplier float64 `json:"memory_multiplier,omitempty"`
+}
+
+// scaleLimits applies the language multipliers to the limits requested by the caller.
+func (s *LangSettings) scaleLimits(req ExecutionRequest) (time.Duration, int64) {
+	timeLimit, memLimit := req.TimeLimit, req.MemoryLimit
+	if s.TimeMultiplier > 0 && timeLimit > 0 {
+		timeLimit = time.Duration(float64(timeLimit) * s.TimeMultiplier)
+	}
+	if s.MemoryMultiplier > 0 && memLimit > 0 {
+		memLimit = int64(float64(memLimit) * s.MemoryMultiplier)
+	}
+	return timeLimit, memLimit
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -35,6 +89,28 @@ func LoadConfig(path string) (*Config, error) {
 	return &cfg, nil
 }
 
+// LoadConfigOrDefault overlays the settings found in path, if it exists, on
+// DefaultConfig. The language list is replaced as a whole.
+func LoadConfigOrDefault(path string) *Config {
+	cfg := DefaultConfig()
+	c, err := LoadConfig(path)
+	if err != nil {
+		return cfg
+	}
+	if c.Backend != "" {
+		cfg.Backend = c.Backend
+	}
+	if c.DockerHost != "" {
+		cfg.DockerHost = c.DockerHost
+	}
+	cfg.Process = c.Process
+	cfg.Remote = c.Remote
+	if len(c.Languages) > 0 {
+		cfg.Languages = c.Languages
+	}
+	return cfg
+}
+
 func DefaultConfig() *Config {
 	return &Config{
 		Languages: map[Language]LangSettings{
@@ -45,11 +121,14 @@ func DefaultConfig() *Config {
 				MemoryLimit: 256 * 1024 * 1024,
 				TimeLimit:   10,
 				PoolSize:    10,
+
+				TimeMultiplier: 3,
 			},
 			"go": {
 				Image:       "golang:1.26-alpine",
 				SourceFile:  "main.go",
-				RunCmd:      []string{"go", "run", "main.go"},
+				CompileCmd:  []string{"go", "build", "-o", "solution", "main.go"},
+				RunCmd:      []string{"./solution"},
 				WarmupCmd:   `printf 'package main\nimport ("fmt";"bufio";"os";"sort";"strconv";"strings";"math")\nfunc main(){fmt.Sprint();bufio.NewReader(os.Stdin);sort.Ints(nil);strconv.Itoa(0);strings.Contains("","");math.Abs(0)}\n' > /tmp/w.go && go run /tmp/w.go && rm /tmp/w.go`,
 				MemoryLimit: 512 * 1024 * 1024,
 				TimeLimit:   30,
@@ -72,6 +151,9 @@ func DefaultConfig() *Config {
 				MemoryLimit: 512 * 1024 * 1024,
 				TimeLimit:   30,
 				PoolSize:    5,
+
+				TimeMultiplier:   2,
+				MemoryMultiplier: 2,
 			},
 		},
 	}
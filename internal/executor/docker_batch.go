@@ -0,0 +1,102 @@
+package executor
+
+import (
+	"context"
+)
+
+// batchJob carries the results of a batch from the worker back to the caller
+// of RunBatch one at a time, so onResult runs on the caller's goroutine and
+// the worker can stop as soon as the caller loses interest.
+type batchJob struct {
+	req     BatchRequest
+	results chan ExecutionResult // worker -> caller
+	next    chan bool            // caller -> worker: keep going?
+}
+
+// RunBatch compiles req.Code once in a pool container and runs it against
+// every input, stopping at the first input for which onResult returns false.
+func (e *DockerExecutor) RunBatch(ctx context.Context, req BatchRequest, onResult func(i int, res ExecutionResult) bool) error {
+	job := &batchJob{
+		req:     req,
+		results: make(chan ExecutionResult),
+		next:    make(chan bool),
+	}
+	resultCh, err := e.enqueue(workItem{
+		ctx: ctx,
+		req: ExecutionRequest{
+			Code:        req.Code,
+			Language:    req.Language,
+			Args:        req.Args,
+			TimeLimit:   req.TimeLimit,
+			MemoryLimit: req.MemoryLimit,
+		},
+		batch: job,
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := 0; ; i++ {
+		select {
+		case res := <-job.results:
+			cont := onResult(i, res)
+			select {
+			case job.next <- cont:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case res := <-resultCh:
+			return res.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (e *DockerExecutor) executeBatch(ctx context.Context, containerID string, req ExecutionRequest, job *batchJob, langConfig *LangSettings) error {
+	timeLimit, memLimit := langConfig.scaleLimits(req)
+	if res, err := e.prepareProgram(ctx, containerID, req.Code, nil, memLimit, langConfig); err != nil {
+		return err
+	} else if res != nil {
+		job.deliver(ctx, *res)
+		return nil
+	}
+
+	for i, input := range job.req.Inputs {
+		files := make(map[string]string, len(job.req.filesAt(i))+1)
+		for name, content := range job.req.filesAt(i) {
+			files[name] = content
+		}
+		if input != "" {
+			files["input.txt"] = input
+		}
+		if len(files) > 0 {
+			if err := e.copyFilesToContainer(ctx, containerID, files); err != nil {
+				return err
+			}
+		}
+		res, err := e.execInContainer(ctx, containerID, langConfig, input != "", job.req.argsAt(i), timeLimit, runOptions{outputLimit: job.req.OutputLimit})
+		if err != nil {
+			return err
+		}
+		if !job.deliver(ctx, res) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// deliver hands res to the caller and waits for its decision to continue.
+func (j *batchJob) deliver(ctx context.Context, res ExecutionResult) bool {
+	select {
+	case j.results <- res:
+	case <-ctx.Done():
+		return false
+	}
+	select {
+	case cont := <-j.next:
+		return cont
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,134 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startBatchPool registers a single-worker cpp pool backed by mock.
+func startBatchPool(t *testing.T, mock *mockDockerClient) *DockerExecutor {
+	t.Helper()
+	e := newTestExecutorWithLangs(mock, map[Language]LangSettings{"cpp": {
+		SourceFile: "main.cpp",
+		CompileCmd: []string{"g++", "main.cpp"},
+		RunCmd:     []string{"./a.out"},
+	}})
+	e.initPools()
+	t.Cleanup(e.shutdown)
+	require.Eventually(t, e.IsReady, time.Second, 10*time.Millisecond)
+	return e
+}
+
+func TestRunBatch_CompilesOnceAndStopsWhenAsked(t *testing.T) {
+	var compiles, runs atomic.Int32
+	mock := &mockDockerClient{
+		containerExecCreateFn: func(_ context.Context, _ string, cfg container.ExecOptions) (container.ExecCreateResponse, error) {
+			switch {
+			case strings.Contains(cfg.Cmd[2], "g++"):
+				compiles.Add(1)
+			case strings.Contains(cfg.Cmd[2], "./a.out"):
+				runs.Add(1)
+			}
+			return container.ExecCreateResponse{ID: "exec-id"}, nil
+		},
+		containerExecAttachFn: func(_ context.Context, _ string, _ container.ExecStartOptions) (dockertypes.HijackedResponse, error) {
+			return hijackedFromString(""), nil
+		},
+	}
+	e := startBatchPool(t, mock)
+
+	var seen []int
+	err := e.RunBatch(context.Background(), BatchRequest{
+		Code:     "int main(){}",
+		Language: "cpp",
+		Inputs:   []string{"1", "2", "3", "4"},
+	}, func(i int, _ ExecutionResult) bool {
+		seen = append(seen, i)
+		return i < 2
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, seen)
+	assert.Equal(t, int32(1), compiles.Load())
+	assert.Equal(t, int32(3), runs.Load())
+}
+
+func TestRunBatch_CompileErrorReportedOnce(t *testing.T) {
+	mock := &mockDockerClient{
+		containerExecAttachFn: func(_ context.Context, _ string, _ container.ExecStartOptions) (dockertypes.HijackedResponse, error) {
+			return hijackedFromString(""), nil
+		},
+		containerExecInspectFn: func(_ context.Context, _ string) (container.ExecInspect, error) {
+			return container.ExecInspect{ExitCode: 1}, nil
+		},
+	}
+	e := startBatchPool(t, mock)
+
+	var results []ExecutionResult
+	err := e.RunBatch(context.Background(), BatchRequest{
+		Code:     "oops",
+		Language: "cpp",
+		Inputs:   []string{"1", "2"},
+	}, func(_ int, res ExecutionResult) bool {
+		results = append(results, res)
+		return true
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].CompileFailed)
+}
+
+type countingExecutor struct {
+	runs []ExecutionRequest
+	fail int // index of the run that reports a compile error, -1 for none
+}
+
+func (c *countingExecutor) Run(_ context.Context, req ExecutionRequest) (ExecutionResult, error) {
+	c.runs = append(c.runs, req)
+	return ExecutionResult{CompileFailed: len(c.runs)-1 == c.fail}, nil
+}
+
+func (c *countingExecutor) IsReady() bool { return true }
+
+func TestRunBatchHelper_FallsBackToRun(t *testing.T) {
+	exec := &countingExecutor{fail: -1}
+	var seen []int
+	err := RunBatch(context.Background(), exec, BatchRequest{
+		Code:      "print(input())",
+		Language:  "python",
+		Inputs:    []string{"a", "b", "c"},
+		Args:      []string{"x"},
+		Files:     []map[string]string{nil, {"f": "b"}},
+		TimeLimit: time.Second,
+	}, func(i int, _ ExecutionResult) bool {
+		seen = append(seen, i)
+		return true
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, seen)
+	require.Len(t, exec.runs, 3)
+	assert.Equal(t, "b", exec.runs[1].Stdin)
+	assert.Equal(t, time.Second, exec.runs[1].TimeLimit)
+	assert.Equal(t, []string{"x"}, exec.runs[1].Args)
+	assert.Equal(t, map[string]string{"f": "b"}, exec.runs[1].Files)
+	assert.Nil(t, exec.runs[2].Files)
+}
+
+func TestRunBatchHelper_StopsAfterCompileError(t *testing.T) {
+	exec := &countingExecutor{fail: 0}
+	calls := 0
+	err := RunBatch(context.Background(), exec, BatchRequest{Language: "cpp", Inputs: []string{"a", "b"}}, func(int, ExecutionResult) bool {
+		calls++
+		return true
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Len(t, exec.runs, 1)
+}
@@ -10,6 +10,7 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -19,6 +20,7 @@ import (
 	"bytebattle/internal/apierr"
 
 	cerrdefs "github.com/containerd/errdefs"
+	dockertypes "github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
@@ -35,12 +37,25 @@ const (
 	poolLabelKey         = "bytebattle"
 	poolLabelVal         = "pool"
 	poolInstanceLabelKey = "bytebattle-instance"
+
+	// minMemoryLimit is the smallest limit applied to a container; Docker
+	// rejects anything below 6MB and most runtimes fail to start much earlier.
+	minMemoryLimit   = 16 * 1024 * 1024
+	compileTimeLimit = 30 * time.Second
+
+	maxLogSize = 10 * 1024
+
+	exitCodeTimeout = 124 // timeout(1)
+	exitCodeKilled  = 137 // 128 + SIGKILL, sent by the OOM killer inside the container
 )
 
 type workItem struct {
-	ctx    context.Context
-	req    ExecutionRequest
-	result chan<- workResult // buffered(1); worker writes exactly once
+	ctx         context.Context
+	req         ExecutionRequest
+	batch       *batchJob       // nil for single runs
+	interactive *interactiveJob // nil unless running an interaction
+	opts        runOptions
+	result      chan<- workResult // buffered(1); worker writes exactly once
 }
 
 type workResult struct {
@@ -64,6 +79,9 @@ type DockerExecutor struct {
 	ready         atomic.Bool
 	shutdownCtx   context.Context
 	shutdown      context.CancelFunc
+	// interactorSlots bound the interactor containers of each language,
+	// which are created outside its pool, to the size of the pool.
+	interactorSlots map[Language]chan struct{}
 }
 
 func NewDockerExecutor(cfg *Config) (*DockerExecutor, error) {
@@ -205,6 +223,7 @@ func (e *DockerExecutor) notifyPoolPrimed(lang Language) {
 
 func (e *DockerExecutor) initPools() {
 	e.primedPerLang = make(map[Language]*atomic.Bool, len(e.config.Languages))
+	e.interactorSlots = make(map[Language]chan struct{}, len(e.config.Languages))
 	for lang, settings := range e.config.Languages {
 		size := settings.PoolSize
 		if size <= 0 {
@@ -215,6 +234,7 @@ func (e *DockerExecutor) initPools() {
 			settings: settings,
 		}
 		e.primedPerLang[lang] = new(atomic.Bool)
+		e.interactorSlots[lang] = make(chan struct{}, size)
 	}
 	// Spawn workers only after both maps are fully populated so that
 	// notifyPoolPrimed (which reads e.primedPerLang and e.pools) does not
@@ -280,9 +300,25 @@ func (e *DockerExecutor) acquireContainer(lang Language, lp *langPool, primed *b
 func (e *DockerExecutor) processNextItem(containerID string, lp *langPool) (alive, ok bool) {
 	select {
 	case item := <-lp.queue:
-		res, err := e.executeWorkItem(item.ctx, containerID, item.req, &lp.settings)
-		item.result <- workResult{res: res, err: err}
+		switch {
+		case item.batch != nil:
+			err := e.executeBatch(item.ctx, containerID, item.req, item.batch, &lp.settings)
+			item.result <- workResult{err: err}
+		case item.interactive != nil:
+			err := e.executeInteractive(item.ctx, containerID, item.req, item.interactive, &lp.settings)
+			item.result <- workResult{err: err}
+		default:
+			res, err := e.executeWorkItem(item.ctx, containerID, item.req, &lp.settings, item.opts)
+			item.result <- workResult{res: res, err: err}
+		}
 
+		if item.req.MemoryLimit > 0 {
+			// Restore the pool limit so the next request does not inherit this one.
+			if err := e.setMemoryLimit(context.Background(), containerID, baseMemoryLimit(&lp.settings)); err != nil {
+				e.sendPoolErr(fmt.Errorf("reset memory limit: %w", err))
+				return false, true
+			}
+		}
 		cleanErr := e.cleanWorkDir(context.Background(), containerID)
 		return cleanErr == nil && e.isContainerRunning(context.Background(), containerID), true
 
@@ -304,45 +340,102 @@ func (e *DockerExecutor) drainQueueWithShutdown(lp *langPool) {
 	}
 }
 
-// Run enqueues req for execution. req.MemoryLimit is ignored, pool containers use the configured LangSettings.MemoryLimit.
+// Run enqueues req for execution. req.TimeLimit and req.MemoryLimit bound the
+// run step (after scaling by the language multipliers); when unset the
+// configured LangSettings limits apply. Compilation is not subject to them.
 func (e *DockerExecutor) Run(ctx context.Context, req ExecutionRequest) (ExecutionResult, error) {
-	lp, ok := e.pools[req.Language]
+	return e.run(ctx, workItem{ctx: ctx, req: req, opts: runOptions{outputLimit: req.OutputLimit}})
+}
+
+// RunStream implements StreamExecutor. The output is streamed as the
+// container's exec stream delivers it.
+func (e *DockerExecutor) RunStream(ctx context.Context, req ExecutionRequest, onOutput func(OutputChunk)) (ExecutionResult, error) {
+	stream := newOutputStreamer(onOutput)
+	defer stream.close()
+	return e.run(ctx, workItem{ctx: ctx, req: req, opts: runOptions{stream: stream, outputLimit: req.OutputLimit}})
+}
+
+func (e *DockerExecutor) run(ctx context.Context, item workItem) (ExecutionResult, error) {
+	resultCh, err := e.enqueue(item)
+	if err != nil {
+		return ExecutionResult{}, err
+	}
+
+	select {
+	case res := <-resultCh:
+		return res.res, res.err
+	case <-ctx.Done():
+		return ExecutionResult{Error: ctx.Err()}, ctx.Err()
+	}
+}
+
+// enqueue hands item to the pool of its language and returns the channel the
+// worker will report to.
+func (e *DockerExecutor) enqueue(item workItem) (<-chan workResult, error) {
+	lp, ok := e.pools[item.req.Language]
 	if !ok {
-		return ExecutionResult{}, fmt.Errorf("unsupported language: %s", req.Language)
+		return nil, fmt.Errorf("unsupported language: %s", item.req.Language)
 	}
 
 	select {
 	case <-e.shutdownCtx.Done():
-		return ExecutionResult{}, errors.New("executor is shutting down")
+		return nil, errors.New("executor is shutting down")
 	default:
 	}
 
 	resultCh := make(chan workResult, 1)
-	item := workItem{ctx: ctx, req: req, result: resultCh}
+	item.result = resultCh
 
 	select {
 	case lp.queue <- item:
 	default:
-		return ExecutionResult{}, apierr.New(apierr.ErrExecutorOverloaded, "executor queue is full, try again later")
-	}
-
-	select {
-	case res := <-resultCh:
-		return res.res, res.err
-	case <-ctx.Done():
-		return ExecutionResult{Error: ctx.Err()}, ctx.Err()
+		return nil, apierr.New(apierr.ErrExecutorOverloaded, "executor queue is full, try again later")
 	}
+	return resultCh, nil
 }
 
-func (e *DockerExecutor) executeWorkItem(ctx context.Context, containerID string, req ExecutionRequest, langConfig *LangSettings) (ExecutionResult, error) {
-	files := map[string]string{langConfig.SourceFile: req.Code}
+func (e *DockerExecutor) executeWorkItem(ctx context.Context, containerID string, req ExecutionRequest, langConfig *LangSettings, opts runOptions) (ExecutionResult, error) {
+	files := make(map[string]string, len(req.Files)+2)
+	for name, content := range req.Files {
+		files[name] = content
+	}
 	if req.Stdin != "" {
 		files["input.txt"] = req.Stdin
 	}
+
+	timeLimit, memLimit := langConfig.scaleLimits(req)
+	if res, err := e.prepareProgram(ctx, containerID, req.Code, files, memLimit, langConfig); err != nil || res != nil {
+		return *res, err
+	}
+	return e.execInContainer(ctx, containerID, langConfig, req.Stdin != "", req.Args, timeLimit, opts)
+}
+
+// prepareProgram copies the source and files into the container, compiles the
+// program and applies the memory limit for the run. It returns a non-nil
+// result when the program cannot be run: either the compile result with
+// CompileFailed set or the result accompanying err.
+func (e *DockerExecutor) prepareProgram(ctx context.Context, containerID, code string, files map[string]string, memLimit int64, langConfig *LangSettings) (*ExecutionResult, error) {
+	if files == nil {
+		files = make(map[string]string, 1)
+	}
+	files[langConfig.SourceFile] = code
 	if err := e.copyFilesToContainer(ctx, containerID, files); err != nil {
-		return ExecutionResult{Error: err}, fmt.Errorf("failed to copy files: %w", err)
+		return &ExecutionResult{Error: err}, fmt.Errorf("failed to copy files: %w", err)
+	}
+
+	if len(langConfig.CompileCmd) > 0 {
+		res, err := e.compileInContainer(ctx, containerID, langConfig)
+		if err != nil || res.CompileFailed {
+			return &res, err
+		}
+	}
+
+	if memLimit > 0 {
+		if err := e.setMemoryLimit(ctx, containerID, memLimit); err != nil {
+			return &ExecutionResult{Error: err}, fmt.Errorf("failed to set memory limit: %w", err)
+		}
 	}
-	return e.execInContainer(ctx, containerID, langConfig, req.Stdin != "", req.TimeLimit)
+	return nil, nil
 }
 
 // cleanWorkDir removes user-written files from /app and /tmp so the container
@@ -385,18 +478,15 @@ func (e *DockerExecutor) cleanupContainer(ctx context.Context, id string) {
 }
 
 func (e *DockerExecutor) createWarmContainer(ctx context.Context, langConfig *LangSettings) (string, error) {
-	memLimit := langConfig.MemoryLimit
-	if memLimit == 0 {
-		memLimit = defaultMemoryLimit
-	}
-
+	memLimit := baseMemoryLimit(langConfig)
 	pidsLimitPtr := pidsLimit
 
 	hostConfig := &container.HostConfig{
 		Resources: container.Resources{
-			Memory:    memLimit,
-			NanoCPUs:  nanoCPUs,
-			PidsLimit: &pidsLimitPtr,
+			Memory:     memLimit,
+			MemorySwap: memLimit,
+			NanoCPUs:   nanoCPUs,
+			PidsLimit:  &pidsLimitPtr,
 		},
 		NetworkMode: "none",
 		CapDrop:     []string{"ALL"},
@@ -475,42 +565,96 @@ func (e *DockerExecutor) runWarmup(ctx context.Context, containerID, cmd string)
 	return nil
 }
 
-func (e *DockerExecutor) execInContainer(ctx context.Context, containerID string, langConfig *LangSettings, hasStdin bool, timeLimit time.Duration) (ExecutionResult, error) {
-	limit := timeLimit
-	if limit == 0 && langConfig.TimeLimit > 0 {
-		limit = time.Duration(langConfig.TimeLimit) * time.Second
+// compileInContainer runs the language's compile step. A non-zero exit,
+// including hitting compileTimeLimit, is reported as CompileFailed.
+func (e *DockerExecutor) compileInContainer(ctx context.Context, containerID string, langConfig *LangSettings) (ExecutionResult, error) {
+	cmd := fmt.Sprintf("timeout %s %s", formatTimeout(compileTimeLimit), strings.Join(langConfig.CompileCmd, " "))
+	out, err := e.runShell(ctx, containerID, cmd, compileTimeLimit, nil)
+	if err != nil {
+		return ExecutionResult{Error: err}, err
 	}
-	if limit == 0 {
-		limit = 5 * time.Second
+	res := ExecutionResult{
+		Stdout:        truncateString(out.stdout, maxLogSize),
+		Stderr:        truncateString(out.stderr, maxLogSize),
+		ExitCode:      out.exitCode,
+		TimeUsed:      out.elapsed,
+		CompileFailed: out.safetyFired || out.exitCode != 0,
 	}
-
-	cmd := e.buildShellCommand(langConfig, hasStdin, limit)
-	execConfig := container.ExecOptions{
-		Cmd:          []string{"/bin/sh", "-c", cmd},
-		AttachStdout: true,
-		AttachStderr: true,
+	if out.safetyFired || out.exitCode == exitCodeTimeout {
+		res.Stderr += "\nCompilation timed out."
 	}
-	execResp, err := e.cli.ContainerExecCreate(ctx, containerID, execConfig)
+	return res, nil
+}
+
+func (e *DockerExecutor) execInContainer(ctx context.Context, containerID string, langConfig *LangSettings, hasStdin bool, args []string, timeLimit time.Duration, opts runOptions) (ExecutionResult, error) {
+	limit, _ := runLimits(langConfig, timeLimit, 0)
+	cmd := withRunStats(e.buildShellCommand(langConfig, hasStdin, args, limit))
+	out, err := e.runShell(ctx, containerID, cmd, limit, opts.stream)
 	if err != nil {
-		return ExecutionResult{Error: err}, fmt.Errorf("failed to create exec: %w", err)
+		return ExecutionResult{Error: err}, err
 	}
-	attachResp, err := e.cli.ContainerExecAttach(ctx, execResp.ID, container.ExecStartOptions{})
+	return runResult(out, opts), nil
+}
+
+// runResult builds the result of a run whose command was wrapped by
+// withRunStats.
+func runResult(out shellResult, opts runOptions) ExecutionResult {
+	stats, found := parseRunStats(&out.stderr)
+	timedOut := out.safetyFired || out.exitCode == exitCodeTimeout
+	oomKilled := !timedOut && out.exitCode == exitCodeKilled
+	if found && stats.oomKnown {
+		oomKilled = stats.oomKills > 0
+	}
+	res := ExecutionResult{
+		Stdout:     truncateString(out.stdout, opts.stdoutLimit()),
+		Stderr:     truncateString(out.stderr, maxLogSize),
+		ExitCode:   out.exitCode,
+		TimeUsed:   out.elapsed,
+		CPUTime:    stats.cpuTime,
+		MemoryUsed: stats.maxRSSKb * 1024,
+		TimedOut:   timedOut,
+		OOMKilled:  oomKilled,
+	}
+	if timedOut {
+		res.ExitCode = exitCodeTimeout
+		res.Stderr += "\nExecution timed out."
+	}
+	return res
+}
+
+type shellResult struct {
+	stdout, stderr string
+	exitCode       int
+	elapsed        time.Duration
+	safetyFired    bool
+}
+
+// runShell runs cmd in the container and collects its full output. cmd is expected
+// to enforce limit itself (via timeout(1)), so the process will self-terminate
+// and close the output stream when the limit is reached. The Go-level timer is
+// a safety net for cases where timeout(1) is unavailable or the container is
+// unresponsive. When stream is not nil the output is also streamed as it
+// arrives; cmd is then expected to be wrapped by withRunStats, whose trailer
+// is kept out of the stream.
+func (e *DockerExecutor) runShell(ctx context.Context, containerID, cmd string, limit time.Duration, stream *outputStreamer) (shellResult, error) {
+	execID, attachResp, err := e.startExec(ctx, containerID, cmd, false)
 	if err != nil {
-		return ExecutionResult{Error: err}, fmt.Errorf("failed to attach exec: %w", err)
+		return shellResult{}, err
 	}
 	defer attachResp.Close()
 
 	stdoutBuf, stderrBuf := &bytes.Buffer{}, &bytes.Buffer{}
+	stdout, stderr := io.Writer(stdoutBuf), io.Writer(stderrBuf)
+	if stream != nil {
+		stdout = stream.writer(StreamStdout, stdoutBuf)
+		stderr = io.MultiWriter(stderrBuf, &statsTrailerFilter{w: stream.sink(StreamStderr)})
+	}
 	outputDone := make(chan error, 1)
 	go func() {
-		_, err := stdcopy.StdCopy(stdoutBuf, stderrBuf, attachResp.Reader)
+		_, err := stdcopy.StdCopy(stdout, stderr, attachResp.Reader)
 		outputDone <- err
 	}()
 
-	// The run command is already wrapped with timeout(1), so the process will
-	// self-terminate and close the output stream when the limit is reached.
-	// The Go-level timer is a safety net for cases where timeout(1) is
-	// unavailable or the container is unresponsive.
 	safetyNet := limit + 30*time.Second
 	startTime := time.Now()
 	var safetyFired bool
@@ -518,56 +662,84 @@ func (e *DockerExecutor) execInContainer(ctx context.Context, containerID string
 	select {
 	case err := <-outputDone:
 		if err != nil {
-			return ExecutionResult{Error: err}, fmt.Errorf("error reading output: %w", err)
+			return shellResult{}, fmt.Errorf("error reading output: %w", err)
 		}
 	case <-time.After(safetyNet):
 		safetyFired = true
 	case <-ctx.Done():
-		return ExecutionResult{Error: ctx.Err()}, ctx.Err()
+		return shellResult{}, ctx.Err()
 	}
+	elapsed := time.Since(startTime)
 
 	exitCode := 0
-	if inspect, err := e.cli.ContainerExecInspect(ctx, execResp.ID); err == nil {
+	if inspect, err := e.cli.ContainerExecInspect(ctx, execID); err == nil {
 		exitCode = inspect.ExitCode
 	}
 
-	timedOut := safetyFired || exitCode == 124
+	return shellResult{
+		stdout:      stdoutBuf.String(),
+		stderr:      stderrBuf.String(),
+		exitCode:    exitCode,
+		elapsed:     elapsed,
+		safetyFired: safetyFired,
+	}, nil
+}
 
-	const maxLogSize = 10 * 1024
-	res := ExecutionResult{
-		Stdout:   truncateString(stdoutBuf.String(), maxLogSize),
-		Stderr:   truncateString(stderrBuf.String(), maxLogSize),
-		ExitCode: exitCode,
-		TimeUsed: time.Since(startTime),
+// startExec starts cmd in the container with its output attached and, when
+// stdin is set, its input as well.
+func (e *DockerExecutor) startExec(ctx context.Context, containerID, cmd string, stdin bool) (string, dockertypes.HijackedResponse, error) {
+	execConfig := container.ExecOptions{
+		Cmd:          []string{"/bin/sh", "-c", cmd},
+		AttachStdin:  stdin,
+		AttachStdout: true,
+		AttachStderr: true,
 	}
-	if timedOut {
-		res.ExitCode = 124
-		res.Stderr += "\nExecution timed out."
+	execResp, err := e.cli.ContainerExecCreate(ctx, containerID, execConfig)
+	if err != nil {
+		return "", dockertypes.HijackedResponse{}, fmt.Errorf("failed to create exec: %w", err)
 	}
-	return res, nil
-}
-
-func (e *DockerExecutor) buildShellCommand(cfg *LangSettings, hasStdin bool, timeLimit time.Duration) string {
-	var parts []string
-	if len(cfg.CompileCmd) > 0 {
-		parts = append(parts, strings.Join(cfg.CompileCmd, " "))
+	attachResp, err := e.cli.ContainerExecAttach(ctx, execResp.ID, container.ExecStartOptions{})
+	if err != nil {
+		return "", dockertypes.HijackedResponse{}, fmt.Errorf("failed to attach exec: %w", err)
 	}
+	return execResp.ID, attachResp, nil
+}
 
+func (e *DockerExecutor) buildShellCommand(cfg *LangSettings, hasStdin bool, args []string, timeLimit time.Duration) string {
 	runCmd := strings.Join(cfg.RunCmd, " ")
+	for _, arg := range args {
+		runCmd += " " + shellQuote(arg)
+	}
 	if hasStdin {
 		runCmd += " < input.txt"
 	}
-
-	secs := int(timeLimit.Seconds())
-	if secs > 0 {
-		runCmd = fmt.Sprintf("timeout %ds %s", secs, runCmd)
+	if timeLimit > 0 {
+		runCmd = fmt.Sprintf("timeout %s %s", formatTimeout(timeLimit), runCmd)
 	}
+	return runCmd
+}
+
+// formatTimeout renders d as a timeout(1) duration, keeping sub-second
+// precision so a 1500ms limit is not rounded down to 1s.
+func formatTimeout(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
 
-	if len(parts) > 0 {
-		parts = append(parts, runCmd)
-		return strings.Join(parts, " && ")
+// setMemoryLimit changes the memory limit of a pool container. Swap is capped
+// at the same value so the limit cannot be sidestepped by swapping.
+func (e *DockerExecutor) setMemoryLimit(ctx context.Context, containerID string, limit int64) error {
+	limit = max(limit, minMemoryLimit)
+	_, err := e.cli.ContainerUpdate(ctx, containerID, container.UpdateConfig{
+		Resources: container.Resources{Memory: limit, MemorySwap: limit},
+	})
+	return err
+}
+
+func baseMemoryLimit(langConfig *LangSettings) int64 {
+	if langConfig.MemoryLimit == 0 {
+		return defaultMemoryLimit
 	}
-	return runCmd
+	return langConfig.MemoryLimit
 }
 
 func (e *DockerExecutor) isContainerRunning(ctx context.Context, id string) bool {
@@ -610,6 +782,11 @@ func (e *DockerExecutor) sendPoolErr(err error) {
 	}
 }
 
+// shellQuote wraps s in single quotes so it is passed to the program verbatim.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
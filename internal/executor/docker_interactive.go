@@ -0,0 +1,206 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// interactiveJob is the batchJob of an interactive run.
+type interactiveJob struct {
+	interactor Interactor
+	tests      []map[string]string
+	results    chan InteractiveResult // worker -> caller
+	next       chan bool              // caller -> worker: keep going?
+}
+
+// RunInteractive implements InteractiveExecutor. The program runs in a pool
+// container of its language. The interactor gets a container of its own for
+// the whole run rather than one from its pool, so that two interactive runs
+// can never wait on each other's pools; at most as many of them as the pool
+// has containers run at once.
+func (e *DockerExecutor) RunInteractive(ctx context.Context, req InteractiveRequest, onResult func(i int, res InteractiveResult) bool) error {
+	if _, ok := e.config.Languages[req.Interactor.Language]; !ok {
+		return fmt.Errorf("unsupported interactor language: %s", req.Interactor.Language)
+	}
+	if len(req.Tests) == 0 {
+		return nil
+	}
+	job := &interactiveJob{
+		interactor: req.Interactor,
+		tests:      req.Tests,
+		results:    make(chan InteractiveResult),
+		next:       make(chan bool),
+	}
+	resultCh, err := e.enqueue(workItem{
+		ctx: ctx,
+		req: ExecutionRequest{
+			Code:        req.Code,
+			Language:    req.Language,
+			TimeLimit:   req.TimeLimit,
+			MemoryLimit: req.MemoryLimit,
+		},
+		interactive: job,
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := 0; ; i++ {
+		select {
+		case res := <-job.results:
+			cont := onResult(i, res)
+			select {
+			case job.next <- cont:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case res := <-resultCh:
+			return res.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (e *DockerExecutor) executeInteractive(ctx context.Context, containerID string, req ExecutionRequest, job *interactiveJob, langConfig *LangSettings) error {
+	interactorConfig := e.config.Languages[job.interactor.Language]
+	slots := e.interactorSlots[job.interactor.Language]
+	select {
+	case slots <- struct{}{}:
+		defer func() { <-slots }()
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-e.shutdownCtx.Done():
+		return errors.New("executor is shutting down")
+	}
+	icontainerID, err := e.createWarmContainer(ctx, &interactorConfig)
+	if err != nil {
+		return fmt.Errorf("create interactor container: %w", err)
+	}
+	defer e.cleanupContainer(context.Background(), icontainerID)
+
+	itimeLimit, imemLimit := interactorConfig.scaleLimits(ExecutionRequest{
+		TimeLimit:   job.interactor.TimeLimit,
+		MemoryLimit: job.interactor.MemoryLimit,
+	})
+	if res, err := e.prepareProgram(ctx, icontainerID, job.interactor.Code, nil, imemLimit, &interactorConfig); err != nil {
+		return err
+	} else if res != nil {
+		job.deliver(ctx, InteractiveResult{Interactor: *res})
+		return nil
+	}
+	timeLimit, memLimit := langConfig.scaleLimits(req)
+	if res, err := e.prepareProgram(ctx, containerID, req.Code, nil, memLimit, langConfig); err != nil {
+		return err
+	} else if res != nil {
+		job.deliver(ctx, InteractiveResult{Program: *res})
+		return nil
+	}
+
+	for _, files := range job.tests {
+		if err := e.copyFilesToContainer(ctx, icontainerID, files); err != nil {
+			return err
+		}
+		res, err := e.runInteraction(ctx, containerID, langConfig, timeLimit, icontainerID, &interactorConfig, itimeLimit, job.interactor.Args)
+		if err != nil {
+			return err
+		}
+		if !job.deliver(ctx, res) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// runInteraction runs the compiled program against the interactor, relaying
+// each side's output to the other's input through the exec streams.
+func (e *DockerExecutor) runInteraction(ctx context.Context, containerID string, langConfig *LangSettings, timeLimit time.Duration, icontainerID string, interactorConfig *LangSettings, itimeLimit time.Duration, args []string) (InteractiveResult, error) {
+	limit, _ := runLimits(langConfig, timeLimit, 0)
+	ilimit, _ := runLimits(interactorConfig, itimeLimit, 0)
+
+	iexecID, iresp, err := e.startExec(ctx, icontainerID, withRunStats(e.buildShellCommand(interactorConfig, false, args, ilimit)), true)
+	if err != nil {
+		return InteractiveResult{}, err
+	}
+	defer iresp.Close()
+	execID, resp, err := e.startExec(ctx, containerID, withRunStats(e.buildShellCommand(langConfig, false, nil, limit)), true)
+	if err != nil {
+		return InteractiveResult{}, err
+	}
+	defer resp.Close()
+
+	transcript := &cappedBuffer{max: maxLogSize + 1}
+	stderr, istderr := &bytes.Buffer{}, &bytes.Buffer{}
+	programDone := make(chan error, 1)
+	go func() {
+		_, err := stdcopy.StdCopy(io.MultiWriter(transcript, &interactorOutput{w: iresp.Conn}), stderr, resp.Reader)
+		_ = iresp.CloseWrite()
+		programDone <- err
+	}()
+	interactorDone := make(chan error, 1)
+	go func() {
+		_, err := stdcopy.StdCopy(&interactorOutput{w: resp.Conn}, istderr, iresp.Reader)
+		_ = resp.CloseWrite()
+		interactorDone <- err
+	}()
+
+	start := time.Now()
+	safetyNet := time.After(max(limit, ilimit) + 30*time.Second)
+	var out, iout shellResult
+	programRunning, interactorRunning := true, true
+	for programRunning || interactorRunning {
+		select {
+		case err := <-programDone:
+			if err != nil && !out.safetyFired {
+				return InteractiveResult{}, fmt.Errorf("error reading output: %w", err)
+			}
+			out.elapsed, programRunning = time.Since(start), false
+		case err := <-interactorDone:
+			if err != nil && !iout.safetyFired {
+				return InteractiveResult{}, fmt.Errorf("error reading interactor output: %w", err)
+			}
+			iout.elapsed, interactorRunning = time.Since(start), false
+		case <-safetyNet:
+			// Whichever side is still running is reported as timed out;
+			// closing the streams ends the copies feeding the buffers.
+			out.safetyFired, iout.safetyFired = programRunning, interactorRunning
+			resp.Close()
+			iresp.Close()
+		case <-ctx.Done():
+			return InteractiveResult{}, ctx.Err()
+		}
+	}
+
+	if inspect, err := e.cli.ContainerExecInspect(ctx, execID); err == nil {
+		out.exitCode = inspect.ExitCode
+	}
+	if inspect, err := e.cli.ContainerExecInspect(ctx, iexecID); err == nil {
+		iout.exitCode = inspect.ExitCode
+	}
+	out.stdout, out.stderr, iout.stderr = transcript.String(), stderr.String(), istderr.String()
+	return InteractiveResult{
+		Program:    runResult(out, runOptions{}),
+		Interactor: runResult(iout, runOptions{}),
+	}, nil
+}
+
+// deliver hands res to the caller and waits for its decision to continue.
+func (j *interactiveJob) deliver(ctx context.Context, res InteractiveResult) bool {
+	select {
+	case j.results <- res:
+	case <-ctx.Done():
+		return false
+	}
+	select {
+	case cont := <-j.next:
+		return cont
+	case <-ctx.Done():
+		return false
+	}
+}
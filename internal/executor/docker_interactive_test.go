@@ -0,0 +1,134 @@
+package executor
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hijackedPeer returns a HijackedResponse whose other end is served by
+// serve, which reads the exec's stdin and writes its stdout.
+func hijackedPeer(serve func(stdin *bufio.Reader, stdout io.Writer)) dockertypes.HijackedResponse {
+	server, client := net.Pipe()
+	go func() {
+		defer server.Close()
+		serve(bufio.NewReader(server), stdcopy.NewStdWriter(server, stdcopy.Stdout))
+	}()
+	return dockertypes.HijackedResponse{
+		Conn:   client,
+		Reader: bufio.NewReader(client),
+	}
+}
+
+func TestRunInteractive_RelaysBothWays(t *testing.T) {
+	var received atomic.Value
+	mock := &mockDockerClient{
+		containerExecCreateFn: func(_ context.Context, _ string, cfg container.ExecOptions) (container.ExecCreateResponse, error) {
+			switch {
+			case strings.Contains(cfg.Cmd[2], "interactor.py"):
+				return container.ExecCreateResponse{ID: "interactor"}, nil
+			case strings.Contains(cfg.Cmd[2], "./a.out"):
+				return container.ExecCreateResponse{ID: "program"}, nil
+			}
+			return container.ExecCreateResponse{ID: "exec-id"}, nil
+		},
+		containerExecAttachFn: func(_ context.Context, execID string, _ container.ExecStartOptions) (dockertypes.HijackedResponse, error) {
+			switch execID {
+			case "interactor":
+				return hijackedPeer(func(stdin *bufio.Reader, stdout io.Writer) {
+					_, _ = io.WriteString(stdout, "5\n")
+					line, _ := stdin.ReadString('\n')
+					received.Store(line)
+				}), nil
+			case "program":
+				return hijackedPeer(func(stdin *bufio.Reader, stdout io.Writer) {
+					if line, _ := stdin.ReadString('\n'); line == "5\n" {
+						_, _ = io.WriteString(stdout, "10\n")
+					}
+				}), nil
+			}
+			return hijackedFromString(""), nil
+		},
+	}
+	e := newTestExecutorWithLangs(mock, map[Language]LangSettings{
+		"cpp": {
+			SourceFile: "main.cpp",
+			CompileCmd: []string{"g++", "main.cpp"},
+			RunCmd:     []string{"./a.out"},
+		},
+		"python": {
+			SourceFile: "interactor.py",
+			RunCmd:     []string{"python3", "interactor.py"},
+		},
+	})
+	e.initPools()
+	t.Cleanup(e.shutdown)
+	require.Eventually(t, e.IsReady, time.Second, 10*time.Millisecond)
+
+	var results []InteractiveResult
+	err := e.RunInteractive(context.Background(), InteractiveRequest{
+		Code:       "int main(){}",
+		Language:   "cpp",
+		Interactor: Interactor{Code: "print(5)", Language: "python"},
+		Tests:      []map[string]string{{"input.txt": "5"}},
+	}, func(_ int, res InteractiveResult) bool {
+		results = append(results, res)
+		return true
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "10\n", results[0].Program.Stdout)
+	assert.Equal(t, 0, results[0].Interactor.ExitCode)
+	assert.Equal(t, "10\n", received.Load())
+}
+
+func TestRunInteractive_WaitsForInteractorSlot(t *testing.T) {
+	var interactorExecs atomic.Int32
+	mock := &mockDockerClient{
+		containerExecCreateFn: func(_ context.Context, _ string, cfg container.ExecOptions) (container.ExecCreateResponse, error) {
+			if strings.Contains(cfg.Cmd[2], "interactor.py") {
+				interactorExecs.Add(1)
+			}
+			return container.ExecCreateResponse{ID: "exec-id"}, nil
+		},
+		containerExecAttachFn: func(_ context.Context, _ string, _ container.ExecStartOptions) (dockertypes.HijackedResponse, error) {
+			return hijackedFromString(""), nil
+		},
+	}
+	e := newTestExecutorWithLangs(mock, map[Language]LangSettings{
+		"cpp": {SourceFile: "main.cpp", RunCmd: []string{"./a.out"}, PoolSize: 1},
+		"python": {
+			SourceFile: "interactor.py",
+			RunCmd:     []string{"python3", "interactor.py"},
+			PoolSize:   1,
+		},
+	})
+	e.initPools()
+	t.Cleanup(e.shutdown)
+	require.Eventually(t, e.IsReady, time.Second, 10*time.Millisecond)
+
+	// Another interaction holds the only python interactor slot.
+	e.interactorSlots["python"] <- struct{}{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := e.RunInteractive(ctx, InteractiveRequest{
+		Code:       "int main(){}",
+		Language:   "cpp",
+		Interactor: Interactor{Code: "print(5)", Language: "python"},
+		Tests:      []map[string]string{{"input.txt": "5"}},
+	}, func(int, InteractiveResult) bool { return true })
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Zero(t, interactorExecs.Load(), "no interactor runs without a slot")
+}
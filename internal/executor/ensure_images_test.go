@@ -31,6 +31,7 @@ type mockDockerClient struct {
 	containerExecAttachFn  func(ctx context.Context, execID string, config container.ExecStartOptions) (dockertypes.HijackedResponse, error)
 	containerExecInspectFn func(ctx context.Context, execID string) (container.ExecInspect, error)
 	containerInspectFn     func(ctx context.Context, containerID string) (container.InspectResponse, error)
+	containerUpdateFn      func(ctx context.Context, containerID string, cfg container.UpdateConfig) (container.UpdateResponse, error)
 }
 
 func (m *mockDockerClient) ImageInspect(ctx context.Context, imageID string, opts ...client.ImageInspectOption) (image.InspectResponse, error) {
@@ -98,6 +99,13 @@ func (m *mockDockerClient) CopyToContainer(_ context.Context, _, _ string, _ io.
 	return nil
 }
 
+func (m *mockDockerClient) ContainerUpdate(ctx context.Context, containerID string, cfg container.UpdateConfig) (container.UpdateResponse, error) {
+	if m.containerUpdateFn != nil {
+		return m.containerUpdateFn(ctx, containerID, cfg)
+	}
+	return container.UpdateResponse{}, nil
+}
+
 func newTestExecutorWithLangs(mock dockerClient, langs map[Language]LangSettings) *DockerExecutor {
 	primedPerLang := make(map[Language]*atomic.Bool, len(langs))
 	for lang := range langs {
@@ -2,6 +2,7 @@ package executor
 
 import (
 	"context"
+	"fmt"
 	"time"
 )
 
@@ -13,18 +14,140 @@ type ExecutionRequest struct {
 	Stdin       string
 	TimeLimit   time.Duration
 	MemoryLimit int64
+	// Args are appended to the language's run command.
+	Args []string
+	// Files are written to the working directory next to the source file.
+	Files map[string]string
+	// OutputLimit is how many bytes of stdout the result keeps, for
+	// programs such as test generators whose output is data rather than a
+	// log. DefaultOutputLimit applies when it is 0; stderr is always capped
+	// at DefaultOutputLimit.
+	OutputLimit int
+}
+
+// DefaultOutputLimit is how much of a program's output a result keeps;
+// longer output is truncated.
+const DefaultOutputLimit = maxLogSize
+
+// runOptions are the parts of a request that concern a run's output.
+type runOptions struct {
+	stream      *outputStreamer
+	outputLimit int
+}
+
+func (o runOptions) stdoutLimit() int {
+	if o.outputLimit > 0 {
+		return o.outputLimit
+	}
+	return maxLogSize
 }
 
 type ExecutionResult struct {
-	Stdout     string
-	Stderr     string
-	ExitCode   int
-	TimeUsed   time.Duration
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	// TimeUsed is the wall-clock time of the run, excluding compilation.
+	TimeUsed time.Duration
+	// CPUTime is the user+system CPU time of the run, or 0 when the executor
+	// cannot measure it.
+	CPUTime time.Duration
+	// MemoryUsed is the peak resident memory of the run in bytes, or 0 when
+	// the executor cannot measure it.
 	MemoryUsed int64
-	Error      error
+	// TimedOut is set when the run was killed for exceeding its time limit.
+	TimedOut bool
+	// OOMKilled is set when the run was killed for exceeding its memory limit.
+	OOMKilled bool
+	// CompileFailed is set when the compile step failed and the program never ran.
+	CompileFailed bool
+	Error         error
 }
 
 type Executor interface {
 	Run(ctx context.Context, req ExecutionRequest) (ExecutionResult, error)
 	IsReady() bool
 }
+
+// New creates the executor selected by cfg.Backend.
+func New(cfg *Config) (Executor, error) {
+	switch cfg.Backend {
+	case "", BackendDocker:
+		return NewDockerExecutor(cfg)
+	case BackendProcess:
+		return NewProcessExecutor(cfg)
+	case BackendRemote:
+		return NewRemoteExecutor(cfg.Remote)
+	default:
+		return nil, fmt.Errorf("unknown executor backend %q", cfg.Backend)
+	}
+}
+
+// BatchRequest runs one program against several inputs. Limits apply to each
+// run separately.
+type BatchRequest struct {
+	Code     string
+	Language Language
+	Inputs   []string
+	// Args are passed to the program on every run, followed by the
+	// InputArgs of the input, if set.
+	Args      []string
+	InputArgs [][]string
+	// Files, if set, holds the files written to the working directory
+	// before the run on the input of the same index.
+	Files       []map[string]string
+	TimeLimit   time.Duration
+	MemoryLimit int64
+	// OutputLimit applies to the stdout of every run as in ExecutionRequest.
+	OutputLimit int
+}
+
+// argsAt returns the arguments of the run on Inputs[i].
+func (r *BatchRequest) argsAt(i int) []string {
+	if i < len(r.InputArgs) {
+		return append(append([]string{}, r.Args...), r.InputArgs[i]...)
+	}
+	return r.Args
+}
+
+// filesAt returns the files of the run on Inputs[i].
+func (r *BatchRequest) filesAt(i int) map[string]string {
+	if i < len(r.Files) {
+		return r.Files[i]
+	}
+	return nil
+}
+
+// BatchExecutor is implemented by executors that can compile a program once
+// and run it against many inputs. onResult is called in input order on the
+// caller's goroutine; returning false stops the batch. A compile error is
+// reported as the result of the first input with CompileFailed set.
+type BatchExecutor interface {
+	RunBatch(ctx context.Context, req BatchRequest, onResult func(i int, res ExecutionResult) bool) error
+}
+
+// RunBatch uses exec's BatchExecutor implementation when available and
+// otherwise falls back to one Run per input.
+func RunBatch(ctx context.Context, exec Executor, req BatchRequest, onResult func(i int, res ExecutionResult) bool) error {
+	if b, ok := exec.(BatchExecutor); ok {
+		return b.RunBatch(ctx, req, onResult)
+	}
+	for i, input := range req.Inputs {
+		res, err := exec.Run(ctx, ExecutionRequest{
+			Code:        req.Code,
+			Language:    req.Language,
+			Stdin:       input,
+			Args:        req.argsAt(i),
+			Files:       req.filesAt(i),
+			TimeLimit:   req.TimeLimit,
+			MemoryLimit: req.MemoryLimit,
+			OutputLimit: req.OutputLimit,
+		})
+		if err != nil {
+			return err
+		}
+		if !onResult(i, res) || res.CompileFailed {
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// InteractiveRequest runs a program against an interactor, a second program
+// it talks to while it runs: the interactor's stdout is the program's stdin
+// and the program's stdout is the interactor's stdin. Both are compiled once
+// and run together once per test. TimeLimit and MemoryLimit bound each run
+// of the program as in a BatchRequest; the interactor has limits of its own.
+type InteractiveRequest struct {
+	Code        string
+	Language    Language
+	TimeLimit   time.Duration
+	MemoryLimit int64
+	Interactor  Interactor
+	// Tests holds the files written to the interactor's working directory
+	// before each run, e.g. the test it is to play.
+	Tests []map[string]string
+}
+
+// Interactor is the judging side of an interactive run. Args are appended to
+// its run command on every test.
+type Interactor struct {
+	Code        string
+	Language    Language
+	Args        []string
+	TimeLimit   time.Duration
+	MemoryLimit int64
+}
+
+// InteractiveResult is the outcome of one run of an interaction.
+// Program.Stdout is what the program sent to the interactor.
+type InteractiveResult struct {
+	Program    ExecutionResult
+	Interactor ExecutionResult
+}
+
+// InteractiveExecutor is implemented by executors that can run interactive
+// problems. onResult is called in test order on the caller's goroutine;
+// returning false stops the run. A compile error of either program is
+// reported as the result of the first test with CompileFailed set on that
+// side; the interactor is compiled first.
+type InteractiveExecutor interface {
+	RunInteractive(ctx context.Context, req InteractiveRequest, onResult func(i int, res InteractiveResult) bool) error
+}
+
+var ErrInteractiveUnsupported = errors.New("executor does not support interactive problems")
+
+// RunInteractive uses exec's InteractiveExecutor implementation. There is no
+// fallback: an interaction cannot be replayed with Run.
+func RunInteractive(ctx context.Context, exec Executor, req InteractiveRequest, onResult func(i int, res InteractiveResult) bool) error {
+	if ie, ok := exec.(InteractiveExecutor); ok {
+		return ie.RunInteractive(ctx, req, onResult)
+	}
+	return ErrInteractiveUnsupported
+}
+
+// interactorOutput passes data on to the other side of an interaction. Once
+// that side is gone writes are dropped instead of failing, so that the copy
+// feeding it keeps draining its source and never blocks the writer.
+type interactorOutput struct {
+	w    io.Writer
+	gone bool
+}
+
+func (o *interactorOutput) Write(p []byte) (int, error) {
+	if !o.gone {
+		if _, err := o.w.Write(p); err != nil {
+			o.gone = true
+		}
+	}
+	return len(p), nil
+}
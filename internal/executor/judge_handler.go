@@ -0,0 +1,389 @@
+package executor
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"bytebattle/internal/apierr"
+)
+
+// The judge protocol is JSON over HTTP:
+//
+//	POST /run     wireRequest      -> wireResult
+//	POST /batch   wireBatchRequest -> newline-delimited wireBatchLine
+//	POST /stream  wireRequest      -> newline-delimited wireStreamLine
+//	POST /interactive wireInteractiveRequest -> newline-delimited wireInteractiveLine
+//	GET  /health  200 when the executor is ready, 503 otherwise
+//
+// Failures are reported as an apierr.AppError body with a non-2xx status.
+// Batch results and streamed output are sent as they are produced; the
+// client stops a batch or a run by closing the connection.
+
+type wireRequest struct {
+	Code        string            `json:"code"`
+	Language    Language          `json:"language"`
+	Stdin       string            `json:"stdin,omitempty"`
+	TimeLimit   time.Duration     `json:"time_limit,omitempty"`
+	MemoryLimit int64             `json:"memory_limit,omitempty"`
+	Args        []string          `json:"args,omitempty"`
+	Files       map[string]string `json:"files,omitempty"`
+	OutputLimit int               `json:"output_limit,omitempty"`
+}
+
+type wireBatchRequest struct {
+	Code        string              `json:"code"`
+	Language    Language            `json:"language"`
+	Inputs      []string            `json:"inputs"`
+	Args        []string            `json:"args,omitempty"`
+	InputArgs   [][]string          `json:"input_args,omitempty"`
+	Files       []map[string]string `json:"files,omitempty"`
+	TimeLimit   time.Duration       `json:"time_limit,omitempty"`
+	MemoryLimit int64               `json:"memory_limit,omitempty"`
+	OutputLimit int                 `json:"output_limit,omitempty"`
+}
+
+type wireInteractiveRequest struct {
+	Code        string              `json:"code"`
+	Language    Language            `json:"language"`
+	TimeLimit   time.Duration       `json:"time_limit,omitempty"`
+	MemoryLimit int64               `json:"memory_limit,omitempty"`
+	Interactor  wireInteractor      `json:"interactor"`
+	Tests       []map[string]string `json:"tests"`
+}
+
+type wireInteractor struct {
+	Code        string        `json:"code"`
+	Language    Language      `json:"language"`
+	Args        []string      `json:"args,omitempty"`
+	TimeLimit   time.Duration `json:"time_limit,omitempty"`
+	MemoryLimit int64         `json:"memory_limit,omitempty"`
+}
+
+type wireResult struct {
+	Stdout        string        `json:"stdout"`
+	Stderr        string        `json:"stderr"`
+	ExitCode      int           `json:"exit_code"`
+	TimeUsed      time.Duration `json:"time_used"`
+	CPUTime       time.Duration `json:"cpu_time,omitempty"`
+	MemoryUsed    int64         `json:"memory_used,omitempty"`
+	TimedOut      bool          `json:"timed_out,omitempty"`
+	OOMKilled     bool          `json:"oom_killed,omitempty"`
+	CompileFailed bool          `json:"compile_failed,omitempty"`
+	Error         string        `json:"error,omitempty"`
+}
+
+// wireBatchLine is either the result of input Index or, as the last line,
+// the error that ended the batch.
+type wireBatchLine struct {
+	Index  int              `json:"index"`
+	Result *wireResult      `json:"result,omitempty"`
+	Error  *apierr.AppError `json:"error,omitempty"`
+}
+
+// wireInteractiveLine is like wireBatchLine with the results of both sides
+// of the interaction.
+type wireInteractiveLine struct {
+	Index      int              `json:"index"`
+	Program    *wireResult      `json:"program,omitempty"`
+	Interactor *wireResult      `json:"interactor,omitempty"`
+	Error      *apierr.AppError `json:"error,omitempty"`
+}
+
+// wireStreamLine is a chunk of output or, as the last line, the result of the
+// run or the error that ended it.
+type wireStreamLine struct {
+	Output *OutputChunk     `json:"output,omitempty"`
+	Result *wireResult      `json:"result,omitempty"`
+	Error  *apierr.AppError `json:"error,omitempty"`
+}
+
+func toWireRequest(req ExecutionRequest) wireRequest {
+	return wireRequest{
+		Code:        req.Code,
+		Language:    req.Language,
+		Stdin:       req.Stdin,
+		TimeLimit:   req.TimeLimit,
+		MemoryLimit: req.MemoryLimit,
+		Args:        req.Args,
+		Files:       req.Files,
+		OutputLimit: req.OutputLimit,
+	}
+}
+
+func toWireInteractiveRequest(req InteractiveRequest) wireInteractiveRequest {
+	return wireInteractiveRequest{
+		Code:        req.Code,
+		Language:    req.Language,
+		TimeLimit:   req.TimeLimit,
+		MemoryLimit: req.MemoryLimit,
+		Interactor: wireInteractor{
+			Code:        req.Interactor.Code,
+			Language:    req.Interactor.Language,
+			Args:        req.Interactor.Args,
+			TimeLimit:   req.Interactor.TimeLimit,
+			MemoryLimit: req.Interactor.MemoryLimit,
+		},
+		Tests: req.Tests,
+	}
+}
+
+func (r wireInteractiveRequest) request() InteractiveRequest {
+	return InteractiveRequest{
+		Code:        r.Code,
+		Language:    r.Language,
+		TimeLimit:   r.TimeLimit,
+		MemoryLimit: r.MemoryLimit,
+		Interactor: Interactor{
+			Code:        r.Interactor.Code,
+			Language:    r.Interactor.Language,
+			Args:        r.Interactor.Args,
+			TimeLimit:   r.Interactor.TimeLimit,
+			MemoryLimit: r.Interactor.MemoryLimit,
+		},
+		Tests: r.Tests,
+	}
+}
+
+func (r wireRequest) request() ExecutionRequest {
+	return ExecutionRequest{
+		Code:        r.Code,
+		Language:    r.Language,
+		Stdin:       r.Stdin,
+		TimeLimit:   r.TimeLimit,
+		MemoryLimit: r.MemoryLimit,
+		Args:        r.Args,
+		Files:       r.Files,
+		OutputLimit: r.OutputLimit,
+	}
+}
+
+func toWireResult(res ExecutionResult) wireResult {
+	w := wireResult{
+		Stdout:        res.Stdout,
+		Stderr:        res.Stderr,
+		ExitCode:      res.ExitCode,
+		TimeUsed:      res.TimeUsed,
+		CPUTime:       res.CPUTime,
+		MemoryUsed:    res.MemoryUsed,
+		TimedOut:      res.TimedOut,
+		OOMKilled:     res.OOMKilled,
+		CompileFailed: res.CompileFailed,
+	}
+	if res.Error != nil {
+		w.Error = res.Error.Error()
+	}
+	return w
+}
+
+func (w wireResult) result() ExecutionResult {
+	res := ExecutionResult{
+		Stdout:        w.Stdout,
+		Stderr:        w.Stderr,
+		ExitCode:      w.ExitCode,
+		TimeUsed:      w.TimeUsed,
+		CPUTime:       w.CPUTime,
+		MemoryUsed:    w.MemoryUsed,
+		TimedOut:      w.TimedOut,
+		OOMKilled:     w.OOMKilled,
+		CompileFailed: w.CompileFailed,
+	}
+	if w.Error != "" {
+		res.Error = errors.New(w.Error)
+	}
+	return res
+}
+
+// toWireError keeps application errors such as ErrExecutorOverloaded intact
+// so that the client can tell a busy worker from a broken one.
+func toWireError(err error) *apierr.AppError {
+	var appErr *apierr.AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return apierr.New(apierr.ErrInternal, err.Error())
+}
+
+// NewJudgeHandler serves exec over the judge protocol. Requests must carry
+// token as a bearer token unless token is empty.
+func NewJudgeHandler(exec Executor, token string) http.Handler {
+	h := &judgeHandler{exec: exec}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", h.health)
+	mux.HandleFunc("POST /run", h.run)
+	mux.HandleFunc("POST /batch", h.batch)
+	mux.HandleFunc("POST /stream", h.stream)
+	mux.HandleFunc("POST /interactive", h.interactive)
+	if token == "" {
+		return mux
+	}
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			writeJudgeError(w, apierr.New(apierr.ErrInvalidToken, "invalid judge token"))
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+type judgeHandler struct {
+	exec Executor
+}
+
+// maxJudgeRequestBytes bounds request bodies. A batch carries the inputs of
+// every test of a problem, which problem packages keep within 200 MB.
+const maxJudgeRequestBytes = 256 << 20
+
+func decodeJudgeRequest(w http.ResponseWriter, r *http.Request, v any) error {
+	return json.NewDecoder(http.MaxBytesReader(w, r.Body, maxJudgeRequestBytes)).Decode(v)
+}
+
+func (h *judgeHandler) health(w http.ResponseWriter, _ *http.Request) {
+	if !h.exec.IsReady() {
+		writeJudgeError(w, apierr.New(apierr.ErrExecutorNotReady, "executor is not ready"))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *judgeHandler) run(w http.ResponseWriter, r *http.Request) {
+	var req wireRequest
+	if err := decodeJudgeRequest(w, r, &req); err != nil {
+		writeJudgeError(w, apierr.New(apierr.ErrValidation, "invalid request body"))
+		return
+	}
+	res, err := h.exec.Run(r.Context(), req.request())
+	if err != nil {
+		writeJudgeError(w, toWireError(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(toWireResult(res))
+}
+
+func (h *judgeHandler) batch(w http.ResponseWriter, r *http.Request) {
+	var req wireBatchRequest
+	if err := decodeJudgeRequest(w, r, &req); err != nil {
+		writeJudgeError(w, apierr.New(apierr.ErrValidation, "invalid request body"))
+		return
+	}
+
+	// The status is only sent with the first line, so an error before any
+	// result can still be reported with its own status code.
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	started := false
+	err := RunBatch(r.Context(), h.exec, BatchRequest{
+		Code:        req.Code,
+		Language:    req.Language,
+		Inputs:      req.Inputs,
+		Args:        req.Args,
+		InputArgs:   req.InputArgs,
+		Files:       req.Files,
+		TimeLimit:   req.TimeLimit,
+		MemoryLimit: req.MemoryLimit,
+		OutputLimit: req.OutputLimit,
+	}, func(i int, res ExecutionResult) bool {
+		if !started {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			started = true
+		}
+		wr := toWireResult(res)
+		if err := enc.Encode(wireBatchLine{Index: i, Result: &wr}); err != nil {
+			return false
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return r.Context().Err() == nil
+	})
+	switch {
+	case err != nil && !started:
+		writeJudgeError(w, toWireError(err))
+	case err != nil:
+		_ = enc.Encode(wireBatchLine{Error: toWireError(err)})
+	case !started:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (h *judgeHandler) interactive(w http.ResponseWriter, r *http.Request) {
+	var req wireInteractiveRequest
+	if err := decodeJudgeRequest(w, r, &req); err != nil {
+		writeJudgeError(w, apierr.New(apierr.ErrValidation, "invalid request body"))
+		return
+	}
+
+	// As in batch, the status is only sent with the first line.
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	started := false
+	err := RunInteractive(r.Context(), h.exec, req.request(), func(i int, res InteractiveResult) bool {
+		if !started {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			started = true
+		}
+		program, interactor := toWireResult(res.Program), toWireResult(res.Interactor)
+		if err := enc.Encode(wireInteractiveLine{Index: i, Program: &program, Interactor: &interactor}); err != nil {
+			return false
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return r.Context().Err() == nil
+	})
+	switch {
+	case err != nil && !started:
+		writeJudgeError(w, toWireError(err))
+	case err != nil:
+		_ = enc.Encode(wireInteractiveLine{Error: toWireError(err)})
+	case !started:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (h *judgeHandler) stream(w http.ResponseWriter, r *http.Request) {
+	var req wireRequest
+	if err := decodeJudgeRequest(w, r, &req); err != nil {
+		writeJudgeError(w, apierr.New(apierr.ErrValidation, "invalid request body"))
+		return
+	}
+
+	// As in batch, the status is only sent with the first line.
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	started := false
+	start := func() {
+		if !started {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			started = true
+		}
+	}
+	res, err := RunStream(r.Context(), h.exec, req.request(), func(chunk OutputChunk) {
+		start()
+		if enc.Encode(wireStreamLine{Output: &chunk}) == nil && flusher != nil {
+			flusher.Flush()
+		}
+	})
+	switch {
+	case err != nil && !started:
+		writeJudgeError(w, toWireError(err))
+	case err != nil:
+		_ = enc.Encode(wireStreamLine{Error: toWireError(err)})
+	default:
+		start()
+		wr := toWireResult(res)
+		_ = enc.Encode(wireStreamLine{Result: &wr})
+	}
+}
+
+func writeJudgeError(w http.ResponseWriter, err *apierr.AppError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(err.HTTPStatus)
+	_ = json.NewEncoder(w).Encode(err)
+}
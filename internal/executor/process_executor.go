@@ -0,0 +1,553 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"bytebattle/internal/apierr"
+)
+
+// ProcessExecutor runs programs as local processes in a fresh directory per
+// request, optionally isolated with nsjail or bubblewrap. It needs no Docker
+// daemon, which makes it suitable for development machines and CI runners.
+type ProcessExecutor struct {
+	config    *Config
+	languages map[Language]LangSettings
+	sandbox   sandbox
+	workDir   string
+	slots     chan struct{}
+	pending   atomic.Int32
+}
+
+func NewProcessExecutor(cfg *Config) (*ProcessExecutor, error) {
+	sb, err := resolveSandbox(cfg.Process.Sandbox, cfg.Process.SandboxArgs, cfg.Process.SandboxPaths)
+	if err != nil {
+		return nil, err
+	}
+	if sb.kind == SandboxNone {
+		log.Printf("warn: process executor runs without a sandbox, only resource limits apply")
+	}
+
+	workDir := cfg.Process.WorkDir
+	if workDir == "" {
+		workDir = os.TempDir()
+	}
+	if err := os.MkdirAll(workDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create work dir: %w", err)
+	}
+
+	concurrency := cfg.Process.MaxConcurrent
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+
+	e := &ProcessExecutor{
+		config:    cfg,
+		languages: make(map[Language]LangSettings, len(cfg.Languages)),
+		sandbox:   sb,
+		workDir:   workDir,
+		slots:     make(chan struct{}, concurrency),
+	}
+	for lang, settings := range cfg.Languages {
+		if missing := missingTool(settings); missing != "" {
+			log.Printf("warn: process executor: %s not found, %s is disabled", missing, lang)
+			continue
+		}
+		e.languages[lang] = settings
+	}
+	return e, nil
+}
+
+// missingTool returns the first compile or run tool that is not on PATH.
+// Commands with a path, such as ./solution, are produced by the compile step.
+func missingTool(settings LangSettings) string {
+	for _, cmd := range [][]string{settings.CompileCmd, settings.RunCmd} {
+		if len(cmd) == 0 || strings.Contains(cmd[0], "/") {
+			continue
+		}
+		if _, err := exec.LookPath(cmd[0]); err != nil {
+			return cmd[0]
+		}
+	}
+	return ""
+}
+
+func (e *ProcessExecutor) IsReady() bool {
+	return len(e.languages) > 0
+}
+
+// Run compiles and runs req in a directory of its own. Limits are applied as
+// in DockerExecutor.Run.
+func (e *ProcessExecutor) Run(ctx context.Context, req ExecutionRequest) (ExecutionResult, error) {
+	return e.run(ctx, req, runOptions{outputLimit: req.OutputLimit})
+}
+
+// RunStream implements StreamExecutor. The output is streamed as the
+// program writes it.
+func (e *ProcessExecutor) RunStream(ctx context.Context, req ExecutionRequest, onOutput func(OutputChunk)) (ExecutionResult, error) {
+	stream := newOutputStreamer(onOutput)
+	defer stream.close()
+	return e.run(ctx, req, runOptions{stream: stream, outputLimit: req.OutputLimit})
+}
+
+func (e *ProcessExecutor) run(ctx context.Context, req ExecutionRequest, opts runOptions) (ExecutionResult, error) {
+	lang, ok := e.languages[req.Language]
+	if !ok {
+		return ExecutionResult{}, fmt.Errorf("unsupported language: %s", req.Language)
+	}
+	release, err := e.acquire(ctx)
+	if err != nil {
+		return ExecutionResult{Error: err}, err
+	}
+	defer release()
+
+	dir, err := os.MkdirTemp(e.workDir, "bytebattle-run-")
+	if err != nil {
+		return ExecutionResult{Error: err}, fmt.Errorf("create run dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	timeLimit, memLimit := lang.scaleLimits(req)
+	if res, err := e.prepareProgram(ctx, dir, req.Code, req.Files, &lang); err != nil || res != nil {
+		return *res, err
+	}
+	return e.runProgram(ctx, dir, &lang, req.Stdin, req.Args, timeLimit, memLimit, opts)
+}
+
+// RunBatch implements BatchExecutor: the program is compiled once and every
+// input is run in the same directory.
+func (e *ProcessExecutor) RunBatch(ctx context.Context, req BatchRequest, onResult func(i int, res ExecutionResult) bool) error {
+	lang, ok := e.languages[req.Language]
+	if !ok {
+		return fmt.Errorf("unsupported language: %s", req.Language)
+	}
+	if len(req.Inputs) == 0 {
+		return nil
+	}
+	release, err := e.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	dir, err := os.MkdirTemp(e.workDir, "bytebattle-run-")
+	if err != nil {
+		return fmt.Errorf("create run dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	timeLimit, memLimit := lang.scaleLimits(ExecutionRequest{TimeLimit: req.TimeLimit, MemoryLimit: req.MemoryLimit})
+	if res, err := e.prepareProgram(ctx, dir, req.Code, nil, &lang); err != nil || res != nil {
+		if err != nil {
+			return err
+		}
+		onResult(0, *res)
+		return nil
+	}
+	for i, input := range req.Inputs {
+		if err := writeFiles(dir, req.filesAt(i)); err != nil {
+			return err
+		}
+		res, err := e.runProgram(ctx, dir, &lang, input, req.argsAt(i), timeLimit, memLimit, runOptions{outputLimit: req.OutputLimit})
+		if err != nil {
+			return err
+		}
+		if !onResult(i, res) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// RunInteractive implements InteractiveExecutor. Both programs are compiled
+// once in directories of their own and connected with pipes on every test.
+func (e *ProcessExecutor) RunInteractive(ctx context.Context, req InteractiveRequest, onResult func(i int, res InteractiveResult) bool) error {
+	lang, ok := e.languages[req.Language]
+	if !ok {
+		return fmt.Errorf("unsupported language: %s", req.Language)
+	}
+	ilang, ok := e.languages[req.Interactor.Language]
+	if !ok {
+		return fmt.Errorf("unsupported interactor language: %s", req.Interactor.Language)
+	}
+	if len(req.Tests) == 0 {
+		return nil
+	}
+	release, err := e.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	dir, err := os.MkdirTemp(e.workDir, "bytebattle-run-")
+	if err != nil {
+		return fmt.Errorf("create run dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	idir, err := os.MkdirTemp(e.workDir, "bytebattle-interactor-")
+	if err != nil {
+		return fmt.Errorf("create interactor dir: %w", err)
+	}
+	defer os.RemoveAll(idir)
+
+	if res, err := e.prepareProgram(ctx, idir, req.Interactor.Code, nil, &ilang); err != nil || res != nil {
+		if err != nil {
+			return err
+		}
+		onResult(0, InteractiveResult{Interactor: *res})
+		return nil
+	}
+	if res, err := e.prepareProgram(ctx, dir, req.Code, nil, &lang); err != nil || res != nil {
+		if err != nil {
+			return err
+		}
+		onResult(0, InteractiveResult{Program: *res})
+		return nil
+	}
+
+	timeLimit, memLimit := lang.scaleLimits(ExecutionRequest{TimeLimit: req.TimeLimit, MemoryLimit: req.MemoryLimit})
+	for i, files := range req.Tests {
+		for name, content := range files {
+			if !filepath.IsLocal(name) {
+				return fmt.Errorf("invalid file name %q", name)
+			}
+			if err := os.WriteFile(filepath.Join(idir, name), []byte(content), 0o644); err != nil {
+				return fmt.Errorf("failed to copy files: %w", err)
+			}
+		}
+		res, err := e.runInteraction(ctx, dir, &lang, timeLimit, memLimit, idir, &ilang, req.Interactor)
+		if err != nil {
+			return err
+		}
+		if !onResult(i, res) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// runInteraction runs the compiled program in dir against the interactor in
+// idir. The interactor writes to the program directly; the program's output
+// is relayed so that it is also kept in the result.
+func (e *ProcessExecutor) runInteraction(ctx context.Context, dir string, langConfig *LangSettings, timeLimit time.Duration, memLimit int64, idir string, interactorConfig *LangSettings, interactor Interactor) (InteractiveResult, error) {
+	limit, memLimit := runLimits(langConfig, timeLimit, memLimit)
+	ilimit, imemLimit := runLimits(interactorConfig, interactor.TimeLimit, interactor.MemoryLimit)
+
+	// interactor -> program, program -> relay, relay -> interactor
+	programIn, interactorOut, err := os.Pipe()
+	if err != nil {
+		return InteractiveResult{}, err
+	}
+	defer programIn.Close()
+	defer interactorOut.Close()
+	relayIn, programOut, err := os.Pipe()
+	if err != nil {
+		return InteractiveResult{}, err
+	}
+	defer relayIn.Close()
+	defer programOut.Close()
+	interactorIn, relayOut, err := os.Pipe()
+	if err != nil {
+		return InteractiveResult{}, err
+	}
+	defer interactorIn.Close()
+	defer relayOut.Close()
+
+	iargv := append(append([]string{}, interactorConfig.RunCmd...), interactor.Args...)
+	irc, err := e.startCommand(ctx, idir, iargv, interactorIn, interactorOut, ilimit, imemLimit, nil)
+	if err != nil {
+		return InteractiveResult{}, err
+	}
+	prc, err := e.startCommand(ctx, dir, langConfig.RunCmd, programIn, programOut, limit, memLimit, nil)
+	if err != nil {
+		irc.cancel()
+		_, _ = irc.wait()
+		return InteractiveResult{}, err
+	}
+	// The children have their own copies; ours would keep the pipes open
+	// after either side exits.
+	for _, f := range []*os.File{programIn, interactorOut, programOut, interactorIn} {
+		f.Close()
+	}
+
+	transcript := &cappedBuffer{max: maxLogSize + 1}
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		_, _ = io.Copy(io.MultiWriter(transcript, &interactorOutput{w: relayOut}), relayIn)
+		relayOut.Close()
+	}()
+
+	pout, perr := prc.wait()
+	<-relayDone
+	iout, ierr := irc.wait()
+	if perr != nil {
+		return InteractiveResult{}, perr
+	}
+	if ierr != nil {
+		return InteractiveResult{}, ierr
+	}
+	pout.stdout = transcript.String()
+	return InteractiveResult{
+		Program:    programResult(pout, memLimit, runOptions{}),
+		Interactor: programResult(iout, imemLimit, runOptions{}),
+	}, nil
+}
+
+func (e *ProcessExecutor) acquire(ctx context.Context) (func(), error) {
+	if int(e.pending.Add(1)) > cap(e.slots)*(queueMultiplier+1) {
+		e.pending.Add(-1)
+		return nil, apierr.New(apierr.ErrExecutorOverloaded, "executor queue is full, try again later")
+	}
+	select {
+	case e.slots <- struct{}{}:
+		return func() {
+			<-e.slots
+			e.pending.Add(-1)
+		}, nil
+	case <-ctx.Done():
+		e.pending.Add(-1)
+		return nil, ctx.Err()
+	}
+}
+
+// prepareProgram writes the source and files into dir and compiles the
+// program. Like DockerExecutor.prepareProgram it returns a non-nil result
+// when the program cannot be run.
+func (e *ProcessExecutor) prepareProgram(ctx context.Context, dir, code string, files map[string]string, langConfig *LangSettings) (*ExecutionResult, error) {
+	all := make(map[string]string, len(files)+1)
+	for name, content := range files {
+		all[name] = content
+	}
+	all[langConfig.SourceFile] = code
+	if err := writeFiles(dir, all); err != nil {
+		return &ExecutionResult{Error: err}, err
+	}
+
+	if len(langConfig.CompileCmd) == 0 {
+		return nil, nil
+	}
+	out, err := e.runCommand(ctx, dir, langConfig.CompileCmd, "", compileTimeLimit, baseMemoryLimit(langConfig), runOptions{})
+	if err != nil {
+		return &ExecutionResult{Error: err}, err
+	}
+	if out.exitCode == 0 && !out.timedOut {
+		return nil, nil
+	}
+	res := ExecutionResult{
+		Stdout:        truncateString(out.stdout, maxLogSize),
+		Stderr:        truncateString(out.stderr, maxLogSize),
+		ExitCode:      out.exitCode,
+		TimeUsed:      out.elapsed,
+		CompileFailed: true,
+	}
+	if out.timedOut {
+		res.Stderr += "\nCompilation timed out."
+	}
+	return &res, nil
+}
+
+// writeFiles writes files into dir, refusing names that lead out of it.
+func writeFiles(dir string, files map[string]string) error {
+	for name, content := range files {
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("invalid file name %q", name)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("failed to copy files: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return fmt.Errorf("failed to copy files: %w", err)
+		}
+	}
+	return nil
+}
+
+func (e *ProcessExecutor) runProgram(ctx context.Context, dir string, langConfig *LangSettings, stdin string, args []string, timeLimit time.Duration, memLimit int64, opts runOptions) (ExecutionResult, error) {
+	limit, memLimit := runLimits(langConfig, timeLimit, memLimit)
+	argv := append(append([]string{}, langConfig.RunCmd...), args...)
+	out, err := e.runCommand(ctx, dir, argv, stdin, limit, memLimit, opts)
+	if err != nil {
+		return ExecutionResult{Error: err}, err
+	}
+	return programResult(out, memLimit, opts), nil
+}
+
+// runLimits fills in the language's defaults for limits left at zero.
+func runLimits(langConfig *LangSettings, timeLimit time.Duration, memLimit int64) (time.Duration, int64) {
+	limit := timeLimit
+	if limit == 0 && langConfig.TimeLimit > 0 {
+		limit = time.Duration(langConfig.TimeLimit) * time.Second
+	}
+	if limit == 0 {
+		limit = 5 * time.Second
+	}
+	if memLimit == 0 {
+		memLimit = baseMemoryLimit(langConfig)
+	}
+	return limit, memLimit
+}
+
+func programResult(out processResult, memLimit int64, opts runOptions) ExecutionResult {
+	// The data limit makes allocations fail rather than getting the process
+	// killed, so a crash close to the limit is taken as running out of memory.
+	oomKilled := !out.timedOut && out.exitCode != 0 && out.maxRSSKb*1024 >= memLimit-memLimit/10
+	res := ExecutionResult{
+		Stdout:     truncateString(out.stdout, opts.stdoutLimit()),
+		Stderr:     truncateString(out.stderr, maxLogSize),
+		ExitCode:   out.exitCode,
+		TimeUsed:   out.elapsed,
+		CPUTime:    out.cpuTime,
+		MemoryUsed: out.maxRSSKb * 1024,
+		TimedOut:   out.timedOut,
+		OOMKilled:  oomKilled,
+	}
+	if out.timedOut {
+		res.ExitCode = exitCodeTimeout
+		res.Stderr += "\nExecution timed out."
+	}
+	return res
+}
+
+type processResult struct {
+	stdout, stderr string
+	exitCode       int
+	elapsed        time.Duration
+	cpuTime        time.Duration
+	maxRSSKb       int64
+	timedOut       bool
+}
+
+// runCommand runs argv in dir inside the sandbox. The wall-clock limit is
+// enforced by killing the whole process group; the CPU time rlimit is a
+// backstop for programs that fork past it. The output is also streamed to
+// opts.stream unless it is nil.
+func (e *ProcessExecutor) runCommand(ctx context.Context, dir string, argv []string, stdin string, limit time.Duration, memLimit int64, opts runOptions) (processResult, error) {
+	stdout := &cappedBuffer{max: opts.stdoutLimit() + 1}
+	rc, err := e.startCommand(ctx, dir, argv, strings.NewReader(stdin), opts.stream.writer(StreamStdout, stdout), limit, memLimit, opts.stream)
+	if err != nil {
+		return processResult{}, err
+	}
+	out, err := rc.wait()
+	out.stdout = stdout.String()
+	return out, err
+}
+
+// runningCommand is a command started by startCommand.
+type runningCommand struct {
+	ctx    context.Context
+	runCtx context.Context
+	cancel context.CancelFunc
+	cmd    *exec.Cmd
+	stderr *cappedBuffer
+	start  time.Time
+	token  string
+}
+
+// startCommand starts argv in dir inside the sandbox with the limits of
+// runCommand. stdin and stdout may be pipes shared with another command.
+func (e *ProcessExecutor) startCommand(ctx context.Context, dir string, argv []string, stdin io.Reader, stdout io.Writer, limit time.Duration, memLimit int64, stream *outputStreamer) (*runningCommand, error) {
+	cpuSeconds := int64(math.Ceil(limit.Seconds())) + 1
+	token := newRunToken()
+	argv = e.sandbox.command(dir, cpuSeconds, memLimit/1024, token, argv)
+
+	runCtx, cancel := context.WithTimeout(ctx, limit)
+	stderr := &cappedBuffer{max: maxLogSize + 1}
+	cmd := exec.CommandContext(runCtx, argv[0], argv[1:]...)
+	cmd.Dir = dir
+	cmd.Env = e.env(dir)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stream.writer(StreamStderr, stderr)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to run %s: %w", argv[0], err)
+	}
+	return &runningCommand{ctx: ctx, runCtx: runCtx, cancel: cancel, cmd: cmd, stderr: stderr, start: start, token: token}, nil
+}
+
+// wait waits for the command to exit. The result has no stdout: that went
+// wherever startCommand was told to send it.
+func (rc *runningCommand) wait() (processResult, error) {
+	defer rc.cancel()
+	err := rc.cmd.Wait()
+	elapsed := time.Since(rc.start)
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return processResult{}, fmt.Errorf("failed to run %s: %w", rc.cmd.Path, err)
+	}
+	if rc.ctx.Err() != nil {
+		return processResult{}, rc.ctx.Err()
+	}
+
+	cmd := rc.cmd
+	out := processResult{
+		stderr:   rc.stderr.String(),
+		exitCode: cmd.ProcessState.ExitCode(),
+		elapsed:  elapsed,
+		timedOut: errors.Is(rc.runCtx.Err(), context.DeadlineExceeded),
+	}
+	if status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		out.exitCode = 128 + int(status.Signal())
+		out.timedOut = out.timedOut || status.Signal() == syscall.SIGXCPU
+	}
+	if limitsFailed(out.exitCode, out.stderr, rc.token) {
+		return processResult{}, fmt.Errorf("failed to run %s: %s", rc.cmd.Path, strings.TrimSpace(out.stderr))
+	}
+	if ru, ok := cmd.ProcessState.SysUsage().(*syscall.Rusage); ok {
+		out.maxRSSKb = ru.Maxrss
+		out.cpuTime = time.Duration(ru.Utime.Nano() + ru.Stime.Nano()).Round(time.Millisecond)
+	}
+	return out, nil
+}
+
+// env is the minimal environment commands run with. HOME points into the
+// run directory so compilers keep their caches there.
+func (e *ProcessExecutor) env(dir string) []string {
+	env := []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + dir,
+		"LANG=C.UTF-8",
+	}
+	return append(env, e.config.Process.Env...)
+}
+
+// cappedBuffer keeps the first max bytes written to it and discards the
+// rest, so a program printing endlessly cannot exhaust the server's memory.
+type cappedBuffer struct {
+	buf bytes.Buffer
+	max int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) String() string {
+	return b.buf.String()
+}
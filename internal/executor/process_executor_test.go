@@ -0,0 +1,350 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newShellExecutor returns a process executor without a sandbox whose only
+// language is POSIX sh; "sh -n" stands in for a compiler.
+func newShellExecutor(t *testing.T) *ProcessExecutor {
+	t.Helper()
+	e, err := NewProcessExecutor(&Config{
+		Process: ProcessConfig{Sandbox: SandboxNone, WorkDir: t.TempDir(), MaxConcurrent: 2},
+		Languages: map[Language]LangSettings{"sh": {
+			SourceFile:  "main.sh",
+			CompileCmd:  []string{"sh", "-n", "main.sh"},
+			RunCmd:      []string{"sh", "main.sh"},
+			MemoryLimit: 64 * 1024 * 1024,
+		}},
+	})
+	require.NoError(t, err)
+	require.True(t, e.IsReady())
+	return e
+}
+
+func TestProcessExecutor_Run(t *testing.T) {
+	e := newShellExecutor(t)
+
+	res, err := e.Run(context.Background(), ExecutionRequest{
+		Code:     `read x; echo "got $x $1"; cat data.txt; echo oops >&2`,
+		Language: "sh",
+		Stdin:    "42\n",
+		Args:     []string{"arg with spaces"},
+		Files:    map[string]string{"data.txt": "file\n"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, res.ExitCode)
+	assert.Equal(t, "got 42 arg with spaces\nfile\n", res.Stdout)
+	assert.Equal(t, "oops\n", res.Stderr)
+	assert.False(t, res.TimedOut)
+	assert.Positive(t, res.MemoryUsed)
+}
+
+func TestProcessExecutor_CompileError(t *testing.T) {
+	e := newShellExecutor(t)
+
+	res, err := e.Run(context.Background(), ExecutionRequest{Code: "if then fi (", Language: "sh"})
+	require.NoError(t, err)
+	assert.True(t, res.CompileFailed)
+	assert.NotEmpty(t, res.Stderr)
+}
+
+func TestProcessExecutor_Timeout(t *testing.T) {
+	e := newShellExecutor(t)
+
+	start := time.Now()
+	res, err := e.Run(context.Background(), ExecutionRequest{
+		Code:      "sleep 10 & wait",
+		Language:  "sh",
+		TimeLimit: 200 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	assert.True(t, res.TimedOut)
+	assert.Equal(t, exitCodeTimeout, res.ExitCode)
+	assert.Less(t, time.Since(start), 5*time.Second, "the whole process group is killed")
+}
+
+func TestProcessExecutor_RuntimeError(t *testing.T) {
+	e := newShellExecutor(t)
+
+	res, err := e.Run(context.Background(), ExecutionRequest{Code: "exit 3", Language: "sh"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.ExitCode)
+	assert.False(t, res.TimedOut)
+	assert.False(t, res.OOMKilled)
+}
+
+func TestProcessExecutor_OutputLimit(t *testing.T) {
+	e := newShellExecutor(t)
+	code := `head -c 20000 /dev/zero | tr '\0' x`
+
+	res, err := e.Run(context.Background(), ExecutionRequest{Code: code, Language: "sh"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(res.Stdout, "...[truncated]"))
+
+	res, err = e.Run(context.Background(), ExecutionRequest{Code: code, Language: "sh", OutputLimit: 32 * 1024})
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("x", 20000), res.Stdout)
+}
+
+func TestProcessExecutor_RejectsFilesOutsideRunDir(t *testing.T) {
+	e := newShellExecutor(t)
+
+	_, err := e.Run(context.Background(), ExecutionRequest{
+		Code:     "true",
+		Language: "sh",
+		Files:    map[string]string{"../escape.txt": "x"},
+	})
+	assert.Error(t, err)
+}
+
+func TestProcessExecutor_RunBatch(t *testing.T) {
+	e := newShellExecutor(t)
+
+	var outputs []string
+	err := e.RunBatch(context.Background(), BatchRequest{
+		Code:     `read x; echo $((x * 2))`,
+		Language: "sh",
+		Inputs:   []string{"1\n", "2\n", "3\n"},
+	}, func(i int, res ExecutionResult) bool {
+		outputs = append(outputs, res.Stdout)
+		return i < 1
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2\n", "4\n"}, outputs)
+}
+
+func TestProcessExecutor_RunBatchArgsAndFiles(t *testing.T) {
+	e := newShellExecutor(t)
+
+	var outputs []string
+	err := e.RunBatch(context.Background(), BatchRequest{
+		Code:     `cat "$1"`,
+		Language: "sh",
+		Inputs:   []string{"", ""},
+		Args:     []string{"answer.txt"},
+		Files:    []map[string]string{{"answer.txt": "a"}, {"answer.txt": "b"}},
+	}, func(_ int, res ExecutionResult) bool {
+		outputs = append(outputs, res.Stdout)
+		return true
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, outputs)
+}
+
+func TestProcessExecutor_RunBatchInputArgs(t *testing.T) {
+	e := newShellExecutor(t)
+
+	var outputs []string
+	err := e.RunBatch(context.Background(), BatchRequest{
+		Code:      `echo "$@"`,
+		Language:  "sh",
+		Inputs:    []string{"", ""},
+		Args:      []string{"gen"},
+		InputArgs: [][]string{{"1"}, {"2", "3"}},
+	}, func(_ int, res ExecutionResult) bool {
+		outputs = append(outputs, res.Stdout)
+		return true
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gen 1\n", "gen 2 3\n"}, outputs)
+}
+
+func TestProcessExecutor_RunBatchOutputLimit(t *testing.T) {
+	e := newShellExecutor(t)
+
+	var output string
+	err := e.RunBatch(context.Background(), BatchRequest{
+		Code:        `head -c 20000 /dev/zero | tr '\0' x`,
+		Language:    "sh",
+		Inputs:      []string{""},
+		OutputLimit: 20000,
+	}, func(_ int, res ExecutionResult) bool {
+		output = res.Stdout
+		return true
+	})
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("x", 20000), output)
+}
+
+func TestProcessExecutor_RunBatchCompileError(t *testing.T) {
+	e := newShellExecutor(t)
+
+	var results []ExecutionResult
+	err := e.RunBatch(context.Background(), BatchRequest{
+		Code:     "if then fi (",
+		Language: "sh",
+		Inputs:   []string{"1\n", "2\n"},
+	}, func(_ int, res ExecutionResult) bool {
+		results = append(results, res)
+		return true
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].CompileFailed)
+}
+
+// doublingInteractor sends the number in input.txt and accepts its double.
+const doublingInteractor = `read n < input.txt
+echo $n
+read ans
+[ "$ans" = "$((n * 2))" ] && exit 0
+echo "expected $((n * 2)), got $ans" >&2
+exit 1`
+
+func TestProcessExecutor_RunInteractive(t *testing.T) {
+	e := newShellExecutor(t)
+
+	var results []InteractiveResult
+	err := e.RunInteractive(context.Background(), InteractiveRequest{
+		Code:       `read x; echo $((x * 2))`,
+		Language:   "sh",
+		Interactor: Interactor{Code: doublingInteractor, Language: "sh"},
+		Tests:      []map[string]string{{"input.txt": "3\n"}, {"input.txt": "5\n"}},
+	}, func(_ int, res InteractiveResult) bool {
+		results = append(results, res)
+		return true
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "6\n", results[0].Program.Stdout)
+	assert.Equal(t, "10\n", results[1].Program.Stdout)
+	for _, res := range results {
+		assert.Zero(t, res.Program.ExitCode)
+		assert.Zero(t, res.Interactor.ExitCode)
+	}
+}
+
+func TestProcessExecutor_RunInteractiveRejected(t *testing.T) {
+	e := newShellExecutor(t)
+
+	var res InteractiveResult
+	err := e.RunInteractive(context.Background(), InteractiveRequest{
+		Code:       `read x; echo $x`,
+		Language:   "sh",
+		Interactor: Interactor{Code: doublingInteractor, Language: "sh"},
+		Tests:      []map[string]string{{"input.txt": "3\n"}},
+	}, func(_ int, r InteractiveResult) bool {
+		res = r
+		return true
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Interactor.ExitCode)
+	assert.Equal(t, "expected 6, got 3\n", res.Interactor.Stderr)
+}
+
+func TestProcessExecutor_RunInteractiveIdleProgram(t *testing.T) {
+	e := newShellExecutor(t)
+
+	var res InteractiveResult
+	err := e.RunInteractive(context.Background(), InteractiveRequest{
+		Code:       `read x; read y`,
+		Language:   "sh",
+		TimeLimit:  200 * time.Millisecond,
+		Interactor: Interactor{Code: doublingInteractor, Language: "sh", TimeLimit: 5 * time.Second},
+		Tests:      []map[string]string{{"input.txt": "3\n"}},
+	}, func(_ int, r InteractiveResult) bool {
+		res = r
+		return true
+	})
+	require.NoError(t, err)
+	assert.True(t, res.Program.TimedOut)
+	assert.False(t, res.Interactor.TimedOut)
+	assert.Equal(t, 1, res.Interactor.ExitCode)
+}
+
+func TestProcessExecutor_RunInteractiveCompileError(t *testing.T) {
+	e := newShellExecutor(t)
+
+	var results []InteractiveResult
+	err := e.RunInteractive(context.Background(), InteractiveRequest{
+		Code:       "if then fi (",
+		Language:   "sh",
+		Interactor: Interactor{Code: doublingInteractor, Language: "sh"},
+		Tests:      []map[string]string{{"input.txt": "1\n"}, {"input.txt": "2\n"}},
+	}, func(_ int, res InteractiveResult) bool {
+		results = append(results, res)
+		return true
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Program.CompileFailed)
+	assert.False(t, results[0].Interactor.CompileFailed)
+}
+
+func TestProcessExecutor_SkipsLanguagesWithoutTools(t *testing.T) {
+	e, err := NewProcessExecutor(&Config{
+		Process: ProcessConfig{Sandbox: SandboxNone, WorkDir: t.TempDir()},
+		Languages: map[Language]LangSettings{"missing": {
+			SourceFile: "main.x",
+			RunCmd:     []string{"bytebattle-no-such-interpreter", "main.x"},
+		}},
+	})
+	require.NoError(t, err)
+	assert.False(t, e.IsReady())
+
+	_, err = e.Run(context.Background(), ExecutionRequest{Code: "x", Language: "missing"})
+	assert.ErrorContains(t, err, "unsupported language")
+}
+
+func TestSandboxCommand(t *testing.T) {
+	argv := []string{"./solution", "arg"}
+
+	none := sandbox{kind: SandboxNone}.command("/work/run", 3, 1024, "t0k", argv)
+	assert.Equal(t, []string{
+		"/bin/sh", "-c",
+		`ulimit -t 3 && ulimit -d 1024 && ulimit -f 131072 && { ulimit -u 512 2>/dev/null || ulimit -p 512; } || ` +
+			`{ echo "bytebattle: cannot set resource limits t0k" >&2; exit 125; }; exec "$@"`,
+		"sh", "./solution", "arg",
+	}, none)
+
+	mounts := []sandboxMount{{path: "/usr"}, {path: "/bin", link: "usr/bin"}}
+	bwrap := sandbox{kind: SandboxBwrap, path: "/usr/bin/bwrap", args: []string{"--seccomp", "3"}, mounts: mounts}.command("/work/run", 3, 1024, "t0k", argv)
+	assert.Equal(t, "/usr/bin/bwrap", bwrap[0])
+	assert.Equal(t, []string{"--ro-bind", "/usr", "/usr", "--symlink", "usr/bin", "/bin"}, bwrap[1:7])
+	assert.Subset(t, bwrap, []string{"--unshare-all", "--bind", "/work/run", "--chdir", "--seccomp"})
+	assert.NotContains(t, bwrap, "/", "the host root is not mounted")
+	assert.Equal(t, none, bwrap[len(bwrap)-len(none):], "the limited command follows the sandbox arguments")
+	assert.Equal(t, "--", bwrap[len(bwrap)-len(none)-1])
+
+	nsjail := sandbox{kind: SandboxNsjail, path: "/usr/bin/nsjail", mounts: mounts}.command("/work/run", 3, 1024, "t0k", argv)
+	assert.Equal(t, "/usr/bin/nsjail", nsjail[0])
+	assert.Subset(t, nsjail, []string{"--bindmount_ro", "/usr", "--symlink", "usr/bin:/bin", "--bindmount", "/work/run", "--cwd", "--rlimit_nproc"})
+	assert.NotContains(t, nsjail, "--chroot")
+	assert.Equal(t, none, nsjail[len(nsjail)-len(none):])
+}
+
+func TestFindMounts(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "usr"), 0o755))
+	require.NoError(t, os.Symlink("usr", filepath.Join(dir, "bin")))
+
+	mounts := findMounts([]string{filepath.Join(dir, "usr"), filepath.Join(dir, "b*"), filepath.Join(dir, "missing")})
+	assert.Equal(t, []sandboxMount{
+		{path: filepath.Join(dir, "usr")},
+		{path: filepath.Join(dir, "bin"), link: "usr"},
+	}, mounts)
+}
+
+func TestLimitsFailed(t *testing.T) {
+	assert.True(t, limitsFailed(125, "sh: ulimit: error\nbytebattle: cannot set resource limits t0k\n", "t0k"))
+	assert.False(t, limitsFailed(125, "bytebattle: cannot set resource limits\n", "t0k"), "a program cannot fake the failure")
+	assert.False(t, limitsFailed(1, "bytebattle: cannot set resource limits t0k\n", "t0k"))
+}
+
+func TestResolveSandbox(t *testing.T) {
+	sb, err := resolveSandbox(SandboxNone, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, SandboxNone, sb.kind)
+
+	_, err = resolveSandbox("chroot", nil, nil)
+	assert.Error(t, err)
+}
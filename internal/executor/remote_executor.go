@@ -0,0 +1,367 @@
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"bytebattle/internal/apierr"
+)
+
+const (
+	remoteHealthInterval = 5 * time.Second
+	remoteHealthTimeout  = 2 * time.Second
+	// maxBatchLineSize bounds a single streamed line; outputs are already
+	// truncated to maxLogSize by the worker. Batch results, which may keep
+	// more output, are decoded without a line limit.
+	maxBatchLineSize = 1024 * 1024
+)
+
+// RemoteConfig lists the judge workers used by the remote backend.
+type RemoteConfig struct {
+	Workers []string `json:"workers,omitempty"`
+	Token   string   `json:"token,omitempty"`
+}
+
+// RemoteExecutor runs programs on judge workers (cmd/bytebattle-judge),
+// spreading requests over the healthy ones round-robin. A request that a
+// worker cannot take because it is unreachable or overloaded is retried on
+// the next one; runs have no side effects, so retrying is safe.
+type RemoteExecutor struct {
+	workers []*remoteWorker
+	token   string
+	client  *http.Client
+	next    atomic.Uint32
+	stop    context.CancelFunc
+}
+
+type remoteWorker struct {
+	url     string
+	healthy atomic.Bool
+}
+
+func NewRemoteExecutor(cfg RemoteConfig) (*RemoteExecutor, error) {
+	if len(cfg.Workers) == 0 {
+		return nil, errors.New("remote executor: no workers configured")
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	e := &RemoteExecutor{
+		token:  cfg.Token,
+		client: &http.Client{},
+		stop:   stop,
+	}
+	for _, url := range cfg.Workers {
+		e.workers = append(e.workers, &remoteWorker{url: strings.TrimRight(url, "/")})
+	}
+	e.checkHealth(ctx)
+	go e.healthLoop(ctx)
+	return e, nil
+}
+
+// Close stops the health checks.
+func (e *RemoteExecutor) Close() {
+	e.stop()
+}
+
+func (e *RemoteExecutor) IsReady() bool {
+	for _, w := range e.workers {
+		if w.healthy.Load() {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *RemoteExecutor) healthLoop(ctx context.Context) {
+	ticker := time.NewTicker(remoteHealthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			e.checkHealth(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (e *RemoteExecutor) checkHealth(ctx context.Context) {
+	for _, w := range e.workers {
+		healthy := e.ping(ctx, w) == nil
+		if w.healthy.Swap(healthy) != healthy {
+			log.Printf("judge worker %s healthy=%t", w.url, healthy)
+		}
+	}
+}
+
+func (e *RemoteExecutor) ping(ctx context.Context, w *remoteWorker) error {
+	ctx, cancel := context.WithTimeout(ctx, remoteHealthTimeout)
+	defer cancel()
+	resp, err := e.do(ctx, w, http.MethodGet, "/health", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// candidates returns the workers to try in order: healthy ones starting at
+// the round-robin position, then the unhealthy ones in case the health
+// information is stale.
+func (e *RemoteExecutor) candidates() []*remoteWorker {
+	start := int(e.next.Add(1))
+	var healthy, unhealthy []*remoteWorker
+	for i := range e.workers {
+		w := e.workers[(start+i)%len(e.workers)]
+		if w.healthy.Load() {
+			healthy = append(healthy, w)
+		} else {
+			unhealthy = append(unhealthy, w)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// retryable reports whether a request that failed with err may be sent to
+// another worker.
+func retryable(err error) bool {
+	var appErr *apierr.AppError
+	if errors.As(err, &appErr) {
+		return appErr.ErrorCode == apierr.ErrExecutorOverloaded || appErr.ErrorCode == apierr.ErrExecutorNotReady
+	}
+	var transportErr *remoteTransportError
+	return errors.As(err, &transportErr)
+}
+
+type remoteTransportError struct {
+	url string
+	err error
+}
+
+func (e *remoteTransportError) Error() string {
+	return fmt.Sprintf("judge worker %s: %v", e.url, e.err)
+}
+func (e *remoteTransportError) Unwrap() error { return e.err }
+
+// do sends a request to w. Transport failures are returned as
+// remoteTransportError and mark the worker unhealthy; non-2xx responses are
+// decoded into an apierr.AppError.
+func (e *RemoteExecutor) do(ctx context.Context, w *remoteWorker, method, path string, body any) (*http.Response, error) {
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, w.url+path, &buf)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if e.token != "" {
+		req.Header.Set("Authorization", "Bearer "+e.token)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		w.healthy.Store(false)
+		return nil, &remoteTransportError{url: w.url, err: err}
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	var appErr apierr.AppError
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxBatchLineSize)).Decode(&appErr); err != nil || appErr.ErrorCode == "" {
+		return nil, fmt.Errorf("judge worker %s: unexpected status %s", w.url, resp.Status)
+	}
+	return nil, apierr.New(appErr.ErrorCode, appErr.Message)
+}
+
+func (e *RemoteExecutor) Run(ctx context.Context, req ExecutionRequest) (ExecutionResult, error) {
+	body := toWireRequest(req)
+	var lastErr error
+	for _, w := range e.candidates() {
+		resp, err := e.do(ctx, w, http.MethodPost, "/run", body)
+		if err != nil {
+			lastErr = err
+			if retryable(err) {
+				continue
+			}
+			return ExecutionResult{Error: err}, err
+		}
+		var res wireResult
+		err = json.NewDecoder(resp.Body).Decode(&res)
+		resp.Body.Close()
+		if err != nil {
+			err = fmt.Errorf("judge worker %s: decode result: %w", w.url, err)
+			return ExecutionResult{Error: err}, err
+		}
+		return res.result(), nil
+	}
+	return ExecutionResult{Error: lastErr}, lastErr
+}
+
+// RunStream implements StreamExecutor by relaying the output a worker
+// streams. Like a batch, a run is only retried on another worker before any
+// output arrived.
+func (e *RemoteExecutor) RunStream(ctx context.Context, req ExecutionRequest, onOutput func(OutputChunk)) (ExecutionResult, error) {
+	body := toWireRequest(req)
+	var lastErr error
+	for _, w := range e.candidates() {
+		resp, err := e.do(ctx, w, http.MethodPost, "/stream", body)
+		if err != nil {
+			lastErr = err
+			if retryable(err) {
+				continue
+			}
+			return ExecutionResult{Error: err}, err
+		}
+		res, err := e.readStream(w, resp, onOutput)
+		if err != nil {
+			return ExecutionResult{Error: err}, err
+		}
+		return res, nil
+	}
+	return ExecutionResult{Error: lastErr}, lastErr
+}
+
+func (e *RemoteExecutor) readStream(w *remoteWorker, resp *http.Response, onOutput func(OutputChunk)) (ExecutionResult, error) {
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), maxBatchLineSize)
+	for scanner.Scan() {
+		var line wireStreamLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return ExecutionResult{}, fmt.Errorf("judge worker %s: decode stream line: %w", w.url, err)
+		}
+		switch {
+		case line.Error != nil:
+			return ExecutionResult{}, apierr.New(line.Error.ErrorCode, line.Error.Message)
+		case line.Result != nil:
+			return line.Result.result(), nil
+		case line.Output != nil:
+			onOutput(*line.Output)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return ExecutionResult{}, fmt.Errorf("judge worker %s: read stream: %w", w.url, err)
+	}
+	return ExecutionResult{}, fmt.Errorf("judge worker %s: stream ended without a result", w.url)
+}
+
+// RunBatch implements BatchExecutor by streaming results from one worker.
+// A batch is only retried on another worker before any result arrived.
+func (e *RemoteExecutor) RunBatch(ctx context.Context, req BatchRequest, onResult func(i int, res ExecutionResult) bool) error {
+	body := wireBatchRequest{
+		Code:        req.Code,
+		Language:    req.Language,
+		Inputs:      req.Inputs,
+		Args:        req.Args,
+		InputArgs:   req.InputArgs,
+		Files:       req.Files,
+		TimeLimit:   req.TimeLimit,
+		MemoryLimit: req.MemoryLimit,
+		OutputLimit: req.OutputLimit,
+	}
+	var lastErr error
+	for _, w := range e.candidates() {
+		resp, err := e.do(ctx, w, http.MethodPost, "/batch", body)
+		if err != nil {
+			lastErr = err
+			if retryable(err) {
+				continue
+			}
+			return err
+		}
+		return e.readBatch(w, resp, onResult)
+	}
+	return lastErr
+}
+
+func (e *RemoteExecutor) readBatch(w *remoteWorker, resp *http.Response, onResult func(i int, res ExecutionResult) bool) error {
+	// Closing the body before the stream ends tells the worker to stop.
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var line wireBatchLine
+		err := dec.Decode(&line)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("judge worker %s: decode batch result: %w", w.url, err)
+		}
+		if line.Error != nil {
+			return apierr.New(line.Error.ErrorCode, line.Error.Message)
+		}
+		if line.Result == nil {
+			continue
+		}
+		if !onResult(line.Index, line.Result.result()) {
+			return nil
+		}
+	}
+}
+
+// RunInteractive implements InteractiveExecutor by streaming results from one
+// worker, which runs both sides of the interaction. As with a batch, it is
+// only retried on another worker before any result arrived.
+func (e *RemoteExecutor) RunInteractive(ctx context.Context, req InteractiveRequest, onResult func(i int, res InteractiveResult) bool) error {
+	body := toWireInteractiveRequest(req)
+	var lastErr error
+	for _, w := range e.candidates() {
+		resp, err := e.do(ctx, w, http.MethodPost, "/interactive", body)
+		if err != nil {
+			lastErr = err
+			if retryable(err) {
+				continue
+			}
+			return err
+		}
+		return e.readInteractive(w, resp, onResult)
+	}
+	return lastErr
+}
+
+func (e *RemoteExecutor) readInteractive(w *remoteWorker, resp *http.Response, onResult func(i int, res InteractiveResult) bool) error {
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), maxBatchLineSize)
+	for scanner.Scan() {
+		var line wireInteractiveLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return fmt.Errorf("judge worker %s: decode interactive result: %w", w.url, err)
+		}
+		if line.Error != nil {
+			return apierr.New(line.Error.ErrorCode, line.Error.Message)
+		}
+		if line.Program == nil || line.Interactor == nil {
+			continue
+		}
+		if !onResult(line.Index, InteractiveResult{Program: line.Program.result(), Interactor: line.Interactor.result()}) {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("judge worker %s: read interactive results: %w", w.url, err)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"bytebattle/internal/apierr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoExecutor echoes stdin upper-cased and counts its runs.
+type echoExecutor struct {
+	runs atomic.Int32
+	err  error
+}
+
+func (e *echoExecutor) Run(_ context.Context, req ExecutionRequest) (ExecutionResult, error) {
+	e.runs.Add(1)
+	if e.err != nil {
+		return ExecutionResult{Error: e.err}, e.err
+	}
+	return ExecutionResult{
+		Stdout:     strings.ToUpper(req.Stdin),
+		ExitCode:   0,
+		TimeUsed:   1500 * time.Millisecond,
+		CPUTime:    time.Second,
+		MemoryUsed: 4096,
+		TimedOut:   req.TimeLimit == time.Nanosecond,
+	}, nil
+}
+
+func (e *echoExecutor) IsReady() bool { return true }
+
+func startJudge(t *testing.T, exec Executor, token string) string {
+	t.Helper()
+	srv := httptest.NewServer(NewJudgeHandler(exec, token))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func newRemote(t *testing.T, token string, urls ...string) *RemoteExecutor {
+	t.Helper()
+	e, err := NewRemoteExecutor(RemoteConfig{Workers: urls, Token: token})
+	require.NoError(t, err)
+	t.Cleanup(e.Close)
+	return e
+}
+
+func TestRemoteExecutor_Run(t *testing.T) {
+	e := newRemote(t, "secret", startJudge(t, &echoExecutor{}, "secret"))
+	require.True(t, e.IsReady())
+
+	res, err := e.Run(context.Background(), ExecutionRequest{Code: "x", Language: "go", Stdin: "hi", TimeLimit: time.Nanosecond})
+	require.NoError(t, err)
+	assert.Equal(t, ExecutionResult{
+		Stdout:     "HI",
+		TimeUsed:   1500 * time.Millisecond,
+		CPUTime:    time.Second,
+		MemoryUsed: 4096,
+		TimedOut:   true,
+	}, res)
+}
+
+func TestRemoteExecutor_WrongToken(t *testing.T) {
+	e := newRemote(t, "wrong", startJudge(t, &echoExecutor{}, "secret"))
+	assert.False(t, e.IsReady())
+
+	_, err := e.Run(context.Background(), ExecutionRequest{Code: "x", Language: "go"})
+	var appErr *apierr.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apierr.ErrInvalidToken, appErr.ErrorCode)
+}
+
+func TestRemoteExecutor_ExecutorErrorIsReturned(t *testing.T) {
+	e := newRemote(t, "", startJudge(t, &echoExecutor{err: errors.New("boom")}, ""))
+
+	_, err := e.Run(context.Background(), ExecutionRequest{Code: "x", Language: "go"})
+	assert.ErrorContains(t, err, "boom")
+}
+
+func TestRemoteExecutor_SkipsOverloadedAndDeadWorkers(t *testing.T) {
+	overloaded := &echoExecutor{err: apierr.New(apierr.ErrExecutorOverloaded, "busy")}
+	healthy := &echoExecutor{}
+	dead := httptest.NewServer(nil)
+	dead.Close()
+
+	e := newRemote(t, "", dead.URL, startJudge(t, overloaded, ""), startJudge(t, healthy, ""))
+	for range 6 {
+		res, err := e.Run(context.Background(), ExecutionRequest{Code: "x", Language: "go", Stdin: "a"})
+		require.NoError(t, err)
+		assert.Equal(t, "A", res.Stdout)
+	}
+	assert.Equal(t, int32(6), healthy.runs.Load())
+}
+
+func TestRemoteExecutor_AllOverloaded(t *testing.T) {
+	busy := &echoExecutor{err: apierr.New(apierr.ErrExecutorOverloaded, "busy")}
+	e := newRemote(t, "", startJudge(t, busy, ""), startJudge(t, busy, ""))
+
+	_, err := e.Run(context.Background(), ExecutionRequest{Code: "x", Language: "go"})
+	var appErr *apierr.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apierr.ErrExecutorOverloaded, appErr.ErrorCode)
+	assert.Equal(t, int32(2), busy.runs.Load())
+}
+
+func TestRemoteExecutor_RoundRobin(t *testing.T) {
+	a, b := &echoExecutor{}, &echoExecutor{}
+	e := newRemote(t, "", startJudge(t, a, ""), startJudge(t, b, ""))
+
+	for range 10 {
+		_, err := e.Run(context.Background(), ExecutionRequest{Code: "x", Language: "go"})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(5), a.runs.Load())
+	assert.Equal(t, int32(5), b.runs.Load())
+}
+
+func TestRemoteExecutor_RunBatch(t *testing.T) {
+	worker := &echoExecutor{}
+	e := newRemote(t, "", startJudge(t, worker, ""))
+
+	var outputs []string
+	err := e.RunBatch(context.Background(), BatchRequest{
+		Code:     "x",
+		Language: "go",
+		Inputs:   []string{"a", "b", "c", "d"},
+	}, func(i int, res ExecutionResult) bool {
+		assert.Equal(t, len(outputs), i)
+		outputs = append(outputs, res.Stdout)
+		return i < 1
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A", "B"}, outputs)
+}
+
+func TestRemoteExecutor_RunBatchLargeOutput(t *testing.T) {
+	e := newRemote(t, "", startJudge(t, &echoExecutor{}, ""))
+	input := strings.Repeat("a", 2*maxBatchLineSize)
+
+	var output string
+	err := e.RunBatch(context.Background(), BatchRequest{
+		Code:        "x",
+		Language:    "go",
+		Inputs:      []string{input},
+		OutputLimit: len(input),
+	}, func(_ int, res ExecutionResult) bool {
+		output = res.Stdout
+		return true
+	})
+	require.NoError(t, err)
+	assert.Equal(t, strings.ToUpper(input), output)
+}
+
+func TestRemoteExecutor_RunBatchError(t *testing.T) {
+	e := newRemote(t, "", startJudge(t, &echoExecutor{err: errors.New("boom")}, ""))
+
+	err := e.RunBatch(context.Background(), BatchRequest{Code: "x", Language: "go", Inputs: []string{"a"}},
+		func(int, ExecutionResult) bool { return true })
+	assert.ErrorContains(t, err, "boom")
+}
+
+func TestNewRemoteExecutor_RequiresWorkers(t *testing.T) {
+	_, err := NewRemoteExecutor(RemoteConfig{})
+	assert.Error(t, err)
+}
+
+func TestRemoteExecutor_RunStream(t *testing.T) {
+	e := newRemote(t, "", startJudge(t, &echoExecutor{}, ""))
+
+	var chunks []OutputChunk
+	res, err := e.RunStream(context.Background(), ExecutionRequest{Code: "x", Language: "go", Stdin: "hi"},
+		func(c OutputChunk) { chunks = append(chunks, c) })
+	require.NoError(t, err)
+	assert.Equal(t, "HI", res.Stdout)
+	assert.Equal(t, []OutputChunk{{Stream: StreamStdout, Data: "HI"}}, chunks)
+}
+
+func TestRemoteExecutor_RunStreamError(t *testing.T) {
+	e := newRemote(t, "", startJudge(t, &echoExecutor{err: errors.New("boom")}, ""))
+
+	_, err := e.RunStream(context.Background(), ExecutionRequest{Code: "x", Language: "go"}, func(OutputChunk) {})
+	assert.ErrorContains(t, err, "boom")
+}
+
+func TestRemoteExecutor_RunInteractive(t *testing.T) {
+	e := newRemote(t, "", startJudge(t, newShellExecutor(t), ""))
+
+	var results []InteractiveResult
+	err := e.RunInteractive(context.Background(), InteractiveRequest{
+		Code:       `read n; echo $((n * 2))`,
+		Language:   "sh",
+		Interactor: Interactor{Code: doublingInteractor, Language: "sh"},
+		Tests:      []map[string]string{{"input.txt": "3\n"}, {"input.txt": "4\n"}},
+	}, func(i int, res InteractiveResult) bool {
+		assert.Equal(t, len(results), i)
+		results = append(results, res)
+		return true
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "8\n", results[1].Program.Stdout)
+	assert.Equal(t, 0, results[1].Interactor.ExitCode)
+}
+
+func TestRemoteExecutor_RunInteractiveUnsupported(t *testing.T) {
+	e := newRemote(t, "", startJudge(t, &echoExecutor{}, ""))
+
+	err := e.RunInteractive(context.Background(), InteractiveRequest{Code: "x", Language: "go", Tests: []map[string]string{{}}},
+		func(int, InteractiveResult) bool { return true })
+	assert.ErrorContains(t, err, ErrInteractiveUnsupported.Error())
+}
@@ -0,0 +1,122 @@
+package executor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	runStatsMarker = "__bytebattle_stats"
+	rusageFile     = "/tmp/bb_rusage"
+	timesFile      = "/tmp/bb_times"
+)
+
+var timesRe = regexp.MustCompile(`(\d+)m([\d.]+)s`)
+
+// runStats is the resource usage of a run reported by the withRunStats wrapper.
+type runStats struct {
+	maxRSSKb int64
+	cpuTime  time.Duration
+	oomKills int64
+	// oomKnown is false when the cgroup memory.events file is unavailable
+	// (cgroup v1) and OOM kills can only be guessed from the exit code.
+	oomKnown bool
+}
+
+// withRunStats wraps cmd so that after it finishes the shell appends a stats
+// trailer to stderr:
+//
+//	__bytebattle_stats rusage=<kb>:<user>:<sys> times_before=<t> times_after=<t> oom_before=<n> oom_after=<n>
+//
+// Peak memory and CPU time come from /usr/bin/time when the image has it (GNU
+// time and BusyBox both support -f). Without it CPU time is taken from the
+// shell's times builtin, which reports the CPU time of reaped children and
+// has to run in the top-level shell, not in a $(...) subshell. OOM kills are
+// counted from the container's cgroup memory.events. The exit code of cmd is
+// preserved.
+func withRunStats(cmd string) string {
+	return fmt.Sprintf(`k() { sed -n 's/^oom_kill //p' /sys/fs/cgroup/memory.events 2>/dev/null; }
+o=$(k)
+rm -f %[1]s
+times > %[2]s.0
+if [ -x /usr/bin/time ]; then /usr/bin/time -f %%M:%%U:%%S -o %[1]s %[3]s; else %[3]s; fi
+rc=$?
+times > %[2]s.1
+printf '\n%[4]s rusage=%%s times_before=%%s times_after=%%s oom_before=%%s oom_after=%%s\n' "$(tail -n 1 %[1]s 2>/dev/null)" "$(tail -n 1 %[2]s.0 | tr -d ' ')" "$(tail -n 1 %[2]s.1 | tr -d ' ')" "$o" "$(k)" >&2
+exit $rc`, rusageFile, timesFile, cmd, runStatsMarker)
+}
+
+// parseRunStats extracts the trailer written by withRunStats and removes it
+// from stderr. The last marker wins, so a program cannot spoof the stats by
+// printing the marker itself.
+func parseRunStats(stderr *string) (runStats, bool) {
+	idx := strings.LastIndex(*stderr, "\n"+runStatsMarker+" ")
+	if idx < 0 {
+		return runStats{}, false
+	}
+	line := (*stderr)[idx+1:]
+	*stderr = (*stderr)[:idx]
+
+	fields := map[string]string{}
+	for _, f := range strings.Fields(strings.TrimPrefix(line, runStatsMarker)) {
+		if k, v, ok := strings.Cut(f, "="); ok {
+			fields[k] = v
+		}
+	}
+
+	var stats runStats
+	if rusage := strings.Split(fields["rusage"], ":"); len(rusage) == 3 {
+		if kb, err := strconv.ParseInt(rusage[0], 10, 64); err == nil && kb > 0 {
+			stats.maxRSSKb = kb
+		}
+		user, errUser := strconv.ParseFloat(rusage[1], 64)
+		sys, errSys := strconv.ParseFloat(rusage[2], 64)
+		if errUser == nil && errSys == nil {
+			stats.cpuTime = secondsToDuration(user + sys)
+		}
+	}
+	if stats.cpuTime == 0 {
+		before, okBefore := parseTimes(fields["times_before"])
+		after, okAfter := parseTimes(fields["times_after"])
+		if okBefore && okAfter && after > before {
+			stats.cpuTime = after - before
+		}
+	}
+	before, errBefore := strconv.ParseInt(fields["oom_before"], 10, 64)
+	after, errAfter := strconv.ParseInt(fields["oom_after"], 10, 64)
+	if errBefore == nil && errAfter == nil {
+		stats.oomKills = after - before
+		stats.oomKnown = true
+	}
+	return stats, true
+}
+
+// parseTimes sums the user and system time from the children line of the
+// times builtin with spaces removed, e.g. "0m0.120000s0m0.010000s". Shells
+// differ in the number of decimals, so any "<m>m<s>s" pair is accepted.
+func parseTimes(s string) (time.Duration, bool) {
+	matches := timesRe.FindAllStringSubmatch(s, -1)
+	if len(matches) != 2 {
+		return 0, false
+	}
+	var total float64
+	for _, m := range matches {
+		mins, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return 0, false
+		}
+		secs, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			return 0, false
+		}
+		total += mins*60 + secs
+	}
+	return secondsToDuration(total), true
+}
+
+func secondsToDuration(secs float64) time.Duration {
+	return time.Duration(secs * float64(time.Second)).Round(time.Millisecond)
+}
@@ -0,0 +1,126 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRunStats_PreservesCommandAndExitCode(t *testing.T) {
+	cmd := withRunStats("timeout 2s ./solution < input.txt")
+	assert.Contains(t, cmd, "/usr/bin/time -f %M:%U:%S -o /tmp/bb_rusage timeout 2s ./solution < input.txt;")
+	assert.Contains(t, cmd, "else timeout 2s ./solution < input.txt; fi")
+	assert.Contains(t, cmd, "exit $rc")
+}
+
+func TestParseRunStats(t *testing.T) {
+	stderr := "warning\n" + runStatsMarker + " rusage=1:0:0 oom_before=0 oom_after=0\nmore\n" +
+		runStatsMarker + " rusage=20480:0.01:0.00 oom_before=2 oom_after=3\n"
+
+	stats, ok := parseRunStats(&stderr)
+	require.True(t, ok)
+	assert.Equal(t, int64(20480), stats.maxRSSKb)
+	assert.True(t, stats.oomKnown)
+	assert.Equal(t, int64(1), stats.oomKills)
+	assert.Equal(t, "warning\n"+runStatsMarker+" rusage=1:0:0 oom_before=0 oom_after=0\nmore", stderr, "only the last trailer is ours")
+}
+
+func TestParseRunStats_MissingValues(t *testing.T) {
+	stderr := "\n" + runStatsMarker + " rusage= oom_before= oom_after=\n"
+
+	stats, ok := parseRunStats(&stderr)
+	require.True(t, ok)
+	assert.Zero(t, stats.maxRSSKb)
+	assert.False(t, stats.oomKnown)
+	assert.Empty(t, stderr)
+}
+
+func TestParseRunStats_NoTrailer(t *testing.T) {
+	stderr := "boom"
+	_, ok := parseRunStats(&stderr)
+	assert.False(t, ok)
+	assert.Equal(t, "boom", stderr)
+}
+
+func hijackedStderr(data string) dockertypes.HijackedResponse {
+	var buf bytes.Buffer
+	_, _ = stdcopy.NewStdWriter(&buf, stdcopy.Stderr).Write([]byte(data))
+	return hijackedFromString(buf.String())
+}
+
+func TestExecInContainer_ReportsMemoryAndOOM(t *testing.T) {
+	cases := []struct {
+		name      string
+		exitCode  int
+		trailer   string
+		oomKilled bool
+	}{
+		{"oom counted by cgroup", 137, "rusage=65536:0.10:0.00 oom_before=0 oom_after=1", true},
+		{"sigkill without oom", 137, "rusage=65536:0.10:0.00 oom_before=4 oom_after=4", false},
+		{"no cgroup stats", 137, "rusage=65536:0.10:0.00 oom_before= oom_after=", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := &mockDockerClient{
+				containerExecAttachFn: func(_ context.Context, _ string, _ container.ExecStartOptions) (dockertypes.HijackedResponse, error) {
+					return hijackedStderr("Killed\n" + runStatsMarker + " " + tc.trailer + "\n"), nil
+				},
+				containerExecInspectFn: func(_ context.Context, _ string) (container.ExecInspect, error) {
+					return container.ExecInspect{ExitCode: tc.exitCode}, nil
+				},
+			}
+			e := newTestExecutor(mock)
+			cfg := &LangSettings{RunCmd: []string{"./solution"}}
+			res, err := e.execInContainer(context.Background(), "cid", cfg, false, nil, time.Second, runOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, int64(65536*1024), res.MemoryUsed)
+			assert.Equal(t, tc.oomKilled, res.OOMKilled)
+			assert.Equal(t, "Killed", res.Stderr)
+		})
+	}
+}
+
+func TestParseRunStats_CPUTimeFromRusage(t *testing.T) {
+	stderr := "\n" + runStatsMarker + " rusage=2048:0.50:0.02 times_before=0m0.00s0m0.00s times_after=0m0.60s0m0.03s oom_before=0 oom_after=0\n"
+
+	stats, ok := parseRunStats(&stderr)
+	require.True(t, ok)
+	assert.Equal(t, int64(2048), stats.maxRSSKb)
+	assert.Equal(t, 520*time.Millisecond, stats.cpuTime)
+}
+
+func TestParseRunStats_CPUTimeFromTimesBuiltin(t *testing.T) {
+	// Without /usr/bin/time the rusage field is empty and the delta of the
+	// children line of the times builtin is used instead.
+	stderr := "\n" + runStatsMarker + " rusage= times_before=0m0.010000s0m0.000000s times_after=0m1.250000s0m0.040000s oom_before=0 oom_after=0\n"
+
+	stats, ok := parseRunStats(&stderr)
+	require.True(t, ok)
+	assert.Zero(t, stats.maxRSSKb)
+	assert.Equal(t, 1280*time.Millisecond, stats.cpuTime)
+}
+
+func TestParseTimes(t *testing.T) {
+	cases := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"0m0.12s0m0.01s", 130 * time.Millisecond, true},
+		{"1m2.500000s0m0.500000s", 63 * time.Second, true},
+		{"0m0.12s", 0, false},
+		{"", 0, false},
+	}
+	for _, tc := range cases {
+		got, ok := parseTimes(tc.in)
+		assert.Equal(t, tc.ok, ok, tc.in)
+		assert.Equal(t, tc.want, got, tc.in)
+	}
+}
@@ -0,0 +1,202 @@
+package executor
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	SandboxNsjail = "nsjail"
+	SandboxBwrap  = "bwrap"
+	SandboxNone   = "none"
+
+	// maxFileSizeBlocks caps files written by a program (ulimit -f). Shells
+	// disagree on whether the unit is 512 or 1024 bytes: 64-128MB.
+	maxFileSizeBlocks = 128 * 1024
+	// maxProcesses caps the processes and threads of the user the programs
+	// run as, which stops fork bombs. The limit is per user rather than per
+	// program, so it leaves room for the judge and the other runs.
+	maxProcesses = 512
+
+	// limitsFailedExit and limitsFailedMessage mark a run whose resource
+	// limits could not be set; it is failed rather than run without them.
+	// The message ends with a token of the run, so that a program cannot
+	// fake the failure.
+	limitsFailedExit    = 125
+	limitsFailedMessage = "bytebattle: cannot set resource limits"
+)
+
+// sandboxPaths are the host paths visible, read-only, inside the sandbox:
+// enough for the toolchains installed by the system package manager or
+// under /usr/local. The Java packages of Debian keep part of the JDK's
+// configuration in /etc/java-*.
+var sandboxPaths = []string{"/usr", "/bin", "/sbin", "/lib", "/lib32", "/lib64", "/etc/alternatives", "/etc/java-*"}
+
+// sandbox wraps commands of the process backend in an isolation tool.
+type sandbox struct {
+	kind string
+	path string
+	args []string
+	// mounts are the host paths that exist of sandboxPaths and the
+	// configured extra paths, with the targets of those that are symlinks.
+	mounts []sandboxMount
+}
+
+type sandboxMount struct {
+	path string
+	// link is the target when path is a symlink, such as /bin -> usr/bin
+	// on merged-/usr systems.
+	link string
+}
+
+// resolveSandbox finds the sandbox binary. An empty name picks nsjail, then
+// bwrap, and falls back to SandboxNone when neither is installed. paths are
+// mounted read-only in addition to sandboxPaths.
+func resolveSandbox(name string, args, paths []string) (sandbox, error) {
+	switch name {
+	case SandboxNone:
+		return sandbox{kind: SandboxNone}, nil
+	case SandboxNsjail, SandboxBwrap:
+		path, err := exec.LookPath(name)
+		if err != nil {
+			return sandbox{}, fmt.Errorf("sandbox %s: %w", name, err)
+		}
+		return sandbox{kind: name, path: path, args: args, mounts: findMounts(append(sandboxPaths, paths...))}, nil
+	case "":
+		for _, kind := range []string{SandboxNsjail, SandboxBwrap} {
+			if path, err := exec.LookPath(kind); err == nil {
+				return sandbox{kind: kind, path: path, args: args, mounts: findMounts(append(sandboxPaths, paths...))}, nil
+			}
+		}
+		return sandbox{kind: SandboxNone}, nil
+	default:
+		return sandbox{}, fmt.Errorf("unknown sandbox %q", name)
+	}
+}
+
+// findMounts expands the glob patterns in paths and keeps the paths that
+// exist: bind-mounting a missing path fails the whole run.
+func findMounts(paths []string) []sandboxMount {
+	var mounts []sandboxMount
+	for _, pattern := range paths {
+		matches, _ := filepath.Glob(pattern)
+		for _, path := range matches {
+			info, err := os.Lstat(path)
+			if err != nil {
+				continue
+			}
+			m := sandboxMount{path: path}
+			if info.Mode()&os.ModeSymlink != 0 {
+				if m.link, err = os.Readlink(path); err != nil {
+					continue
+				}
+			}
+			mounts = append(mounts, m)
+		}
+	}
+	return mounts
+}
+
+// command returns the argv that runs argv in dir inside the sandbox with the
+// given CPU time (seconds) and data size (KB) limits; zero means unlimited.
+// token identifies the run in the message of limitsFailed.
+//
+// Both nsjail and bwrap see only the toolchain paths, read-only, and dir,
+// the only writable one, with a private /tmp, no network and a fresh PID
+// namespace. Resource limits are applied with ulimit inside the sandbox so
+// they also hold without one.
+func (s sandbox) command(dir string, cpuSeconds, dataKb int64, token string, argv []string) []string {
+	limited := append([]string{"/bin/sh", "-c", limitScript(cpuSeconds, dataKb, token), "sh"}, argv...)
+
+	var prefix []string
+	switch s.kind {
+	case SandboxBwrap:
+		prefix = []string{s.path}
+		for _, m := range s.mounts {
+			if m.link != "" {
+				prefix = append(prefix, "--symlink", m.link, m.path)
+			} else {
+				prefix = append(prefix, "--ro-bind", m.path, m.path)
+			}
+		}
+		prefix = append(prefix,
+			"--dev", "/dev",
+			"--proc", "/proc",
+			"--tmpfs", "/tmp",
+			"--bind", dir, dir,
+			"--chdir", dir,
+			"--unshare-all",
+			"--die-with-parent",
+			"--new-session",
+			"--cap-drop", "ALL",
+		)
+	case SandboxNsjail:
+		// Without --chroot nsjail starts from an empty tmpfs root.
+		prefix = []string{s.path, "--mode", "o", "--quiet"}
+		for _, m := range s.mounts {
+			if m.link != "" {
+				prefix = append(prefix, "--symlink", m.link+":"+m.path)
+			} else {
+				prefix = append(prefix, "--bindmount_ro", m.path)
+			}
+		}
+		for _, dev := range []string{"/dev/null", "/dev/zero", "/dev/urandom"} {
+			prefix = append(prefix, "--bindmount_ro", dev)
+		}
+		prefix = append(prefix,
+			"--tmpfsmount", "/tmp",
+			"--bindmount", dir,
+			"--cwd", dir,
+			"--keep_env",
+			"--time_limit", "0",
+			"--rlimit_as", "inf",
+			"--rlimit_cpu", "inf",
+			"--rlimit_fsize", "inf",
+			"--rlimit_nofile", "64",
+			"--rlimit_nproc", strconv.Itoa(maxProcesses),
+		)
+	default:
+		return limited
+	}
+	prefix = append(prefix, s.args...)
+	prefix = append(prefix, "--")
+	return append(prefix, limited...)
+}
+
+// limitScript sets the limits and runs the command, or fails with
+// limitsFailedExit when a limit cannot be set. The process limit is -u in
+// bash and -p in dash.
+func limitScript(cpuSeconds, dataKb int64, token string) string {
+	var limits []string
+	if cpuSeconds > 0 {
+		limits = append(limits, fmt.Sprintf("ulimit -t %d", cpuSeconds))
+	}
+	if dataKb > 0 {
+		limits = append(limits, fmt.Sprintf("ulimit -d %d", dataKb))
+	}
+	limits = append(limits,
+		fmt.Sprintf("ulimit -f %d", maxFileSizeBlocks),
+		fmt.Sprintf("{ ulimit -u %d 2>/dev/null || ulimit -p %d; }", maxProcesses, maxProcesses),
+	)
+	return fmt.Sprintf(`%s || { echo "%s %s" >&2; exit %d; }; exec "$@"`,
+		strings.Join(limits, " && "), limitsFailedMessage, token, limitsFailedExit)
+}
+
+// limitsFailed reports whether the command of the run with token exited
+// because limitScript could not set its limits.
+func limitsFailed(exitCode int, stderr, token string) bool {
+	return exitCode == limitsFailedExit && strings.Contains(stderr, limitsFailedMessage+" "+token)
+}
+
+// newRunToken returns a random token for command.
+func newRunToken() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,174 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+
+	// maxStreamedOutput bounds the output streamed from one run. The result
+	// itself is still truncated to maxLogSize.
+	maxStreamedOutput = 1024 * 1024
+)
+
+// OutputChunk is a piece of a program's stdout or stderr.
+type OutputChunk struct {
+	Stream string `json:"stream"`
+	Data   string `json:"data"`
+}
+
+// StreamExecutor is implemented by executors that can report a program's
+// output while it runs. onOutput is called with chunks of stdout and stderr
+// in the order they were read, never concurrently and never after RunStream
+// returns. Compiler output is not streamed; it is part of the result as in
+// Run.
+type StreamExecutor interface {
+	RunStream(ctx context.Context, req ExecutionRequest, onOutput func(OutputChunk)) (ExecutionResult, error)
+}
+
+// RunStream uses exec's StreamExecutor implementation when available and
+// otherwise falls back to Run, reporting the output once the program ends.
+func RunStream(ctx context.Context, exec Executor, req ExecutionRequest, onOutput func(OutputChunk)) (ExecutionResult, error) {
+	if s, ok := exec.(StreamExecutor); ok {
+		return s.RunStream(ctx, req, onOutput)
+	}
+	res, err := exec.Run(ctx, req)
+	if err != nil || res.CompileFailed {
+		return res, err
+	}
+	if res.Stdout != "" {
+		onOutput(OutputChunk{Stream: StreamStdout, Data: res.Stdout})
+	}
+	if res.Stderr != "" {
+		onOutput(OutputChunk{Stream: StreamStderr, Data: res.Stderr})
+	}
+	return res, nil
+}
+
+// outputStreamer passes output to an onOutput function. It serializes the
+// calls, which may come from one goroutine per stream, stops after
+// maxStreamedOutput bytes and drops everything once closed, so that a run
+// still finishing on a worker cannot call onOutput after RunStream returned.
+// A nil *outputStreamer streams nothing.
+type outputStreamer struct {
+	mu       sync.Mutex
+	onOutput func(OutputChunk)
+	left     int
+	closed   bool
+}
+
+func newOutputStreamer(onOutput func(OutputChunk)) *outputStreamer {
+	return &outputStreamer{onOutput: onOutput, left: maxStreamedOutput}
+}
+
+func (s *outputStreamer) emit(stream string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed || s.left <= 0 || len(data) == 0 {
+		return
+	}
+	if len(data) > s.left {
+		data = data[:s.left]
+	}
+	s.left -= len(data)
+	s.onOutput(OutputChunk{Stream: stream, Data: string(data)})
+}
+
+func (s *outputStreamer) close() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+}
+
+// writer returns a writer that writes to w and streams what is written as
+// stream. It returns w itself when s is nil.
+func (s *outputStreamer) writer(stream string, w io.Writer) io.Writer {
+	if s == nil {
+		return w
+	}
+	return io.MultiWriter(w, s.sink(stream))
+}
+
+// sink returns a writer that streams what is written to it as stream.
+func (s *outputStreamer) sink(stream string) io.Writer {
+	return &streamWriter{s: s, stream: stream}
+}
+
+// streamWriter emits what is written to it, holding back a trailing
+// incomplete UTF-8 sequence until the rest of it arrives.
+type streamWriter struct {
+	s       *outputStreamer
+	stream  string
+	pending []byte
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	data := append(w.pending, p...)
+	n := len(data) - incompleteRuneSuffix(data)
+	w.s.emit(w.stream, data[:n])
+	w.pending = append([]byte(nil), data[n:]...)
+	return len(p), nil
+}
+
+// incompleteRuneSuffix returns the length of the incomplete UTF-8 sequence
+// data ends with, 0 if there is none.
+func incompleteRuneSuffix(data []byte) int {
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		c := data[len(data)-i]
+		if utf8.RuneStart(c) {
+			if utf8.FullRune(data[len(data)-i:]) {
+				return 0
+			}
+			return i
+		}
+	}
+	return 0
+}
+
+// statsTrailerFilter passes stderr through to w up to the trailer written by
+// withRunStats, holding back anything that may turn out to be its start.
+// Should the program print the marker itself the rest of its stderr is not
+// streamed; it is still part of the result.
+type statsTrailerFilter struct {
+	w    io.Writer
+	held []byte
+	done bool
+}
+
+func (f *statsTrailerFilter) Write(p []byte) (int, error) {
+	if f.done {
+		return len(p), nil
+	}
+	marker := []byte("\n" + runStatsMarker)
+	data := append(f.held, p...)
+	if i := bytes.Index(data, marker); i >= 0 {
+		f.done = true
+		f.held = nil
+		_, _ = f.w.Write(data[:i])
+		return len(p), nil
+	}
+	n := len(data) - markerPrefixSuffix(data, marker)
+	_, _ = f.w.Write(data[:n])
+	f.held = append([]byte(nil), data[n:]...)
+	return len(p), nil
+}
+
+// markerPrefixSuffix returns the length of the longest suffix of data that is
+// a proper prefix of marker.
+func markerPrefixSuffix(data, marker []byte) int {
+	for n := min(len(data), len(marker)-1); n > 0; n-- {
+		if bytes.HasSuffix(data, marker[:n]) {
+			return n
+		}
+	}
+	return 0
+}
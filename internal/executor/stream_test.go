@@ -0,0 +1,151 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collect returns an onOutput function appending to chunks and a function
+// joining what was streamed per stream.
+func collect() (onOutput func(OutputChunk), joined func(stream string) string) {
+	var chunks []OutputChunk
+	onOutput = func(c OutputChunk) { chunks = append(chunks, c) }
+	joined = func(stream string) string {
+		var sb strings.Builder
+		for _, c := range chunks {
+			if c.Stream == stream {
+				sb.WriteString(c.Data)
+			}
+		}
+		return sb.String()
+	}
+	return onOutput, joined
+}
+
+func TestStatsTrailerFilter(t *testing.T) {
+	trailer := "\n" + runStatsMarker + " rusage=1:0:0 oom_before=0 oom_after=0\n"
+	cases := []struct {
+		name   string
+		writes []string
+		want   string
+	}{
+		{"trailer in one write", []string{"oops\n" + trailer}, "oops\n"},
+		{"trailer split", []string{"oops\n\n__byte", "battle_stats rusage=", "1:0:0\n"}, "oops\n"},
+		{"partial marker from the program", []string{"a\n__byte", "x\n", trailer}, "a\n__bytex\n"},
+		{"no trailer yet", []string{"line\n"}, "line"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			f := &statsTrailerFilter{w: &out}
+			for _, w := range tc.writes {
+				n, err := f.Write([]byte(w))
+				require.NoError(t, err)
+				assert.Equal(t, len(w), n)
+			}
+			assert.Equal(t, tc.want, out.String())
+		})
+	}
+}
+
+func TestStreamWriter_KeepsRunesWhole(t *testing.T) {
+	onOutput, joined := collect()
+	var chunks int
+	s := newOutputStreamer(func(c OutputChunk) {
+		chunks++
+		assert.True(t, utf8.ValidString(c.Data), "chunk %q splits a rune", c.Data)
+		onOutput(c)
+	})
+	w := s.sink(StreamStdout)
+	data := []byte("привет")
+	for i := range data {
+		_, _ = w.Write(data[i : i+1])
+	}
+	assert.Equal(t, "привет", joined(StreamStdout))
+	assert.Equal(t, 6, chunks)
+}
+
+func TestOutputStreamer_CapAndClose(t *testing.T) {
+	onOutput, joined := collect()
+	s := newOutputStreamer(onOutput)
+	w := s.sink(StreamStdout)
+
+	_, _ = w.Write(bytes.Repeat([]byte("x"), maxStreamedOutput-1))
+	_, _ = w.Write([]byte("yz"))
+	assert.Len(t, joined(StreamStdout), maxStreamedOutput)
+
+	s = newOutputStreamer(onOutput)
+	s.close()
+	_, _ = s.sink(StreamStderr).Write([]byte("late"))
+	assert.Empty(t, joined(StreamStderr))
+}
+
+func TestRunStream_FallsBackToRun(t *testing.T) {
+	onOutput, joined := collect()
+	res, err := RunStream(context.Background(), &echoExecutor{}, ExecutionRequest{Stdin: "hi"}, onOutput)
+	require.NoError(t, err)
+	assert.Equal(t, "HI", res.Stdout)
+	assert.Equal(t, "HI", joined(StreamStdout))
+}
+
+func TestProcessExecutor_RunStream(t *testing.T) {
+	e := newShellExecutor(t)
+
+	onOutput, joined := collect()
+	res, err := e.RunStream(context.Background(), ExecutionRequest{
+		Code:     `echo one; echo warn >&2; sleep 0.1; echo two`,
+		Language: "sh",
+	}, onOutput)
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\n", res.Stdout)
+	assert.Equal(t, "one\ntwo\n", joined(StreamStdout))
+	assert.Equal(t, "warn\n", joined(StreamStderr))
+}
+
+func TestProcessExecutor_RunStreamSkipsCompilerOutput(t *testing.T) {
+	e := newShellExecutor(t)
+
+	var chunks []OutputChunk
+	res, err := e.RunStream(context.Background(), ExecutionRequest{Code: "if then fi (", Language: "sh"},
+		func(c OutputChunk) { chunks = append(chunks, c) })
+	require.NoError(t, err)
+	assert.True(t, res.CompileFailed)
+	assert.Empty(t, chunks)
+}
+
+func TestExecInContainer_StreamsOutputWithoutTrailer(t *testing.T) {
+	var frames bytes.Buffer
+	_, _ = stdcopy.NewStdWriter(&frames, stdcopy.Stdout).Write([]byte("out\n"))
+	_, _ = stdcopy.NewStdWriter(&frames, stdcopy.Stderr).Write([]byte("err\n"))
+	_, _ = stdcopy.NewStdWriter(&frames, stdcopy.Stderr).Write([]byte("\n" + runStatsMarker + " rusage=1024:0.01:0.00 oom_before=0 oom_after=0\n"))
+	mock := &mockDockerClient{
+		containerExecAttachFn: func(_ context.Context, _ string, _ container.ExecStartOptions) (dockertypes.HijackedResponse, error) {
+			return hijackedFromString(frames.String()), nil
+		},
+		containerExecInspectFn: func(_ context.Context, _ string) (container.ExecInspect, error) {
+			return container.ExecInspect{ExitCode: 0}, nil
+		},
+	}
+	e := newTestExecutor(mock)
+	cfg := &LangSettings{RunCmd: []string{"./solution"}}
+
+	onOutput, joined := collect()
+	stream := newOutputStreamer(onOutput)
+	res, err := e.execInContainer(context.Background(), "cid", cfg, false, nil, time.Second, runOptions{stream: stream})
+	stream.close()
+	require.NoError(t, err)
+	assert.Equal(t, "out\n", res.Stdout)
+	assert.Equal(t, "err\n", res.Stderr)
+	assert.Equal(t, "out\n", joined(StreamStdout))
+	assert.Equal(t, "err\n", joined(StreamStderr))
+}
@@ -85,22 +85,35 @@ func TestDrainQueueWithShutdown(t *testing.T) {
 func TestBuildShellCommand_WrapsRunWithTimeout(t *testing.T) {
 	e := newTestExecutor(&mockDockerClient{})
 	cfg := &LangSettings{RunCmd: []string{"python", "main.py"}}
-	assert.Equal(t, "timeout 10s python main.py", e.buildShellCommand(cfg, false, 10*time.Second))
+	assert.Equal(t, "timeout 10s python main.py", e.buildShellCommand(cfg, false, nil, 10*time.Second))
 }
 
-func TestBuildShellCommand_CompileAndRun(t *testing.T) {
+func TestBuildShellCommand_DoesNotCompile(t *testing.T) {
 	e := newTestExecutor(&mockDockerClient{})
 	cfg := &LangSettings{
 		CompileCmd: []string{"g++", "-O2", "main.cpp", "-o", "main"},
 		RunCmd:     []string{"./main"},
 	}
-	assert.Equal(t, "g++ -O2 main.cpp -o main && timeout 5s ./main", e.buildShellCommand(cfg, false, 5*time.Second))
+	assert.Equal(t, "timeout 5s ./main", e.buildShellCommand(cfg, false, nil, 5*time.Second))
+}
+
+func TestBuildShellCommand_FractionalTimeout(t *testing.T) {
+	e := newTestExecutor(&mockDockerClient{})
+	cfg := &LangSettings{RunCmd: []string{"./main"}}
+	assert.Equal(t, "timeout 1.5s ./main", e.buildShellCommand(cfg, false, nil, 1500*time.Millisecond))
+	assert.Equal(t, "timeout 0.25s ./main", e.buildShellCommand(cfg, false, nil, 250*time.Millisecond))
 }
 
 func TestBuildShellCommand_StdinRedirect(t *testing.T) {
 	e := newTestExecutor(&mockDockerClient{})
 	cfg := &LangSettings{RunCmd: []string{"python", "main.py"}}
-	assert.Equal(t, "timeout 10s python main.py < input.txt", e.buildShellCommand(cfg, true, 10*time.Second))
+	assert.Equal(t, "timeout 10s python main.py < input.txt", e.buildShellCommand(cfg, true, nil, 10*time.Second))
+}
+
+func TestBuildShellCommand_QuotesArgs(t *testing.T) {
+	e := newTestExecutor(&mockDockerClient{})
+	cfg := &LangSettings{RunCmd: []string{"python", "main.py"}}
+	assert.Equal(t, `timeout 10s python main.py 'input.txt' 'it'\''s'`, e.buildShellCommand(cfg, false, []string{"input.txt", "it's"}, 10*time.Second))
 }
 
 func TestCleanWorkDir_CallsCorrectCommand(t *testing.T) {
@@ -119,3 +132,130 @@ func TestCleanWorkDir_CallsCorrectCommand(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, []string{"/bin/sh", "-c", "rm -rf /app/* /tmp/*"}, capturedCmd)
 }
+
+func TestExecInContainer_ClassifiesExitCodes(t *testing.T) {
+	cases := []struct {
+		name                string
+		exitCode            int
+		timedOut, oomKilled bool
+	}{
+		{"clean", 0, false, false},
+		{"runtime error", 1, false, false},
+		{"timeout", 124, true, false},
+		{"oom", 137, false, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := &mockDockerClient{
+				containerExecAttachFn: func(_ context.Context, _ string, _ container.ExecStartOptions) (dockertypes.HijackedResponse, error) {
+					return hijackedFromString(""), nil
+				},
+				containerExecInspectFn: func(_ context.Context, _ string) (container.ExecInspect, error) {
+					return container.ExecInspect{ExitCode: tc.exitCode}, nil
+				},
+			}
+			e := newTestExecutor(mock)
+			cfg := &LangSettings{RunCmd: []string{"./solution"}}
+			res, err := e.execInContainer(context.Background(), "cid", cfg, false, nil, time.Second, runOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tc.timedOut, res.TimedOut)
+			assert.Equal(t, tc.oomKilled, res.OOMKilled)
+			assert.False(t, res.CompileFailed)
+		})
+	}
+}
+
+func TestExecuteWorkItem_CompileErrorSkipsRun(t *testing.T) {
+	var cmds []string
+	mock := &mockDockerClient{
+		containerExecCreateFn: func(_ context.Context, _ string, cfg container.ExecOptions) (container.ExecCreateResponse, error) {
+			cmds = append(cmds, cfg.Cmd[2])
+			return container.ExecCreateResponse{ID: "exec-id"}, nil
+		},
+		containerExecAttachFn: func(_ context.Context, _ string, _ container.ExecStartOptions) (dockertypes.HijackedResponse, error) {
+			return hijackedFromString(""), nil
+		},
+		containerExecInspectFn: func(_ context.Context, _ string) (container.ExecInspect, error) {
+			return container.ExecInspect{ExitCode: 1}, nil
+		},
+	}
+	e := newTestExecutor(mock)
+	cfg := &LangSettings{
+		SourceFile: "main.cpp",
+		CompileCmd: []string{"g++", "main.cpp"},
+		RunCmd:     []string{"./a.out"},
+	}
+
+	res, err := e.executeWorkItem(context.Background(), "cid", ExecutionRequest{Code: "x"}, cfg, runOptions{})
+	require.NoError(t, err)
+	assert.True(t, res.CompileFailed)
+	assert.Equal(t, []string{"timeout 30s g++ main.cpp"}, cmds)
+}
+
+func TestExecuteWorkItem_AppliesScaledLimits(t *testing.T) {
+	var cmds []string
+	var memory []int64
+	mock := &mockDockerClient{
+		containerExecCreateFn: func(_ context.Context, _ string, cfg container.ExecOptions) (container.ExecCreateResponse, error) {
+			cmds = append(cmds, cfg.Cmd[2])
+			return container.ExecCreateResponse{ID: "exec-id"}, nil
+		},
+		containerExecAttachFn: func(_ context.Context, _ string, _ container.ExecStartOptions) (dockertypes.HijackedResponse, error) {
+			return hijackedFromString(""), nil
+		},
+		containerUpdateFn: func(_ context.Context, _ string, cfg container.UpdateConfig) (container.UpdateResponse, error) {
+			assert.Equal(t, cfg.Memory, cfg.MemorySwap)
+			memory = append(memory, cfg.Memory)
+			return container.UpdateResponse{}, nil
+		},
+	}
+	e := newTestExecutor(mock)
+	cfg := &LangSettings{
+		SourceFile:       "Main.java",
+		CompileCmd:       []string{"javac", "Main.java"},
+		RunCmd:           []string{"java", "Main"},
+		TimeMultiplier:   2,
+		MemoryMultiplier: 1.5,
+	}
+
+	_, err := e.executeWorkItem(context.Background(), "cid", ExecutionRequest{
+		Code:        "x",
+		TimeLimit:   time.Second,
+		MemoryLimit: 64 * 1024 * 1024,
+	}, cfg, runOptions{})
+	require.NoError(t, err)
+	require.Len(t, cmds, 2)
+	assert.Equal(t, "timeout 30s javac Main.java", cmds[0])
+	assert.Contains(t, cmds[1], "timeout 2s java Main")
+	assert.Equal(t, []int64{96 * 1024 * 1024}, memory)
+}
+
+func TestProcessNextItem_RestoresMemoryLimit(t *testing.T) {
+	var memory []int64
+	mock := &mockDockerClient{
+		containerExecAttachFn: func(_ context.Context, _ string, _ container.ExecStartOptions) (dockertypes.HijackedResponse, error) {
+			return hijackedFromString(""), nil
+		},
+		containerUpdateFn: func(_ context.Context, _ string, cfg container.UpdateConfig) (container.UpdateResponse, error) {
+			memory = append(memory, cfg.Memory)
+			return container.UpdateResponse{}, nil
+		},
+	}
+	e := newTestExecutor(mock)
+	lp := &langPool{
+		queue:    make(chan workItem, 1),
+		settings: LangSettings{SourceFile: "main.py", RunCmd: []string{"python", "main.py"}, MemoryLimit: 256 * 1024 * 1024},
+	}
+	result := make(chan workResult, 1)
+	lp.queue <- workItem{
+		ctx:    context.Background(),
+		req:    ExecutionRequest{Code: "x", MemoryLimit: 64 * 1024 * 1024},
+		result: result,
+	}
+
+	alive, ok := e.processNextItem("cid", lp)
+	require.True(t, ok)
+	assert.True(t, alive)
+	require.NoError(t, (<-result).err)
+	assert.Equal(t, []int64{64 * 1024 * 1024, 256 * 1024 * 1024}, memory)
+}
@@ -0,0 +1,3 @@
+ALTER TABLE solutions
+    DROP COLUMN IF EXISTS test_results,
+    DROP COLUMN IF EXISTS verdict;
@@ -0,0 +1,4 @@
+ALTER TABLE solutions
+    ADD COLUMN verdict      VARCHAR(3) NOT NULL DEFAULT 'AC'
+        CHECK (verdict IN ('AC', 'WA', 'TLE', 'MLE', 'RE', 'CE')),
+    ADD COLUMN test_results JSONB      NOT NULL DEFAULT '[]';
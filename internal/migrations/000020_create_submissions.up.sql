@@ -0,0 +1,20 @@
+CREATE TABLE submissions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    game_id INTEGER REFERENCES games(id) ON DELETE CASCADE,
+    problem_id TEXT NOT NULL,
+    problem_version_id BIGINT NOT NULL REFERENCES problem_versions(id) ON DELETE RESTRICT,
+    code TEXT NOT NULL,
+    language VARCHAR(20) NOT NULL,
+    verdict VARCHAR(3) NOT NULL
+        CHECK (verdict IN ('AC', 'WA', 'TLE', 'MLE', 'RE', 'CE')),
+    failed_test INTEGER, -- 0-based index of the first failed test
+    execution_time INTEGER, -- в миллисекундах
+    memory_used INTEGER, -- в КБ
+    test_results JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_submissions_game_id ON submissions(game_id, created_at);
+CREATE INDEX idx_submissions_user_id ON submissions(user_id);
+CREATE INDEX idx_submissions_problem_version_id ON submissions(problem_version_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS rating_history;
+ALTER TABLE users ALTER COLUMN rating DROP NOT NULL;
+ALTER TABLE users ALTER COLUMN rating SET DEFAULT 0;
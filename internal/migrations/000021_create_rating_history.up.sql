@@ -0,0 +1,16 @@
+UPDATE users SET rating = 1200 WHERE rating IS NULL OR rating = 0;
+ALTER TABLE users ALTER COLUMN rating SET DEFAULT 1200;
+ALTER TABLE users ALTER COLUMN rating SET NOT NULL;
+
+CREATE TABLE rating_history (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    game_id INTEGER NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    place INTEGER NOT NULL, -- 1-based, equal places are draws
+    old_rating INTEGER NOT NULL,
+    new_rating INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (game_id, user_id)
+);
+
+CREATE INDEX idx_rating_history_user_id ON rating_history(user_id, created_at);
@@ -0,0 +1,25 @@
+CREATE TABLE submission_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    game_id INTEGER NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    problem_index INTEGER NOT NULL, -- the player's problem when the job was queued
+    code TEXT NOT NULL,
+    language VARCHAR(20) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'running', 'done')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    instance_id TEXT NOT NULL, -- server instance that queued or last claimed the job
+    run_after TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    result JSONB, -- message for the submitter, NULL when there is nothing to report
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- A player has at most one submission in flight per game.
+CREATE UNIQUE INDEX idx_submission_jobs_active ON submission_jobs(game_id, user_id) WHERE status <> 'done';
+CREATE INDEX idx_submission_jobs_pending ON submission_jobs(run_after, id) WHERE status = 'pending';
+CREATE INDEX idx_submission_jobs_undelivered ON submission_jobs(game_id, user_id)
+    WHERE status = 'done' AND delivered_at IS NULL;
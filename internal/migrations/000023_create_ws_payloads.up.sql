@@ -0,0 +1,9 @@
+-- Hub messages too large for a NOTIFY payload (8000 bytes) are stored here and
+-- the notification carries the row ID instead.
+CREATE TABLE ws_payloads (
+    id BIGSERIAL PRIMARY KEY,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ws_payloads_created_at ON ws_payloads(created_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS game_events;
+ALTER TABLE games DROP COLUMN IF EXISTS last_event_seq;
@@ -0,0 +1,11 @@
+ALTER TABLE games ADD COLUMN last_event_seq BIGINT NOT NULL DEFAULT 0;
+
+-- Room events broadcast over the game's WebSocket, numbered per game so that a
+-- reconnecting client can ask for the ones it missed.
+CREATE TABLE game_events (
+    game_id INTEGER NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    seq BIGINT NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (game_id, seq)
+);
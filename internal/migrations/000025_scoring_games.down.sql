@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS game_scores;
+ALTER TABLE submissions DROP COLUMN IF EXISTS score;
+ALTER TABLE games DROP COLUMN IF EXISTS mode;
@@ -0,0 +1,15 @@
+ALTER TABLE games
+    ADD COLUMN mode VARCHAR(10) NOT NULL DEFAULT 'race'
+        CHECK (mode IN ('race', 'scoring'));
+
+ALTER TABLE submissions ADD COLUMN score INTEGER;
+
+-- Best score of each player on each problem of a scoring game.
+CREATE TABLE game_scores (
+    game_id INTEGER NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    problem_index INTEGER NOT NULL,
+    score INTEGER NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (game_id, user_id, problem_index)
+);
@@ -0,0 +1,4 @@
+ALTER TABLE problem_versions DROP CONSTRAINT IF EXISTS problem_versions_checker_type_check;
+ALTER TABLE problem_versions
+    ADD CONSTRAINT problem_versions_checker_type_check
+        CHECK (checker_type IN ('diff', 'custom'));
@@ -0,0 +1,4 @@
+ALTER TABLE problem_versions DROP CONSTRAINT problem_versions_checker_type_check;
+ALTER TABLE problem_versions
+    ADD CONSTRAINT problem_versions_checker_type_check
+        CHECK (checker_type IN ('diff', 'custom', 'interactive'));
@@ -0,0 +1,4 @@
+-- The job a submission was judged in. A job retried after its submission was
+-- recorded must not record it again.
+ALTER TABLE submissions ADD COLUMN job_id BIGINT REFERENCES submission_jobs(id) ON DELETE SET NULL;
+CREATE UNIQUE INDEX idx_submissions_job_id ON submissions(job_id);
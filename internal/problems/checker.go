@@ -0,0 +1,149 @@
+package problems
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"bytebattle/internal/executor"
+)
+
+const (
+	CheckerTypeDiff        = "diff"
+	CheckerTypeCustom      = "custom"
+	CheckerTypeInteractive = "interactive"
+
+	checkerTimeLimit = 10 * time.Second
+
+	checkerInputFile  = "input.txt"
+	checkerOutputFile = "output.txt"
+	checkerAnswerFile = "answer.txt"
+)
+
+// Checker is a problem-supplied program that judges contestant output for
+// problems whose answer is not unique. It is run as
+//
+//	<checker> input.txt output.txt answer.txt
+//
+// and reports the verdict through its exit code: 0 accepted, 1 wrong answer,
+// anything else means the checker itself failed.
+type Checker struct {
+	Code     string
+	Language string
+}
+
+type CheckResult struct {
+	Accepted bool
+	Message  string
+}
+
+func checkerType(c *Checker, in *Interactor) string {
+	switch {
+	case in != nil:
+		return CheckerTypeInteractive
+	case c != nil:
+		return CheckerTypeCustom
+	default:
+		return CheckerTypeDiff
+	}
+}
+
+// CheckerType reports the checker_type stored for this problem version.
+func (p *Problem) CheckerType() string {
+	return checkerType(p.Checker, p.Interactor)
+}
+
+// loadChecker returns nil when the problem has no checker/ directory.
+func loadChecker(dir string) (*Checker, error) {
+	code, lang, err := loadProgram(dir, "checker")
+	if err != nil || code == "" {
+		return nil, err
+	}
+	return &Checker{Code: code, Language: lang}, nil
+}
+
+// loadProgram reads the single source file of a helper program such as the
+// checker from dir. It returns an empty code when dir does not exist.
+func loadProgram(dir, what string) (code, lang string, err error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("reading %s/: %w", what, err)
+	}
+
+	var found []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if _, ok := extToLang[strings.ToLower(filepath.Ext(e.Name()))]; ok {
+			found = append(found, e.Name())
+		}
+	}
+	if len(found) == 0 {
+		return "", "", fmt.Errorf("%s/: no %s source found (.py, .go, .cpp, .java)", what, what)
+	}
+	if len(found) > 1 {
+		return "", "", fmt.Errorf("%s/: multiple %s sources found, expected exactly one", what, what)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, found[0]))
+	if err != nil {
+		return "", "", fmt.Errorf("reading %s: %w", what, err)
+	}
+	if len(data) == 0 {
+		return "", "", fmt.Errorf("%s/%s is empty", what, found[0])
+	}
+	return string(data), extToLang[strings.ToLower(filepath.Ext(found[0]))], nil
+}
+
+var checkerArgs = []string{checkerInputFile, checkerOutputFile, checkerAnswerFile}
+
+func checkerFiles(tc TestCase, output string) map[string]string {
+	return map[string]string{
+		checkerInputFile:  tc.Input,
+		checkerOutputFile: output,
+		checkerAnswerFile: tc.Expected,
+	}
+}
+
+// BatchRequest returns the request checking outputs[i] as an answer for
+// tests[i], so the checker is compiled once for all of them.
+func (c *Checker) BatchRequest(tests []TestCase, outputs []string) executor.BatchRequest {
+	req := executor.BatchRequest{
+		Code:      c.Code,
+		Language:  executor.Language(c.Language),
+		Inputs:    make([]string, len(tests)),
+		Args:      checkerArgs,
+		Files:     make([]map[string]string, len(tests)),
+		TimeLimit: checkerTimeLimit,
+	}
+	for i, tc := range tests {
+		req.Files[i] = checkerFiles(tc, outputs[i])
+	}
+	return req
+}
+
+// CheckerResult reads the verdict of a checker run from its exit code.
+func CheckerResult(result executor.ExecutionResult) (CheckResult, error) {
+	msg := strings.TrimSpace(result.Stdout)
+	if msg == "" {
+		msg = strings.TrimSpace(result.Stderr)
+	}
+	switch {
+	case result.CompileFailed:
+		return CheckResult{}, fmt.Errorf("checker does not compile: %s", msg)
+	case result.ExitCode == 0:
+		return CheckResult{Accepted: true, Message: msg}, nil
+	case result.ExitCode == 1:
+		return CheckResult{Accepted: false, Message: msg}, nil
+	default:
+		return CheckResult{}, fmt.Errorf("checker failed with exit code %d: %s", result.ExitCode, msg)
+	}
+}
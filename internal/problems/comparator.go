@@ -0,0 +1,116 @@
+package problems
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	ComparatorLines          = "lines"
+	ComparatorTokens         = "tokens"
+	ComparatorTokensCaseless = "tokens_ci"
+	ComparatorFloat          = "float"
+	ComparatorJSON           = "json"
+
+	DefaultComparatorEpsilon = 1e-6
+	maxComparatorEpsilon     = 1.0
+)
+
+// Comparator is a built-in output comparison mode selected in manifest.json.
+//
+//   - lines: trailing whitespace on each line and trailing blank lines are ignored
+//   - tokens: outputs are compared as whitespace-separated tokens
+//   - tokens_ci: like tokens, but case-insensitive ("YES" == "yes")
+//   - float: like tokens, numeric tokens may differ by an absolute or
+//     relative error of at most Epsilon
+//   - json: outputs are compared as JSON values, numbers by value and within
+//     Epsilon when it is set; the default of function problems
+type Comparator struct {
+	Mode    string
+	Epsilon float64
+}
+
+// OutputComparator returns the comparator declared by the manifest, falling
+// back to line-based comparison, or to JSON for function problems.
+func (m Manifest) OutputComparator() Comparator {
+	c := Comparator{Mode: m.Comparator, Epsilon: m.Epsilon}
+	switch {
+	case c.Mode == "" && m.Function != nil:
+		c.Mode = ComparatorJSON
+	case c.Mode == "":
+		c.Mode = ComparatorLines
+	}
+	if c.Mode == ComparatorFloat && c.Epsilon == 0 {
+		c.Epsilon = DefaultComparatorEpsilon
+	}
+	return c
+}
+
+func validateComparator(m Manifest) error {
+	mode := m.Comparator
+	if mode == "" && m.Function != nil {
+		mode = ComparatorJSON
+	}
+	switch mode {
+	case "", ComparatorLines, ComparatorTokens, ComparatorTokensCaseless:
+		if m.Epsilon != 0 {
+			return fmt.Errorf("manifest.json: epsilon is only allowed with the %q and %q comparators", ComparatorFloat, ComparatorJSON)
+		}
+	case ComparatorFloat, ComparatorJSON:
+		if m.Epsilon < 0 || m.Epsilon > maxComparatorEpsilon || math.IsNaN(m.Epsilon) {
+			return fmt.Errorf("manifest.json: epsilon must be between 0 and %g", maxComparatorEpsilon)
+		}
+	default:
+		return fmt.Errorf("manifest.json: unknown comparator %q (expected %s, %s, %s, %s or %s)",
+			m.Comparator, ComparatorLines, ComparatorTokens, ComparatorTokensCaseless, ComparatorFloat, ComparatorJSON)
+	}
+	return nil
+}
+
+func (c Comparator) Match(actual, expected string) bool {
+	switch c.Mode {
+	case ComparatorTokens:
+		return matchTokens(actual, expected, func(a, b string) bool { return a == b })
+	case ComparatorTokensCaseless:
+		return matchTokens(actual, expected, strings.EqualFold)
+	case ComparatorFloat:
+		return matchTokens(actual, expected, func(a, b string) bool { return matchFloat(a, b, c.Epsilon) })
+	case ComparatorJSON:
+		return matchJSON(actual, expected, c.Epsilon)
+	default:
+		return Match(actual, expected)
+	}
+}
+
+func matchTokens(actual, expected string, eq func(a, b string) bool) bool {
+	a, b := strings.Fields(actual), strings.Fields(expected)
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !eq(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchFloat compares numeric tokens with tolerance eps; non-numeric tokens
+// must match exactly.
+func matchFloat(actual, expected string, eps float64) bool {
+	if actual == expected {
+		return true
+	}
+	want, err := strconv.ParseFloat(expected, 64)
+	if err != nil {
+		return false
+	}
+	got, err := strconv.ParseFloat(actual, 64)
+	if err != nil || math.IsNaN(got) || math.IsInf(got, 0) {
+		return false
+	}
+	diff := math.Abs(got - want)
+	return diff <= eps || diff <= eps*math.Abs(want)
+}
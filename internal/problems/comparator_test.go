@@ -0,0 +1,67 @@
+package problems
+
+import (
+	"testing"
+
+	"bytebattle/internal/executor"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComparator_Match(t *testing.T) {
+	cases := []struct {
+		name     string
+		cmp      Comparator
+		actual   string
+		expected string
+		want     bool
+	}{
+		{"lines trailing whitespace", Comparator{Mode: ComparatorLines}, "1 2  \n", "1 2\n\n", true},
+		{"lines inner whitespace differs", Comparator{Mode: ComparatorLines}, "1  2", "1 2", false},
+		{"tokens inner whitespace", Comparator{Mode: ComparatorTokens}, "1  2\n3", "1 2 3", true},
+		{"tokens count differs", Comparator{Mode: ComparatorTokens}, "1 2", "1 2 3", false},
+		{"tokens case sensitive", Comparator{Mode: ComparatorTokens}, "YES", "yes", false},
+		{"tokens_ci", Comparator{Mode: ComparatorTokensCaseless}, "Yes\nNO", "yes no", true},
+		{"float within absolute error", Comparator{Mode: ComparatorFloat, Epsilon: 1e-6}, "0.3333333", "0.333333333", true},
+		{"float within relative error", Comparator{Mode: ComparatorFloat, Epsilon: 1e-6}, "1000000.5", "1000000", true},
+		{"float outside error", Comparator{Mode: ComparatorFloat, Epsilon: 1e-6}, "0.334", "0.333", false},
+		{"float non-numeric token", Comparator{Mode: ComparatorFloat, Epsilon: 1e-6}, "abc 1.0", "abc 1", true},
+		{"float rejects nan", Comparator{Mode: ComparatorFloat, Epsilon: 1e-6}, "nan", "1", false},
+		{"json formatting", Comparator{Mode: ComparatorJSON}, "[1, 2]\n", "[1,2]", true},
+		{"json numbers by value", Comparator{Mode: ComparatorJSON}, "[2.0,1e2]", "[2,100]", true},
+		{"json element differs", Comparator{Mode: ComparatorJSON}, "[1,3]", "[1,2]", false},
+		{"json strings exact", Comparator{Mode: ComparatorJSON}, `"a b"`, `"a  b"`, false},
+		{"json within error", Comparator{Mode: ComparatorJSON, Epsilon: 1e-6}, "[0.3333333]", "[0.333333333]", true},
+		{"json trailing data", Comparator{Mode: ComparatorJSON}, "1 2", "1", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.cmp.Match(tc.actual, tc.expected))
+		})
+	}
+}
+
+func TestManifest_OutputComparatorDefaults(t *testing.T) {
+	assert.Equal(t, Comparator{Mode: ComparatorLines}, Manifest{}.OutputComparator())
+	assert.Equal(t, Comparator{Mode: ComparatorFloat, Epsilon: DefaultComparatorEpsilon}, Manifest{Comparator: ComparatorFloat}.OutputComparator())
+	assert.Equal(t, Comparator{Mode: ComparatorFloat, Epsilon: 1e-3}, Manifest{Comparator: ComparatorFloat, Epsilon: 1e-3}.OutputComparator())
+	assert.Equal(t, Comparator{Mode: ComparatorJSON}, Manifest{Function: &FunctionSignature{}}.OutputComparator())
+}
+
+func TestValidateComparator(t *testing.T) {
+	assert.NoError(t, validateComparator(Manifest{}))
+	assert.NoError(t, validateComparator(Manifest{Comparator: ComparatorTokensCaseless}))
+	assert.NoError(t, validateComparator(Manifest{Comparator: ComparatorFloat, Epsilon: 1e-9}))
+	assert.NoError(t, validateComparator(Manifest{Function: &FunctionSignature{}, Epsilon: 1e-9}))
+	assert.ErrorContains(t, validateComparator(Manifest{Comparator: "regex"}), "unknown comparator")
+	assert.ErrorContains(t, validateComparator(Manifest{Comparator: ComparatorTokens, Epsilon: 1e-6}), "epsilon is only allowed")
+	assert.ErrorContains(t, validateComparator(Manifest{Comparator: ComparatorFloat, Epsilon: 2}), "epsilon must be between")
+}
+
+func TestRunFailureVerdict(t *testing.T) {
+	assert.Equal(t, Verdict(""), RunFailureVerdict(executor.ExecutionResult{}))
+	assert.Equal(t, VerdictRuntimeError, RunFailureVerdict(executor.ExecutionResult{ExitCode: 1}))
+	assert.Equal(t, VerdictTimeLimit, RunFailureVerdict(executor.ExecutionResult{ExitCode: 124, TimedOut: true}))
+	assert.Equal(t, VerdictMemoryLimit, RunFailureVerdict(executor.ExecutionResult{ExitCode: 137, OOMKilled: true}))
+	assert.Equal(t, VerdictCompileError, RunFailureVerdict(executor.ExecutionResult{ExitCode: 1, CompileFailed: true}))
+}
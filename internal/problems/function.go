@@ -0,0 +1,318 @@
+package problems
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// FunctionSignature declares the function contestants implement in a
+// function problem instead of a whole program, e.g.
+//
+//	"function": {
+//	  "name": "twoSum",
+//	  "params": [{"name": "nums", "type": "int[]"}, {"name": "target", "type": "int"}],
+//	  "returns": "int[]"
+//	}
+//
+// Types are int, long, double, bool and string, and arrays of them written
+// as "int[]" or "int[][]". A test's input holds the arguments as JSON values
+// in order and its expected output the JSON of the result; the harness the
+// contestant's code is wrapped in reads the one and prints the other.
+type FunctionSignature struct {
+	Name    string          `json:"name"`
+	Params  []FunctionParam `json:"params"`
+	Returns string          `json:"returns"`
+}
+
+type FunctionParam struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+const (
+	typeInt    = "int"
+	typeLong   = "long"
+	typeDouble = "double"
+	typeBool   = "bool"
+	typeString = "string"
+
+	maxTypeDims      = 2
+	maxFunctionParam = 10
+)
+
+// valueType is a parsed FunctionParam type: base with dims array levels.
+type valueType struct {
+	base string
+	dims int
+}
+
+func parseValueType(s string) (valueType, error) {
+	t := valueType{base: s}
+	for strings.HasSuffix(t.base, "[]") {
+		t.base = strings.TrimSuffix(t.base, "[]")
+		t.dims++
+	}
+	switch t.base {
+	case typeInt, typeLong, typeDouble, typeBool, typeString:
+	default:
+		return valueType{}, fmt.Errorf("unknown type %q (expected int, long, double, bool or string, optionally with [])", s)
+	}
+	if t.dims > maxTypeDims {
+		return valueType{}, fmt.Errorf("type %q has more than %d dimensions", s, maxTypeDims)
+	}
+	return t, nil
+}
+
+// elem is the type of the elements of an array type.
+func (t valueType) elem() valueType {
+	return valueType{base: t.base, dims: t.dims - 1}
+}
+
+var identifierRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// reservedNames are keywords of the supported languages and names the
+// harnesses declare themselves.
+var reservedNames = map[string]bool{
+	"main": true, "Main": true, "Solution": true, "self": true, "bbjson": true, "bbos": true,
+	"bbfmt": true, "bbDecode": true, "bbEncode": true, "BBJson": true, "bbharness": true,
+	// Python
+	"and": true, "as": true, "assert": true, "async": true, "await": true, "class": true, "def": true,
+	"del": true, "elif": true, "except": true, "from": true, "global": true, "in": true, "is": true,
+	"lambda": true, "nonlocal": true, "not": true, "or": true, "pass": true, "raise": true, "with": true,
+	"yield": true, "None": true, "True": true, "False": true,
+	// Go
+	"chan": true, "defer": true, "fallthrough": true, "func": true, "go": true, "import": true,
+	"interface": true, "map": true, "package": true, "range": true, "select": true, "type": true, "var": true,
+	// C++ and Java
+	"auto": true, "bool": true, "boolean": true, "break": true, "byte": true, "case": true, "catch": true,
+	"char": true, "const": true, "continue": true, "default": true, "delete": true, "do": true, "double": true,
+	"else": true, "enum": true, "extends": true, "final": true, "finally": true, "float": true, "for": true,
+	"friend": true, "goto": true, "if": true, "implements": true, "inline": true, "instanceof": true,
+	"int": true, "long": true, "namespace": true, "native": true, "new": true, "null": true, "nullptr": true,
+	"operator": true, "private": true, "protected": true, "public": true, "register": true, "return": true,
+	"short": true, "signed": true, "sizeof": true, "static": true, "string": true, "struct": true,
+	"super": true, "switch": true, "synchronized": true, "template": true, "this": true, "throw": true,
+	"throws": true, "transient": true, "true": true, "false": true, "try": true, "typedef": true,
+	"typename": true, "union": true, "unsigned": true, "using": true, "virtual": true, "void": true,
+	"volatile": true, "while": true, "std": true, "String": true,
+}
+
+func validateIdentifier(what, name string) error {
+	if !identifierRe.MatchString(name) {
+		return fmt.Errorf("manifest.json: function %s %q is not a valid identifier", what, name)
+	}
+	if reservedNames[name] {
+		return fmt.Errorf("manifest.json: function %s %q is reserved", what, name)
+	}
+	return nil
+}
+
+func validateFunction(m Manifest) error {
+	f := m.Function
+	if f == nil {
+		if m.Comparator == ComparatorJSON {
+			return fmt.Errorf("manifest.json: the %q comparator is only used by function problems", ComparatorJSON)
+		}
+		return nil
+	}
+	if m.Interactive {
+		return fmt.Errorf("manifest.json: a function problem cannot be interactive")
+	}
+	if m.Comparator != "" && m.Comparator != ComparatorJSON {
+		return fmt.Errorf("manifest.json: function problems compare results with the %q comparator", ComparatorJSON)
+	}
+	if err := validateIdentifier("name", f.Name); err != nil {
+		return err
+	}
+	if len(f.Params) > maxFunctionParam {
+		return fmt.Errorf("manifest.json: function has too many params (%d, max %d)", len(f.Params), maxFunctionParam)
+	}
+	seen := map[string]bool{f.Name: true}
+	for _, p := range f.Params {
+		if err := validateIdentifier("param", p.Name); err != nil {
+			return err
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("manifest.json: function param %q is declared twice or shadows the function", p.Name)
+		}
+		seen[p.Name] = true
+		if _, err := parseValueType(p.Type); err != nil {
+			return fmt.Errorf("manifest.json: function param %q: %w", p.Name, err)
+		}
+	}
+	if _, err := parseValueType(f.Returns); err != nil {
+		return fmt.Errorf("manifest.json: function returns: %w", err)
+	}
+	return nil
+}
+
+// checkFunctionTests checks the tests of function problems with
+// checkFunctionTest.
+func checkFunctionTests(m Manifest, tests []TestCase) error {
+	if m.Function == nil {
+		return nil
+	}
+	for _, tc := range tests {
+		if err := checkFunctionTest(m.Function, tc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkFunctionTest checks that tc holds arguments and a result of the types
+// of f, so that a broken test fails the upload rather than every submission.
+func checkFunctionTest(f *FunctionSignature, tc TestCase) error {
+	dec := json.NewDecoder(strings.NewReader(tc.Input))
+	dec.UseNumber()
+	for _, p := range f.Params {
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			if errors.Is(err, io.EOF) {
+				return fmt.Errorf("test %q: missing argument %s", tc.Name, p.Name)
+			}
+			return fmt.Errorf("test %q: argument %s is not valid JSON: %w", tc.Name, p.Name, err)
+		}
+		t, _ := parseValueType(p.Type)
+		if !t.matches(v) {
+			return fmt.Errorf("test %q: argument %s is not of type %s", tc.Name, p.Name, p.Type)
+		}
+	}
+	if dec.More() {
+		return fmt.Errorf("test %q: more arguments than function params", tc.Name)
+	}
+
+	result, err := decodeJSONValue(tc.Expected)
+	if err != nil {
+		return fmt.Errorf("test %q: expected result is not a single JSON value: %w", tc.Name, err)
+	}
+	if t, _ := parseValueType(f.Returns); !t.matches(result) {
+		return fmt.Errorf("test %q: expected result is not of type %s", tc.Name, f.Returns)
+	}
+	return nil
+}
+
+// matches reports whether v, decoded with UseNumber, is a value of type t.
+func (t valueType) matches(v any) bool {
+	if t.dims > 0 {
+		items, ok := v.([]any)
+		if !ok {
+			return false
+		}
+		for _, item := range items {
+			if !t.elem().matches(item) {
+				return false
+			}
+		}
+		return true
+	}
+	switch t.base {
+	case typeInt:
+		n, ok := v.(json.Number)
+		if !ok {
+			return false
+		}
+		i, err := n.Int64()
+		return err == nil && i >= math.MinInt32 && i <= math.MaxInt32
+	case typeLong:
+		n, ok := v.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := n.Int64()
+		return err == nil
+	case typeDouble:
+		n, ok := v.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := n.Float64()
+		return err == nil
+	case typeBool:
+		_, ok := v.(bool)
+		return ok
+	default:
+		_, ok := v.(string)
+		return ok
+	}
+}
+
+// decodeJSONValue decodes s, which must hold exactly one JSON value, keeping
+// numbers as json.Number.
+func decodeJSONValue(s string) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("unexpected data after the value")
+	}
+	return v, nil
+}
+
+// matchJSON compares outputs as JSON values. Numbers are compared by value,
+// within eps when it is set, so 2 matches 2.0.
+func matchJSON(actual, expected string, eps float64) bool {
+	a, err := decodeJSONValue(actual)
+	if err != nil {
+		return false
+	}
+	e, err := decodeJSONValue(expected)
+	if err != nil {
+		return false
+	}
+	return equalJSON(a, e, eps)
+}
+
+func equalJSON(a, e any, eps float64) bool {
+	switch e := e.(type) {
+	case json.Number:
+		a, ok := a.(json.Number)
+		if !ok {
+			return false
+		}
+		ai, aerr := a.Int64()
+		ei, eerr := e.Int64()
+		if aerr == nil && eerr == nil {
+			return ai == ei
+		}
+		if eps > 0 {
+			return matchFloat(a.String(), e.String(), eps)
+		}
+		af, aerr := a.Float64()
+		ef, eerr := e.Float64()
+		return aerr == nil && eerr == nil && af == ef
+	case []any:
+		a, ok := a.([]any)
+		if !ok || len(a) != len(e) {
+			return false
+		}
+		for i := range e {
+			if !equalJSON(a[i], e[i], eps) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		a, ok := a.(map[string]any)
+		if !ok || len(a) != len(e) {
+			return false
+		}
+		for k, ev := range e {
+			av, ok := a[k]
+			if !ok || !equalJSON(av, ev, eps) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == e
+	}
+}
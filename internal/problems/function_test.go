@@ -0,0 +1,122 @@
+package problems
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func twoSum() *FunctionSignature {
+	return &FunctionSignature{
+		Name:    "twoSum",
+		Params:  []FunctionParam{{Name: "nums", Type: "int[]"}, {Name: "target", Type: "int"}},
+		Returns: "int[]",
+	}
+}
+
+func TestValidateFunction(t *testing.T) {
+	assert.NoError(t, validateFunction(Manifest{}))
+	assert.NoError(t, validateFunction(Manifest{Function: twoSum()}))
+	assert.NoError(t, validateFunction(Manifest{Function: twoSum(), Comparator: ComparatorJSON, Epsilon: 1e-6}))
+
+	cases := map[string]struct {
+		edit func(m *Manifest)
+		want string
+	}{
+		"json without function": {func(m *Manifest) { m.Function = nil; m.Comparator = ComparatorJSON }, "only used by function problems"},
+		"interactive":           {func(m *Manifest) { m.Interactive = true }, "cannot be interactive"},
+		"other comparator":      {func(m *Manifest) { m.Comparator = ComparatorTokens }, `with the "json" comparator`},
+		"bad name":              {func(m *Manifest) { m.Function.Name = "two-sum" }, "not a valid identifier"},
+		"keyword":               {func(m *Manifest) { m.Function.Params[0].Name = "class" }, `"class" is reserved`},
+		"duplicate param":       {func(m *Manifest) { m.Function.Params[1].Name = "nums" }, "declared twice"},
+		"unknown type":          {func(m *Manifest) { m.Function.Params[0].Type = "char" }, `unknown type "char"`},
+		"too many dims":         {func(m *Manifest) { m.Function.Returns = "int[][][]" }, "more than 2 dimensions"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := Manifest{Function: twoSum()}
+			tc.edit(&m)
+			assert.ErrorContains(t, validateFunction(m), tc.want)
+		})
+	}
+}
+
+func TestCheckFunctionTest(t *testing.T) {
+	f := twoSum()
+	cases := []struct {
+		input, expected string
+		want            string
+	}{
+		{"[2,7,11,15]\n9\n", "[0,1]\n", ""},
+		{"[] 0", "[]", ""},
+		{"[2,7]\n", "[0,1]", "missing argument target"},
+		{"[2,7]\n9\n1\n", "[0,1]", "more arguments than function params"},
+		{"[2,7.5]\n9\n", "[0,1]", "argument nums is not of type int[]"},
+		{"[2,7]\n3000000000\n", "[0,1]", "argument target is not of type int"},
+		{"[2,7]\n9\n", `"0 1"`, "expected result is not of type int[]"},
+		{"[2,7]\n9\n", "[0,1] [0,1]", "expected result is not a single JSON value"},
+	}
+	for _, tc := range cases {
+		err := checkFunctionTest(f, TestCase{Name: "01", Input: tc.input, Expected: tc.expected})
+		if tc.want == "" {
+			assert.NoError(t, err, tc.input)
+		} else {
+			assert.ErrorContains(t, err, tc.want, tc.input)
+		}
+	}
+}
+
+func TestManifest_Program(t *testing.T) {
+	code, err := Manifest{}.Program("print(1)\n", "python")
+	require.NoError(t, err)
+	assert.Equal(t, "print(1)\n", code)
+
+	m := Manifest{Function: twoSum()}
+	code, err = m.Program("class Solution:\n    pass\n", "python")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(code, "class Solution:\n    pass\n"))
+	assert.Contains(t, code, "Solution().twoSum(*args)")
+
+	// The imports go on the line of the package clause so that compiler
+	// errors point at the lines the player wrote.
+	src := "// sum\npackage main\n\nfunc twoSum(nums []int, target int) []int {\n\treturn nil\n}\n"
+	code, err = m.Program(src, "go")
+	require.NoError(t, err)
+	assert.Equal(t, "// sum", strings.Split(code, "\n")[0])
+	assert.True(t, strings.HasPrefix(strings.Split(code, "\n")[1], "package main; import ("))
+	assert.Equal(t, "func twoSum(nums []int, target int) []int {", strings.Split(code, "\n")[3])
+	assert.Contains(t, code, "bbResult := twoSum(bbArg0, bbArg1)")
+
+	code, err = m.Program("func twoSum(nums []int, target int) []int { return nil }\n", "go")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(code, "package main; import ("))
+
+	code, err = m.Program("class Solution {};\n", "cpp")
+	require.NoError(t, err)
+	assert.Contains(t, code, "bbharness::Conv<std::vector<int>>::from(bbIn.next())")
+
+	code, err = m.Program("class Solution {}\n", "java")
+	require.NoError(t, err)
+	assert.Contains(t, code, "int[] bbArg0 = BBJson.toInt1(bbIn.next());")
+	assert.Contains(t, code, "static int[] toInt1(Object o)")
+
+	_, err = m.Program("", "sh")
+	assert.ErrorContains(t, err, "cannot be solved in sh")
+}
+
+func TestManifest_StarterCode(t *testing.T) {
+	assert.Nil(t, Manifest{}.StarterCode())
+
+	f := &FunctionSignature{
+		Name:    "solve",
+		Params:  []FunctionParam{{Name: "grid", Type: "double[][]"}, {Name: "name", Type: "string"}, {Name: "n", Type: "long"}},
+		Returns: "bool[]",
+	}
+	code := Manifest{Function: f}.StarterCode()
+	assert.Equal(t, "class Solution:\n    def solve(self, grid: list[list[float]], name: str, n: int) -> list[bool]:\n        pass\n", code["python"])
+	assert.Contains(t, code["go"], "func solve(grid [][]float64, name string, n int64) []bool {")
+	assert.Contains(t, code["cpp"], "vector<bool> solve(vector<vector<double>>& grid, string name, long long n) {")
+	assert.Contains(t, code["java"], "public boolean[] solve(double[][] grid, String name, long n) {")
+}
@@ -0,0 +1,234 @@
+package problems
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"bytebattle/internal/executor"
+)
+
+const (
+	// MaxGeneratedFileBytes bounds a generated input and the reference
+	// solution's output for it.
+	MaxGeneratedFileBytes = 16 * 1024 * 1024 // 16 MB
+	// MaxGeneratedBytes bounds all generated inputs and outputs of a problem.
+	MaxGeneratedBytes = 200 * 1024 * 1024 // 200 MB
+
+	generatorTimeLimit = 10 * time.Second
+	validatorTimeLimit = 10 * time.Second
+
+	generatorTestsFile = "tests.txt"
+	generatedPrefix    = "gen-"
+)
+
+// Generator is a problem-supplied program producing test inputs, so that
+// large tests need not be shipped in the archive. It is run once per line of
+// generator/tests.txt with that line's fields as arguments, e.g. a seed and
+// the size of the test, and prints the input to stdout. The expected output
+// is produced by the reference solution.
+type Generator struct {
+	Code     string
+	Language string
+	Args     [][]string
+}
+
+// InputValidator is a problem-supplied program that checks a test input,
+// given on stdin, against the constraints of the statement. It exits with 0
+// when the input is valid; its output explains why it is not.
+type InputValidator struct {
+	Code     string
+	Language string
+}
+
+// loadGenerator returns nil when the problem has no generator/ directory.
+func loadGenerator(dir string) (*Generator, error) {
+	code, lang, err := loadProgram(dir, "generator")
+	if err != nil || code == "" {
+		return nil, err
+	}
+	args, err := loadGeneratorArgs(filepath.Join(dir, generatorTestsFile))
+	if err != nil {
+		return nil, err
+	}
+	return &Generator{Code: code, Language: lang, Args: args}, nil
+}
+
+// loadGeneratorArgs reads one test per line, skipping blank lines and
+// comments starting with #.
+func loadGeneratorArgs(path string) ([][]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("generator/%s is missing", generatorTestsFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading generator/%s: %w", generatorTestsFile, err)
+	}
+	defer f.Close()
+
+	var args [][]string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		args = append(args, strings.Fields(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading generator/%s: %w", generatorTestsFile, err)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("generator/%s: at least one test is required", generatorTestsFile)
+	}
+	return args, nil
+}
+
+// loadInputValidator returns nil when the problem has no validator/ directory.
+func loadInputValidator(dir string) (*InputValidator, error) {
+	code, lang, err := loadProgram(dir, "validator")
+	if err != nil || code == "" {
+		return nil, err
+	}
+	return &InputValidator{Code: code, Language: lang}, nil
+}
+
+// validateInputs runs v on the input of every test, compiling it once.
+func validateInputs(ctx context.Context, exec executor.Executor, v *InputValidator, tests []TestCase) error {
+	inputs := make([]string, len(tests))
+	for i, tc := range tests {
+		inputs[i] = tc.Input
+	}
+	return runAll(ctx, exec, executor.BatchRequest{
+		Code:      v.Code,
+		Language:  executor.Language(v.Language),
+		Inputs:    inputs,
+		TimeLimit: validatorTimeLimit,
+	}, "validator", func(i int, result executor.ExecutionResult) error {
+		msg := strings.TrimSpace(result.Stdout + "\n" + result.Stderr)
+		if verdict := RunFailureVerdict(result); verdict != "" && verdict != VerdictRuntimeError {
+			return fmt.Errorf("test %q: validator failed with verdict %s: %s", tests[i].Name, verdict, msg)
+		}
+		if result.ExitCode != 0 {
+			return fmt.Errorf("test %q: validator rejected input: %s", tests[i].Name, msg)
+		}
+		return nil
+	})
+}
+
+// runAll runs req as a batch and passes every result to check, stopping at
+// the first error. what names the program in executor errors.
+func runAll(ctx context.Context, exec executor.Executor, req executor.BatchRequest, what string, check func(i int, result executor.ExecutionResult) error) error {
+	var checkErr error
+	done := 0
+	err := executor.RunBatch(ctx, exec, req, func(i int, result executor.ExecutionResult) bool {
+		checkErr = check(i, result)
+		done++
+		return checkErr == nil
+	})
+	switch {
+	case err != nil:
+		return fmt.Errorf("running %s: %w", what, err)
+	case checkErr != nil:
+		return checkErr
+	case done < len(req.Inputs):
+		return fmt.Errorf("running %s: got %d results for %d tests", what, done, len(req.Inputs))
+	}
+	return nil
+}
+
+// generateTests runs gen for each of its tests, checks the inputs with v
+// unless it is nil and computes the expected outputs with the reference
+// solution. Each program is compiled once for all the tests. The tests are
+// written to testsDir as gen-NNN.in/.out, next to the archive's own tests.
+func generateTests(ctx context.Context, exec executor.Executor, manifest Manifest, gen *Generator, v *InputValidator, code, lang, testsDir string) error {
+	if err := os.MkdirAll(testsDir, 0o755); err != nil {
+		return fmt.Errorf("creating tests/: %w", err)
+	}
+	tests := make([]TestCase, len(gen.Args))
+	for i := range tests {
+		name := fmt.Sprintf("%s%03d", generatedPrefix, i+1)
+		for _, ext := range []string{".in", ".out"} {
+			if _, err := os.Stat(filepath.Join(testsDir, name+ext)); err == nil {
+				return fmt.Errorf("tests/%s%s: name is reserved for generated tests", name, ext)
+			}
+		}
+		tests[i].Name = name
+	}
+
+	var total int
+	err := runAll(ctx, exec, executor.BatchRequest{
+		Code:        gen.Code,
+		Language:    executor.Language(gen.Language),
+		Inputs:      make([]string, len(tests)),
+		InputArgs:   gen.Args,
+		TimeLimit:   generatorTimeLimit,
+		OutputLimit: MaxGeneratedFileBytes,
+	}, "generator", func(i int, result executor.ExecutionResult) error {
+		if verdict := RunFailureVerdict(result); verdict != "" {
+			return fmt.Errorf("test %q: generator %v failed with verdict %s: %s", tests[i].Name, gen.Args[i], verdict, strings.TrimSpace(result.Stderr))
+		}
+		if len(result.Stdout) > MaxGeneratedFileBytes {
+			return fmt.Errorf("test %q: generated input exceeds %d MB", tests[i].Name, MaxGeneratedFileBytes/(1024*1024))
+		}
+		tests[i].Input = result.Stdout
+		return addGeneratedBytes(&total, len(result.Stdout))
+	})
+	if err != nil {
+		return err
+	}
+	if v != nil {
+		if err := validateInputs(ctx, exec, v, tests); err != nil {
+			return err
+		}
+	}
+
+	inputs := make([]string, len(tests))
+	for i, tc := range tests {
+		inputs[i] = tc.Input
+	}
+	err = runAll(ctx, exec, executor.BatchRequest{
+		Code:        code,
+		Language:    executor.Language(lang),
+		Inputs:      inputs,
+		TimeLimit:   time.Duration(manifest.TimeLimitMs) * time.Millisecond,
+		MemoryLimit: int64(manifest.MemoryLimitMb) * 1024 * 1024,
+		OutputLimit: MaxGeneratedFileBytes,
+	}, "reference solution", func(i int, result executor.ExecutionResult) error {
+		if verdict := RunFailureVerdict(result); verdict != "" {
+			return fmt.Errorf("test %q: reference solution failed with verdict %s: %s", tests[i].Name, verdict, strings.TrimSpace(result.Stderr))
+		}
+		if len(result.Stdout) > MaxGeneratedFileBytes {
+			return fmt.Errorf("test %q: reference output exceeds %d MB", tests[i].Name, MaxGeneratedFileBytes/(1024*1024))
+		}
+		tests[i].Expected = result.Stdout
+		return addGeneratedBytes(&total, len(result.Stdout))
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, tc := range tests {
+		if err := os.WriteFile(filepath.Join(testsDir, tc.Name+".in"), []byte(tc.Input), 0o644); err != nil {
+			return fmt.Errorf("writing test %q: %w", tc.Name, err)
+		}
+		if err := os.WriteFile(filepath.Join(testsDir, tc.Name+".out"), []byte(tc.Expected), 0o644); err != nil {
+			return fmt.Errorf("writing test %q: %w", tc.Name, err)
+		}
+	}
+	return nil
+}
+
+func addGeneratedBytes(total *int, n int) error {
+	*total += n
+	if *total > MaxGeneratedBytes {
+		return fmt.Errorf("generated tests exceed %d MB", MaxGeneratedBytes/(1024*1024))
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package problems
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+const (
+	// DefaultProblemPoints is what a problem without test groups is worth:
+	// it is solved all or nothing.
+	DefaultProblemPoints = 100
+	MaxProblemPoints     = 1000
+)
+
+// TestGroup is a subtask: a set of tests worth Points, awarded when all of
+// them and all the groups it depends on pass. Tests are matched by name
+// against the patterns in Tests, e.g. "samples/*" or "gen-*".
+type TestGroup struct {
+	Name      string   `json:"name"`
+	Points    int      `json:"points"`
+	Tests     []string `json:"tests"`
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+// GroupResult is the outcome of a test group for one submission.
+type GroupResult struct {
+	Name      string `json:"name"`
+	Points    int    `json:"points"`
+	MaxPoints int    `json:"max_points"`
+	Passed    bool   `json:"passed"`
+}
+
+// MaxScore is what the problem is worth in a scoring game.
+func (p *Problem) MaxScore() int {
+	if len(p.Manifest.Groups) == 0 {
+		return DefaultProblemPoints
+	}
+	total := 0
+	for _, g := range p.Manifest.Groups {
+		total += g.Points
+	}
+	return total
+}
+
+// Score awards the points of every group whose tests all passed and whose
+// dependencies were awarded theirs. verdicts are those of p.TestCases in
+// order; tests without one did not run and count as failed.
+func (p *Problem) Score(verdicts []Verdict) (int, []GroupResult) {
+	passed := func(i int) bool { return i < len(verdicts) && verdicts[i] == VerdictAccepted }
+
+	if len(p.Manifest.Groups) == 0 {
+		for i := range p.TestCases {
+			if !passed(i) {
+				return 0, nil
+			}
+		}
+		return DefaultProblemPoints, nil
+	}
+
+	members := groupMembers(p.Manifest.Groups, p.TestCases)
+	results := make([]GroupResult, len(p.Manifest.Groups))
+	byName := make(map[string]bool, len(results))
+	score := 0
+	for gi, g := range p.Manifest.Groups {
+		ok := true
+		for _, dep := range g.DependsOn {
+			ok = ok && byName[dep]
+		}
+		for _, i := range members[gi] {
+			ok = ok && passed(i)
+		}
+		byName[g.Name] = ok
+		results[gi] = GroupResult{Name: g.Name, MaxPoints: g.Points, Passed: ok}
+		if ok {
+			results[gi].Points = g.Points
+			score += g.Points
+		}
+	}
+	return score, results
+}
+
+// groupMembers returns the indices of the tests in each group. A test
+// belongs to the first group with a matching pattern.
+func groupMembers(groups []TestGroup, tests []TestCase) [][]int {
+	members := make([][]int, len(groups))
+	for i, tc := range tests {
+		if gi := groupOf(groups, tc.Name); gi >= 0 {
+			members[gi] = append(members[gi], i)
+		}
+	}
+	return members
+}
+
+func groupOf(groups []TestGroup, name string) int {
+	for gi, g := range groups {
+		for _, pattern := range g.Tests {
+			if ok, _ := path.Match(pattern, name); ok {
+				return gi
+			}
+		}
+	}
+	return -1
+}
+
+// validateGroups checks the test groups of a manifest against the problem's
+// tests: every hidden test must belong to a group, and groups may only
+// depend on groups defined before them.
+func validateGroups(groups []TestGroup, tests []TestCase) error {
+	if len(groups) == 0 {
+		return nil
+	}
+
+	defined := make(map[string]bool, len(groups))
+	total := 0
+	for _, g := range groups {
+		if strings.TrimSpace(g.Name) == "" {
+			return fmt.Errorf("manifest.json: every group needs a name")
+		}
+		if defined[g.Name] {
+			return fmt.Errorf("manifest.json: duplicate group %q", g.Name)
+		}
+		if g.Points < 0 {
+			return fmt.Errorf("manifest.json: group %q: points must not be negative", g.Name)
+		}
+		if len(g.Tests) == 0 {
+			return fmt.Errorf("manifest.json: group %q has no tests", g.Name)
+		}
+		for _, pattern := range g.Tests {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("manifest.json: group %q: invalid test pattern %q", g.Name, pattern)
+			}
+		}
+		for _, dep := range g.DependsOn {
+			if !defined[dep] {
+				return fmt.Errorf("manifest.json: group %q depends on %q, which must be defined before it", g.Name, dep)
+			}
+		}
+		defined[g.Name] = true
+		total += g.Points
+	}
+	if total <= 0 || total > MaxProblemPoints {
+		return fmt.Errorf("manifest.json: groups must be worth between 1 and %d points in total", MaxProblemPoints)
+	}
+
+	for gi, m := range groupMembers(groups, tests) {
+		if len(m) == 0 {
+			return fmt.Errorf("manifest.json: group %q matches no tests", groups[gi].Name)
+		}
+	}
+	for _, tc := range tests {
+		if !tc.Sample && groupOf(groups, tc.Name) < 0 {
+			return fmt.Errorf("manifest.json: test %q is not in any group", tc.Name)
+		}
+	}
+	return nil
+}
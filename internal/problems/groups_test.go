@@ -0,0 +1,90 @@
+package problems
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func groupedProblem() *Problem {
+	return &Problem{
+		Manifest: Manifest{Groups: []TestGroup{
+			{Name: "small", Points: 30, Tests: []string{"small-*"}},
+			{Name: "large", Points: 70, Tests: []string{"large-*"}, DependsOn: []string{"small"}},
+		}},
+		TestCases: []TestCase{
+			{Name: "samples/01", Sample: true},
+			{Name: "small-1"},
+			{Name: "small-2"},
+			{Name: "large-1"},
+		},
+	}
+}
+
+func TestScore(t *testing.T) {
+	p := groupedProblem()
+	AC, WA := VerdictAccepted, VerdictWrongAnswer
+	cases := []struct {
+		name     string
+		verdicts []Verdict
+		want     int
+		passed   []bool
+	}{
+		{"all passed", []Verdict{AC, AC, AC, AC}, 100, []bool{true, true}},
+		{"sample failure does not count", []Verdict{WA, AC, AC, AC}, 100, []bool{true, true}},
+		{"small only", []Verdict{AC, AC, AC, WA}, 30, []bool{true, false}},
+		{"dependency failed", []Verdict{AC, AC, WA, AC}, 0, []bool{false, false}},
+		{"tests not run", []Verdict{AC, AC, AC}, 30, []bool{true, false}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			score, groups := p.Score(tc.verdicts)
+			assert.Equal(t, tc.want, score)
+			require.Len(t, groups, 2)
+			for i, g := range groups {
+				assert.Equal(t, tc.passed[i], g.Passed, g.Name)
+			}
+		})
+	}
+	assert.Equal(t, 100, p.MaxScore())
+}
+
+func TestScore_WithoutGroups(t *testing.T) {
+	p := &Problem{TestCases: []TestCase{{Name: "01"}, {Name: "02"}}}
+	score, groups := p.Score([]Verdict{VerdictAccepted, VerdictAccepted})
+	assert.Equal(t, DefaultProblemPoints, score)
+	assert.Nil(t, groups)
+
+	score, _ = p.Score([]Verdict{VerdictAccepted, VerdictTimeLimit})
+	assert.Zero(t, score)
+}
+
+func TestValidateGroups(t *testing.T) {
+	tests := groupedProblem().TestCases
+	cases := []struct {
+		name    string
+		groups  []TestGroup
+		wantErr string
+	}{
+		{"valid", groupedProblem().Manifest.Groups, ""},
+		{"test outside groups", []TestGroup{{Name: "small", Points: 10, Tests: []string{"small-*"}}}, `test "large-1" is not in any group`},
+		{"forward dependency", []TestGroup{
+			{Name: "small", Points: 10, Tests: []string{"small-*"}, DependsOn: []string{"large"}},
+			{Name: "large", Points: 10, Tests: []string{"large-*"}},
+		}, "must be defined before it"},
+		{"empty group", []TestGroup{{Name: "all", Points: 10, Tests: []string{"*-*"}}, {Name: "none", Points: 5, Tests: []string{"x"}}}, `group "none" matches no tests`},
+		{"no points", []TestGroup{{Name: "all", Tests: []string{"*-*"}}}, "between 1 and 1000 points"},
+		{"bad pattern", []TestGroup{{Name: "all", Points: 1, Tests: []string{"["}}}, "invalid test pattern"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateGroups(tc.groups, tests)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
@@ -0,0 +1,611 @@
+package problems
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// harnessLanguages are the languages function problems can be solved in.
+var harnessLanguages = []string{"python", "go", "cpp", "java"}
+
+// Program returns what to run for code written in lang: the code itself, or
+// for function problems the code followed by the harness that reads the
+// arguments of a test, calls the function and prints its result.
+func (m Manifest) Program(code, lang string) (string, error) {
+	f := m.Function
+	if f == nil {
+		return code, nil
+	}
+	switch lang {
+	case "python":
+		return code + pythonHarness(f), nil
+	case "go":
+		return goHarness(code, f), nil
+	case "cpp":
+		return code + cppHarness(f), nil
+	case "java":
+		return code + javaHarness(f), nil
+	}
+	return "", fmt.Errorf("function problems cannot be solved in %s", lang)
+}
+
+// StarterCode returns the code a player starts from for each language: the
+// function to implement with an empty body. It is nil for problems that are
+// not function problems.
+func (m Manifest) StarterCode() map[string]string {
+	f := m.Function
+	if f == nil {
+		return nil
+	}
+	code := make(map[string]string, len(harnessLanguages))
+	for _, lang := range harnessLanguages {
+		code[lang] = starterCode(f, lang)
+	}
+	return code
+}
+
+func starterCode(f *FunctionSignature, lang string) string {
+	switch lang {
+	case "python":
+		params := []string{"self"}
+		for _, p := range f.Params {
+			params = append(params, p.Name+": "+typeName(p.Type, "python"))
+		}
+		return fmt.Sprintf("class Solution:\n    def %s(%s) -> %s:\n        pass\n",
+			f.Name, strings.Join(params, ", "), typeName(f.Returns, "python"))
+	case "go":
+		params := make([]string, len(f.Params))
+		for i, p := range f.Params {
+			params[i] = p.Name + " " + typeName(p.Type, "go")
+		}
+		return fmt.Sprintf("package main\n\nfunc %s(%s) %s {\n\n}\n",
+			f.Name, strings.Join(params, ", "), typeName(f.Returns, "go"))
+	case "cpp":
+		params := make([]string, len(f.Params))
+		for i, p := range f.Params {
+			t := typeName(p.Type, "cpp")
+			if strings.HasPrefix(t, "vector") {
+				t += "&"
+			}
+			params[i] = t + " " + p.Name
+		}
+		return fmt.Sprintf("#include <bits/stdc++.h>\nusing namespace std;\n\nclass Solution {\npublic:\n    %s %s(%s) {\n\n    }\n};\n",
+			typeName(f.Returns, "cpp"), f.Name, strings.Join(params, ", "))
+	case "java":
+		params := make([]string, len(f.Params))
+		for i, p := range f.Params {
+			params[i] = typeName(p.Type, "java") + " " + p.Name
+		}
+		return fmt.Sprintf("import java.util.*;\n\nclass Solution {\n    public %s %s(%s) {\n\n    }\n}\n",
+			typeName(f.Returns, "java"), f.Name, strings.Join(params, ", "))
+	}
+	return ""
+}
+
+var baseTypeNames = map[string]map[string]string{
+	"python": {typeInt: "int", typeLong: "int", typeDouble: "float", typeBool: "bool", typeString: "str"},
+	"go":     {typeInt: "int", typeLong: "int64", typeDouble: "float64", typeBool: "bool", typeString: "string"},
+	"cpp":    {typeInt: "int", typeLong: "long long", typeDouble: "double", typeBool: "bool", typeString: "string"},
+	"java":   {typeInt: "int", typeLong: "long", typeDouble: "double", typeBool: "boolean", typeString: "String"},
+}
+
+// typeName spells the manifest type s in lang, as the starter code does.
+func typeName(s, lang string) string {
+	t, _ := parseValueType(s)
+	name := baseTypeNames[lang][t.base]
+	for range t.dims {
+		switch lang {
+		case "python":
+			name = "list[" + name + "]"
+		case "go":
+			name = "[]" + name
+		case "cpp":
+			name = "vector<" + name + ">"
+		case "java":
+			name += "[]"
+		}
+	}
+	return name
+}
+
+func pythonHarness(f *FunctionSignature) string {
+	return fmt.Sprintf(`
+
+
+import json as _bb_json
+import sys as _bb_sys
+
+
+def _bb_main():
+    data = _bb_sys.stdin.read()
+    decoder = _bb_json.JSONDecoder()
+    args, pos = [], 0
+    for _ in range(%d):
+        while pos < len(data) and data[pos].isspace():
+            pos += 1
+        value, pos = decoder.raw_decode(data, pos)
+        args.append(value)
+    result = Solution().%s(*args)
+    print(_bb_json.dumps(result, separators=(",", ":")))
+
+
+_bb_main()
+`, len(f.Params), f.Name)
+}
+
+var goPackageRe = regexp.MustCompile(`(?m)^package\s+main\b`)
+
+// goImports is spliced into the contestant's file on the line of the package
+// clause, so that the compiler reports errors at the lines they wrote.
+const goImports = `; import (bbjson "encoding/json"; bbfmt "fmt"; bbos "os")`
+
+func goHarness(code string, f *FunctionSignature) string {
+	if loc := goPackageRe.FindStringIndex(code); loc != nil {
+		code = code[:loc[1]] + goImports + code[loc[1]:]
+	} else {
+		code = "package main" + goImports + "; " + code
+	}
+
+	var b strings.Builder
+	b.WriteString(code)
+	b.WriteString("\n\nfunc main() {\n\tbbDec := bbjson.NewDecoder(bbos.Stdin)\n")
+	args := make([]string, len(f.Params))
+	for i, p := range f.Params {
+		args[i] = fmt.Sprintf("bbArg%d", i)
+		fmt.Fprintf(&b, "\tvar %s %s\n\tbbDecode(bbDec, &%s)\n", args[i], typeName(p.Type, "go"), args[i])
+	}
+	fmt.Fprintf(&b, "\tbbResult := %s(%s)\n", f.Name, strings.Join(args, ", "))
+	if t, _ := parseValueType(f.Returns); t.dims > 0 {
+		// A nil slice is the empty array, not null.
+		fmt.Fprintf(&b, "\tif bbResult == nil {\n\t\tbbResult = %s{}\n\t}\n", typeName(f.Returns, "go"))
+	}
+	b.WriteString(`	bbOut, bbErr := bbjson.Marshal(bbResult)
+	if bbErr != nil {
+		bbfmt.Fprintln(bbos.Stderr, bbErr)
+		bbos.Exit(2)
+	}
+	bbfmt.Println(string(bbOut))
+}
+
+func bbDecode(dec *bbjson.Decoder, v any) {
+	if err := dec.Decode(v); err != nil {
+		bbfmt.Fprintln(bbos.Stderr, "reading arguments:", err)
+		bbos.Exit(2)
+	}
+}
+`)
+	return b.String()
+}
+
+var cppBaseTypes = map[string]string{
+	typeInt: "int", typeLong: "long long", typeDouble: "double", typeBool: "bool", typeString: "std::string",
+}
+
+func cppHarness(f *FunctionSignature) string {
+	var b strings.Builder
+	b.WriteString(cppRuntime)
+	b.WriteString(`
+int main() {
+    std::string bbInput((std::istreambuf_iterator<char>(std::cin)), std::istreambuf_iterator<char>());
+    bbharness::Parser bbIn(bbInput);
+`)
+	args := make([]string, len(f.Params))
+	for i, p := range f.Params {
+		t, _ := parseValueType(p.Type)
+		name := cppBaseTypes[t.base]
+		for range t.dims {
+			name = "std::vector<" + name + ">"
+		}
+		args[i] = fmt.Sprintf("bbArg%d", i)
+		fmt.Fprintf(&b, "    auto %s = bbharness::Conv<%s>::from(bbIn.next());\n", args[i], name)
+	}
+	fmt.Fprintf(&b, `    Solution bbSolution;
+    std::string bbOut;
+    bbharness::write(bbOut, bbSolution.%s(%s));
+    std::cout << bbOut << '\n';
+    return 0;
+}
+`, f.Name, strings.Join(args, ", "))
+	return b.String()
+}
+
+const cppRuntime = `
+
+#include <cctype>
+#include <cstdio>
+#include <iostream>
+#include <iterator>
+#include <stdexcept>
+#include <string>
+#include <type_traits>
+#include <vector>
+
+namespace bbharness {
+
+struct Value {
+    bool b = false;
+    std::string text;
+    std::vector<Value> items;
+};
+
+class Parser {
+public:
+    explicit Parser(std::string s) : s_(std::move(s)) {}
+
+    Value next() { return value(); }
+
+private:
+    std::string s_;
+    size_t pos_ = 0;
+
+    char peek() {
+        while (pos_ < s_.size() && std::isspace(static_cast<unsigned char>(s_[pos_]))) pos_++;
+        if (pos_ >= s_.size()) throw std::runtime_error("unexpected end of input");
+        return s_[pos_];
+    }
+
+    Value value() {
+        Value v;
+        char c = peek();
+        if (c == '[') {
+            pos_++;
+            if (peek() == ']') {
+                pos_++;
+                return v;
+            }
+            for (;;) {
+                v.items.push_back(value());
+                char d = peek();
+                pos_++;
+                if (d == ']') return v;
+                if (d != ',') throw std::runtime_error("expected , or ]");
+            }
+        }
+        if (c == '"') {
+            pos_++;
+            v.text = str();
+            return v;
+        }
+        if (s_.compare(pos_, 4, "true") == 0) {
+            pos_ += 4;
+            v.b = true;
+            return v;
+        }
+        if (s_.compare(pos_, 5, "false") == 0) {
+            pos_ += 5;
+            return v;
+        }
+        size_t start = pos_;
+        while (pos_ < s_.size() && std::string("+-0123456789.eE").find(s_[pos_]) != std::string::npos) pos_++;
+        if (start == pos_) throw std::runtime_error("unexpected character");
+        v.text = s_.substr(start, pos_ - start);
+        return v;
+    }
+
+    unsigned hex4() {
+        if (pos_ + 4 > s_.size()) throw std::runtime_error("bad escape");
+        unsigned cp = std::stoul(s_.substr(pos_, 4), nullptr, 16);
+        pos_ += 4;
+        return cp;
+    }
+
+    std::string str() {
+        std::string out;
+        for (;;) {
+            if (pos_ >= s_.size()) throw std::runtime_error("unterminated string");
+            char c = s_[pos_++];
+            if (c == '"') return out;
+            if (c != '\\') {
+                out += c;
+                continue;
+            }
+            char e = s_[pos_++];
+            switch (e) {
+            case 'b': out += '\b'; break;
+            case 'f': out += '\f'; break;
+            case 'n': out += '\n'; break;
+            case 'r': out += '\r'; break;
+            case 't': out += '\t'; break;
+            case 'u': {
+                unsigned cp = hex4();
+                if (cp >= 0xD800 && cp < 0xDC00 && s_.compare(pos_, 2, "\\u") == 0) {
+                    pos_ += 2;
+                    cp = 0x10000 + ((cp - 0xD800) << 10) + (hex4() - 0xDC00);
+                }
+                if (cp < 0x80) {
+                    out += static_cast<char>(cp);
+                } else if (cp < 0x800) {
+                    out += static_cast<char>(0xC0 | (cp >> 6));
+                    out += static_cast<char>(0x80 | (cp & 0x3F));
+                } else if (cp < 0x10000) {
+                    out += static_cast<char>(0xE0 | (cp >> 12));
+                    out += static_cast<char>(0x80 | ((cp >> 6) & 0x3F));
+                    out += static_cast<char>(0x80 | (cp & 0x3F));
+                } else {
+                    out += static_cast<char>(0xF0 | (cp >> 18));
+                    out += static_cast<char>(0x80 | ((cp >> 12) & 0x3F));
+                    out += static_cast<char>(0x80 | ((cp >> 6) & 0x3F));
+                    out += static_cast<char>(0x80 | (cp & 0x3F));
+                }
+                break;
+            }
+            default: out += e;
+            }
+        }
+    }
+};
+
+template <class T> struct Conv;
+template <> struct Conv<int> { static int from(const Value& v) { return std::stoi(v.text); } };
+template <> struct Conv<long long> { static long long from(const Value& v) { return std::stoll(v.text); } };
+template <> struct Conv<double> { static double from(const Value& v) { return std::stod(v.text); } };
+template <> struct Conv<bool> { static bool from(const Value& v) { return v.b; } };
+template <> struct Conv<std::string> { static std::string from(const Value& v) { return v.text; } };
+template <class T> struct Conv<std::vector<T>> {
+    static std::vector<T> from(const Value& v) {
+        std::vector<T> out;
+        out.reserve(v.items.size());
+        for (const auto& item : v.items) out.push_back(Conv<T>::from(item));
+        return out;
+    }
+};
+
+template <class T> struct isVector : std::false_type {};
+template <class T> struct isVector<std::vector<T>> : std::true_type {};
+
+template <class T> void write(std::string& out, const T& v) {
+    if constexpr (std::is_same_v<T, bool>) {
+        out += v ? "true" : "false";
+    } else if constexpr (std::is_integral_v<T>) {
+        out += std::to_string(v);
+    } else if constexpr (std::is_floating_point_v<T>) {
+        char buf[32];
+        std::snprintf(buf, sizeof buf, "%.17g", static_cast<double>(v));
+        out += buf;
+    } else if constexpr (isVector<T>::value) {
+        out += '[';
+        for (size_t i = 0; i < v.size(); i++) {
+            if (i > 0) out += ',';
+            write(out, static_cast<typename T::value_type>(v[i]));
+        }
+        out += ']';
+    } else {
+        const std::string& s = v;
+        out += '"';
+        for (unsigned char c : s) {
+            switch (c) {
+            case '"': out += "\\\""; break;
+            case '\\': out += "\\\\"; break;
+            case '\n': out += "\\n"; break;
+            case '\r': out += "\\r"; break;
+            case '\t': out += "\\t"; break;
+            default:
+                if (c < 0x20) {
+                    char buf[8];
+                    std::snprintf(buf, sizeof buf, "\\u%04x", c);
+                    out += buf;
+                } else {
+                    out += static_cast<char>(c);
+                }
+            }
+        }
+        out += '"';
+    }
+}
+
+}  // namespace bbharness
+`
+
+var javaBaseTypes = []struct{ base, name, conv string }{
+	{typeInt, "int", "Int"},
+	{typeLong, "long", "Long"},
+	{typeDouble, "double", "Double"},
+	{typeBool, "boolean", "Bool"},
+	{typeString, "String", "Str"},
+}
+
+func javaHarness(f *FunctionSignature) string {
+	var b strings.Builder
+	b.WriteString(`
+
+class Main {
+    public static void main(String[] bbArgs) throws Exception {
+        BBJson bbIn = new BBJson(new String(System.in.readAllBytes(), java.nio.charset.StandardCharsets.UTF_8));
+`)
+	args := make([]string, len(f.Params))
+	for i, p := range f.Params {
+		t, _ := parseValueType(p.Type)
+		conv := ""
+		for _, bt := range javaBaseTypes {
+			if bt.base == t.base {
+				conv = bt.conv
+			}
+		}
+		args[i] = fmt.Sprintf("bbArg%d", i)
+		fmt.Fprintf(&b, "        %s %s = BBJson.to%s%d(bbIn.next());\n", typeName(p.Type, "java"), args[i], conv, t.dims)
+	}
+	fmt.Fprintf(&b, `        StringBuilder bbOut = new StringBuilder();
+        BBJson.write(bbOut, new Solution().%s(%s));
+        System.out.println(bbOut);
+    }
+}
+`, f.Name, strings.Join(args, ", "))
+	b.WriteString(javaRuntime)
+
+	// Converters from the parsed values to each parameter type.
+	for _, bt := range javaBaseTypes {
+		for dims := 1; dims <= maxTypeDims; dims++ {
+			elem := bt.name + strings.Repeat("[]", dims-1)
+			// new int[n][] rather than new int[][n].
+			alloc := bt.name + "[l.size()]" + strings.Repeat("[]", dims-1)
+			fmt.Fprintf(&b, `
+    static %s[] to%s%d(Object o) {
+        java.util.List<?> l = (java.util.List<?>) o;
+        %s[] a = new %s;
+        for (int i = 0; i < a.length; i++) a[i] = to%s%d(l.get(i));
+        return a;
+    }
+`, elem, bt.conv, dims, elem, alloc, bt.conv, dims-1)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// javaRuntime is the BBJson class without its closing brace, which follows
+// the generated array converters.
+const javaRuntime = `
+final class BBJson {
+    private final String s;
+    private int pos;
+
+    BBJson(String s) {
+        this.s = s;
+    }
+
+    Object next() {
+        return value();
+    }
+
+    private char peek() {
+        while (pos < s.length() && Character.isWhitespace(s.charAt(pos))) pos++;
+        if (pos >= s.length()) throw new IllegalArgumentException("unexpected end of input");
+        return s.charAt(pos);
+    }
+
+    private Object value() {
+        char c = peek();
+        if (c == '[') {
+            pos++;
+            java.util.List<Object> items = new java.util.ArrayList<>();
+            if (peek() == ']') {
+                pos++;
+                return items;
+            }
+            while (true) {
+                items.add(value());
+                char d = peek();
+                pos++;
+                if (d == ']') return items;
+                if (d != ',') throw new IllegalArgumentException("expected , or ] at " + (pos - 1));
+            }
+        }
+        if (c == '"') {
+            pos++;
+            return string();
+        }
+        if (s.startsWith("true", pos)) {
+            pos += 4;
+            return Boolean.TRUE;
+        }
+        if (s.startsWith("false", pos)) {
+            pos += 5;
+            return Boolean.FALSE;
+        }
+        int start = pos;
+        while (pos < s.length() && "+-0123456789.eE".indexOf(s.charAt(pos)) >= 0) pos++;
+        if (start == pos) throw new IllegalArgumentException("unexpected " + c + " at " + pos);
+        return new java.math.BigDecimal(s.substring(start, pos));
+    }
+
+    private String string() {
+        StringBuilder b = new StringBuilder();
+        while (true) {
+            char c = s.charAt(pos++);
+            if (c == '"') return b.toString();
+            if (c != '\\') {
+                b.append(c);
+                continue;
+            }
+            char e = s.charAt(pos++);
+            switch (e) {
+                case 'b': b.append('\b'); break;
+                case 'f': b.append('\f'); break;
+                case 'n': b.append('\n'); break;
+                case 'r': b.append('\r'); break;
+                case 't': b.append('\t'); break;
+                case 'u':
+                    b.append((char) Integer.parseInt(s.substring(pos, pos + 4), 16));
+                    pos += 4;
+                    break;
+                default: b.append(e);
+            }
+        }
+    }
+
+    static int toInt0(Object o) {
+        return ((Number) o).intValue();
+    }
+
+    static long toLong0(Object o) {
+        return ((Number) o).longValue();
+    }
+
+    static double toDouble0(Object o) {
+        return ((Number) o).doubleValue();
+    }
+
+    static boolean toBool0(Object o) {
+        return (Boolean) o;
+    }
+
+    static String toStr0(Object o) {
+        return (String) o;
+    }
+
+    static void write(StringBuilder b, Object v) {
+        if (v instanceof String) {
+            writeString(b, (String) v);
+        } else if (v instanceof Boolean || v instanceof Number) {
+            b.append(v);
+        } else if (v instanceof int[]) {
+            int[] a = (int[]) v;
+            b.append('[');
+            for (int i = 0; i < a.length; i++) b.append(i > 0 ? "," : "").append(a[i]);
+            b.append(']');
+        } else if (v instanceof long[]) {
+            long[] a = (long[]) v;
+            b.append('[');
+            for (int i = 0; i < a.length; i++) b.append(i > 0 ? "," : "").append(a[i]);
+            b.append(']');
+        } else if (v instanceof double[]) {
+            double[] a = (double[]) v;
+            b.append('[');
+            for (int i = 0; i < a.length; i++) b.append(i > 0 ? "," : "").append(a[i]);
+            b.append(']');
+        } else if (v instanceof boolean[]) {
+            boolean[] a = (boolean[]) v;
+            b.append('[');
+            for (int i = 0; i < a.length; i++) b.append(i > 0 ? "," : "").append(a[i]);
+            b.append(']');
+        } else if (v instanceof Object[]) {
+            Object[] a = (Object[]) v;
+            b.append('[');
+            for (int i = 0; i < a.length; i++) {
+                if (i > 0) b.append(',');
+                write(b, a[i]);
+            }
+            b.append(']');
+        } else if (v == null) {
+            b.append("null");
+        } else {
+            throw new IllegalArgumentException("cannot print " + v.getClass());
+        }
+    }
+
+    private static void writeString(StringBuilder b, String s) {
+        b.append('"');
+        for (int i = 0; i < s.length(); i++) {
+            char c = s.charAt(i);
+            if (c == '"' || c == '\\') {
+                b.append('\\').append(c);
+            } else if (c < 0x20 || c > 0x7e) {
+                b.append(String.format("\\u%04x", (int) c));
+            } else {
+                b.append(c);
+            }
+        }
+        b.append('"');
+    }
+`
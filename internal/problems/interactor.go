@@ -0,0 +1,105 @@
+package problems
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"bytebattle/internal/executor"
+)
+
+// interactorExtraTime is added to the contestant's time limit for the
+// interactor, so that a contestant stalling the interaction runs out of
+// time first and gets the TLE.
+const interactorExtraTime = 10 * time.Second
+
+// Interactor is a problem-supplied program that talks to the contestant's
+// program in interactive problems: what it prints is the program's input and
+// what the program prints is its input. It is run as
+//
+//	<interactor> input.txt answer.txt
+//
+// with the files of the test, and reports the verdict through its exit code
+// like a Checker. A wrong answer is explained on stderr.
+type Interactor struct {
+	Code     string
+	Language string
+}
+
+// loadInteractor returns nil when the problem has no interactor/ directory.
+func loadInteractor(dir string) (*Interactor, error) {
+	code, lang, err := loadProgram(dir, "interactor")
+	if err != nil || code == "" {
+		return nil, err
+	}
+	return &Interactor{Code: code, Language: lang}, nil
+}
+
+// validateInteractive checks that the manifest's interactive flag agrees
+// with the interactor/ directory. The interactor decides the verdict, so
+// there is nothing for a checker or a comparator to do.
+func validateInteractive(m Manifest, interactor *Interactor, checker *Checker) error {
+	switch {
+	case m.Interactive && interactor == nil:
+		return fmt.Errorf("manifest.json: interactive problems need an interactor/ directory")
+	case !m.Interactive && interactor != nil:
+		return fmt.Errorf("interactor/ is only used when manifest.json sets interactive")
+	case interactor != nil && checker != nil:
+		return fmt.Errorf("interactive problems cannot have a checker/")
+	case interactor != nil && m.Comparator != "":
+		return fmt.Errorf("manifest.json: comparator cannot be combined with an interactor")
+	}
+	return nil
+}
+
+// Request builds the request running code against the interactor on tests.
+func (in *Interactor) Request(code string, lang executor.Language, timeLimit time.Duration, memLimit int64, tests []TestCase) executor.InteractiveRequest {
+	files := make([]map[string]string, len(tests))
+	for i, tc := range tests {
+		files[i] = map[string]string{
+			checkerInputFile:  tc.Input,
+			checkerAnswerFile: tc.Expected,
+		}
+	}
+	return executor.InteractiveRequest{
+		Code:        code,
+		Language:    lang,
+		TimeLimit:   timeLimit,
+		MemoryLimit: memLimit,
+		Interactor: executor.Interactor{
+			Code:      in.Code,
+			Language:  executor.Language(in.Language),
+			Args:      []string{checkerInputFile, checkerAnswerFile},
+			TimeLimit: timeLimit + interactorExtraTime,
+		},
+		Tests: files,
+	}
+}
+
+// InteractionVerdict judges one run of an interaction and returns the
+// interactor's message along with a wrong answer. The program's own failures
+// come first, except that a program that crashed after the interactor had
+// already rejected it gets the wrong answer. An error means the interactor
+// itself failed.
+func InteractionVerdict(res executor.InteractiveResult) (Verdict, string, error) {
+	program, interactor := res.Program, res.Interactor
+	msg := strings.TrimSpace(interactor.Stderr)
+	switch {
+	case interactor.CompileFailed:
+		return "", "", fmt.Errorf("interactor failed to compile: %s", msg)
+	case program.CompileFailed:
+		return VerdictCompileError, "", nil
+	case program.TimedOut:
+		return VerdictTimeLimit, "", nil
+	case program.OOMKilled:
+		return VerdictMemoryLimit, "", nil
+	case interactor.ExitCode == 1:
+		return VerdictWrongAnswer, msg, nil
+	case program.ExitCode != 0:
+		return VerdictRuntimeError, "", nil
+	case interactor.TimedOut || interactor.OOMKilled || interactor.ExitCode != 0:
+		return "", "", fmt.Errorf("interactor failed with exit code %d: %s", interactor.ExitCode, msg)
+	default:
+		return VerdictAccepted, "", nil
+	}
+}
@@ -131,7 +131,7 @@ func insertProblemTx(ctx context.Context, pool *pgxpool.Pool, args insertProblem
 		ArtifactSha256:    args.sha,
 		LimitsTimeMs:      int32(args.problem.Manifest.TimeLimitMs),
 		LimitsMemoryKb:    int32(args.problem.Manifest.MemoryLimitMb * 1024),
-		CheckerType:       "diff",
+		CheckerType:       args.problem.CheckerType(),
 		ReferenceLanguage: args.refLang,
 		CreatedByUserID:   uuid.NullUUID{Valid: false},
 		TestCaseCount:     int32(len(args.problem.TestCases)),
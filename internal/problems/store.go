@@ -10,6 +10,8 @@ import (
 	"sort"
 	"strings"
 	"sync"
+
+	"bytebattle/internal/executor"
 )
 
 type Manifest struct {
@@ -18,19 +20,51 @@ type Manifest struct {
 	Difficulty    string `json:"difficulty"`
 	TimeLimitMs   int    `json:"time_limit_ms"`
 	MemoryLimitMb int    `json:"memory_limit_mb"`
+	// Comparator selects a built-in comparison mode (see Comparator);
+	// ignored when the problem ships a custom checker.
+	Comparator string  `json:"comparator,omitempty"`
+	Epsilon    float64 `json:"epsilon,omitempty"`
+	// Groups split the tests into subtasks scored separately; without them
+	// a problem is solved all or nothing.
+	Groups []TestGroup `json:"groups,omitempty"`
+	// Interactive problems are judged by the program in interactor/, which
+	// the contestant's program talks to instead of reading a test.
+	Interactive bool `json:"interactive,omitempty"`
+	// Function problems have contestants implement a function rather than
+	// a whole program; see FunctionSignature.
+	Function *FunctionSignature `json:"function,omitempty"`
 }
 
 type TestCase struct {
 	Name     string
 	Input    string
 	Expected string
+	// Sample tests are shown to players along with their expected output;
+	// the others are hidden and only ever reported by verdict.
+	Sample bool
 }
 
 type Problem struct {
-	Slug      string
-	Statement string
-	Manifest  Manifest
-	TestCases []TestCase
+	Slug       string
+	Statement  string
+	Manifest   Manifest
+	TestCases  []TestCase
+	Checker    *Checker
+	Interactor *Interactor
+}
+
+// samplesDir is the subdirectory of tests/ holding the sample tests.
+const samplesDir = "samples"
+
+// SampleTests returns the tests a player may see and run their code against
+// before submitting. They come first in TestCases, so a submission fails on
+// a sample, with its full output, before any hidden test is run.
+func (p *Problem) SampleTests() []TestCase {
+	n := 0
+	for n < len(p.TestCases) && p.TestCases[n].Sample {
+		n++
+	}
+	return p.TestCases[:n]
 }
 
 type Store struct {
@@ -77,6 +111,9 @@ func loadProblemFromDir(artifactPath, dir string) (*Problem, error) {
 	if manifest.Title == "" {
 		return nil, fmt.Errorf("manifest.json: title is required")
 	}
+	if err := validateFunction(manifest); err != nil {
+		return nil, err
+	}
 
 	stmtBytes, err := os.ReadFile(filepath.Join(dir, "statement.md"))
 	if err != nil {
@@ -90,18 +127,55 @@ func loadProblemFromDir(artifactPath, dir string) (*Problem, error) {
 	if len(tests) == 0 {
 		return nil, fmt.Errorf("problem has no test cases")
 	}
+	if err := validateGroups(manifest.Groups, tests); err != nil {
+		return nil, err
+	}
+
+	checker, err := loadChecker(filepath.Join(dir, "checker"))
+	if err != nil {
+		return nil, err
+	}
+	interactor, err := loadInteractor(filepath.Join(dir, "interactor"))
+	if err != nil {
+		return nil, err
+	}
+	if err := validateInteractive(manifest, interactor, checker); err != nil {
+		return nil, err
+	}
 
 	slug := strings.SplitN(artifactPath, "/", 2)[0]
 
 	return &Problem{
-		Slug:      slug,
-		Statement: string(stmtBytes),
-		Manifest:  manifest,
-		TestCases: tests,
+		Slug:       slug,
+		Statement:  string(stmtBytes),
+		Manifest:   manifest,
+		TestCases:  tests,
+		Checker:    checker,
+		Interactor: interactor,
 	}, nil
 }
 
+// loadTestCases loads the sample tests from dir/samples followed by the hidden
+// tests from dir. Only tests under samples/ are ever shown to players: a
+// package without them has no samples.
 func loadTestCases(dir string) ([]TestCase, error) {
+	samples, err := loadTestDir(filepath.Join(dir, samplesDir), samplesDir+"/")
+	if err != nil {
+		return nil, err
+	}
+	hidden, err := loadTestDir(dir, "")
+	if err != nil {
+		return nil, err
+	}
+	for i := range samples {
+		samples[i].Sample = true
+	}
+	return append(samples, hidden...), nil
+}
+
+// loadTestDir loads the .in/.out pairs in dir sorted by name. Test names are
+// the file names without extension, prefixed with prefix.
+func loadTestDir(dir, prefix string) ([]TestCase, error) {
 	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
 		return nil, nil
 	} else if err != nil {
@@ -129,10 +203,10 @@ func loadTestCases(dir string) ([]TestCase, error) {
 		}
 		expectedData, err := os.ReadFile(filepath.Join(dir, base+".out"))
 		if err != nil {
-			return nil, fmt.Errorf("reading %s.out: %w", base, err)
+			return nil, fmt.Errorf("reading %s.out: %w", prefix+base, err)
 		}
 		cases = append(cases, TestCase{
-			Name:     base,
+			Name:     prefix + base,
 			Input:    string(inputData),
 			Expected: string(expectedData),
 		})
@@ -152,6 +226,17 @@ func Match(actual, expected string) bool {
 	return NormalizeOutput(actual) == NormalizeOutput(expected)
 }
 
+// OutputLimit is how much stdout to keep from runs judged on tests: more
+// than the longest expected output, so that a correct answer to a large
+// generated test is not cut off.
+func OutputLimit(tests []TestCase) int {
+	n := 0
+	for _, tc := range tests {
+		n = max(n, len(tc.Expected))
+	}
+	return n + executor.DefaultOutputLimit
+}
+
 type ProblemMeta struct {
 	Slug          string
 	Title         string
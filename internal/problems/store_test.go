@@ -1,8 +1,10 @@
 package problems
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -54,6 +56,9 @@ func TestStore_GetByPath_OK(t *testing.T) {
 	if len(p.TestCases) != 2 {
 		t.Errorf("test cases = %d", len(p.TestCases))
 	}
+	if samples := p.SampleTests(); len(samples) != 0 {
+		t.Errorf("tests outside samples/ must stay hidden, sample tests = %+v", samples)
+	}
 }
 
 func TestStore_GetByPath_Cached(t *testing.T) {
@@ -69,6 +74,32 @@ func TestStore_GetByPath_Cached(t *testing.T) {
 	}
 }
 
+func TestStore_GetByPath_WithChecker(t *testing.T) {
+	dir := t.TempDir()
+	writeTestProblem(t, dir, "001-add", "v1", sampleManifest, map[string][2]string{
+		"01": {"1 2\n", "3\n"},
+	})
+	checkerDir := filepath.Join(dir, "001-add", "v1", "checker")
+	if err := os.MkdirAll(checkerDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(checkerDir, "checker.cpp"), []byte("int main(){}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewStore(dir)
+	p, err := s.GetByPath("001-add/v1")
+	if err != nil {
+		t.Fatalf("GetByPath: %v", err)
+	}
+	if p.Checker == nil || p.Checker.Language != "cpp" {
+		t.Fatalf("checker = %+v", p.Checker)
+	}
+	if p.CheckerType() != CheckerTypeCustom {
+		t.Errorf("checker type = %q", p.CheckerType())
+	}
+}
+
 func TestStore_GetByPath_NotFound(t *testing.T) {
 	s := NewStore(t.TempDir())
 	_, err := s.GetByPath("nope/v1")
@@ -107,3 +138,72 @@ func TestNormalizeAndMatch(t *testing.T) {
 		t.Error("should not match")
 	}
 }
+
+func TestStore_GetByPath_Samples(t *testing.T) {
+	dir := t.TempDir()
+	writeTestProblem(t, dir, "001-add", "v1", sampleManifest, map[string][2]string{
+		"01": {"5 5\n", "10\n"},
+	})
+	samples := filepath.Join(dir, "001-add", "v1", "tests", "samples")
+	if err := os.MkdirAll(samples, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"01.in": "1 2\n", "01.out": "3\n", "02.in": "2 2\n", "02.out": "4\n"} {
+		if err := os.WriteFile(filepath.Join(samples, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p, err := NewStore(dir).GetByPath("001-add/v1")
+	if err != nil {
+		t.Fatalf("GetByPath: %v", err)
+	}
+	var names []string
+	for _, tc := range p.TestCases {
+		names = append(names, fmt.Sprintf("%s:%t", tc.Name, tc.Sample))
+	}
+	if got := strings.Join(names, " "); got != "samples/01:true samples/02:true 01:false" {
+		t.Errorf("test cases = %s", got)
+	}
+	if len(p.SampleTests()) != 2 {
+		t.Errorf("sample tests = %+v", p.SampleTests())
+	}
+}
+
+func TestStore_GetByPath_Interactive(t *testing.T) {
+	dir := t.TempDir()
+	writeTestProblem(t, dir, "001-guess", "v1", `{"title":"Guess","interactive":true}`, map[string][2]string{
+		"01": {"42\n", "42\n"},
+	})
+	interactorDir := filepath.Join(dir, "001-guess", "v1", "interactor")
+	if err := os.MkdirAll(interactorDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(interactorDir, "interactor.py"), []byte("import sys\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewStore(dir)
+	p, err := s.GetByPath("001-guess/v1")
+	if err != nil {
+		t.Fatalf("GetByPath: %v", err)
+	}
+	if p.Interactor == nil || p.Interactor.Language != "python" {
+		t.Fatalf("interactor = %+v", p.Interactor)
+	}
+	if p.CheckerType() != CheckerTypeInteractive {
+		t.Errorf("checker type = %q", p.CheckerType())
+	}
+}
+
+func TestStore_GetByPath_InteractiveWithoutInteractor(t *testing.T) {
+	dir := t.TempDir()
+	writeTestProblem(t, dir, "001-guess", "v1", `{"title":"Guess","interactive":true}`, map[string][2]string{
+		"01": {"42\n", "42\n"},
+	})
+
+	s := NewStore(dir)
+	if _, err := s.GetByPath("001-guess/v1"); err == nil {
+		t.Fatal("expected an error for an interactive problem without interactor/")
+	}
+}
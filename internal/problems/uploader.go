@@ -73,8 +73,8 @@ func UploadProblem(ctx context.Context, pool *pgxpool.Pool, store *Store, valida
 		ArtifactSha256:    sha,
 		LimitsTimeMs:      int32(validated.Manifest.TimeLimitMs),
 		LimitsMemoryKb:    int32(validated.Manifest.MemoryLimitMb * 1024),
-		CheckerType:       "diff",
-		ReferenceLanguage: validated.ReferenceLang,
+		CheckerType:       checkerType(validated.Checker, validated.Interactor),
+		ReferenceLanguage: validated.References[0].Language,
 		CreatedByUserID:   uuid.NullUUID{UUID: ownerID, Valid: true},
 		TestCaseCount:     int32(len(validated.TestCases)),
 		Difficulty:        validated.Manifest.Difficulty,
@@ -152,8 +152,8 @@ func uploadNewVersion(ctx context.Context, pool *pgxpool.Pool, store *Store, val
 		ArtifactSha256:    sha,
 		LimitsTimeMs:      int32(validated.Manifest.TimeLimitMs),
 		LimitsMemoryKb:    int32(validated.Manifest.MemoryLimitMb * 1024),
-		CheckerType:       "diff",
-		ReferenceLanguage: validated.ReferenceLang,
+		CheckerType:       checkerType(validated.Checker, validated.Interactor),
+		ReferenceLanguage: validated.References[0].Language,
 		CreatedByUserID:   uuid.NullUUID{UUID: ownerID, Valid: true},
 		TestCaseCount:     int32(len(validated.TestCases)),
 		Difficulty:        validated.Manifest.Difficulty,
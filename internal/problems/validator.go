@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -22,18 +23,31 @@ var ErrExecutorNotReady = errors.New("executor not ready")
 const (
 	MaxArchiveBytes       = 50 * 1024 * 1024  // 50 MB
 	MaxExtractedBytes     = 200 * 1024 * 1024 // 200 MB
-	MaxTestCases          = 100
+	MaxTestCases          = 200               // in tests/ and generated together
+	MaxSolutions          = 10                // per reference/ and wrong/
 	MaxProblemsPerUser    = 20
 	MaxVersionsPerProblem = 10
 )
 
 type ValidatedProblem struct {
-	Manifest      Manifest
-	Statement     string
-	ReferenceCode string
-	ReferenceLang string
-	TestCases     []TestCase
-	Dir           string // temp directory; caller must os.RemoveAll when done
+	Manifest  Manifest
+	Statement string
+	// References are the accepted solutions; the first one produces the
+	// expected output of generated tests.
+	References []Solution
+	// WrongSolutions are known to be rejected by the tests.
+	WrongSolutions []Solution
+	TestCases      []TestCase
+	Checker        *Checker
+	Interactor     *Interactor
+	Dir            string // temp directory; caller must os.RemoveAll when done
+}
+
+// Solution is a solution shipped with a problem in reference/ or wrong/.
+type Solution struct {
+	Name     string // file name, e.g. "naive.py"
+	Code     string
+	Language string
 }
 
 func ValidateArchive(ctx context.Context, r io.ReadSeeker, size int64, exec executor.Executor) ([]*ValidatedProblem, error) {
@@ -114,28 +128,108 @@ func validateProblemDir(ctx context.Context, dir string, exec executor.Executor)
 		return nil, fmt.Errorf("statement.md missing: %w", err)
 	}
 
-	refDir := filepath.Join(dir, "reference")
-	refCode, refLang, err := loadReference(refDir)
+	refs, err := loadSolutions(filepath.Join(dir, "reference"), "reference")
+	if err != nil {
+		return nil, err
+	}
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("reference/ directory is missing")
+	}
+	wrong, err := loadSolutions(filepath.Join(dir, "wrong"), "wrong")
+	if err != nil {
+		return nil, err
+	}
+
+	generator, err := loadGenerator(filepath.Join(dir, "generator"))
+	if err != nil {
+		return nil, err
+	}
+	inputValidator, err := loadInputValidator(filepath.Join(dir, "validator"))
 	if err != nil {
 		return nil, err
 	}
 
-	testCases, err := loadAndValidateTestCases(filepath.Join(dir, "tests"))
+	testsDir := filepath.Join(dir, "tests")
+	var generated int
+	if generator != nil {
+		generated = len(generator.Args)
+	}
+	testCases, err := loadAndValidateTestCases(testsDir, generated)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkFunctionTests(manifest, testCases); err != nil {
+		return nil, err
+	}
 
-	if err := runReferenceTests(ctx, exec, manifest, refCode, refLang, testCases); err != nil {
+	checker, err := loadChecker(filepath.Join(dir, "checker"))
+	if err != nil {
+		return nil, err
+	}
+	if checker != nil && manifest.Comparator != "" {
+		return nil, fmt.Errorf("manifest.json: comparator cannot be combined with a custom checker")
+	}
+	interactor, err := loadInteractor(filepath.Join(dir, "interactor"))
+	if err != nil {
 		return nil, err
 	}
+	if err := validateInteractive(manifest, interactor, checker); err != nil {
+		return nil, err
+	}
+	if interactor != nil && generator != nil {
+		return nil, fmt.Errorf("generator/ is not supported for interactive problems")
+	}
+
+	if inputValidator != nil {
+		if err := validateInputs(ctx, exec, inputValidator, testCases); err != nil {
+			return nil, err
+		}
+	}
+	// The first reference solution is checked before it is trusted with the
+	// expected output of the generated tests; the others are checked on all
+	// tests.
+	if err := runReferenceTests(ctx, exec, manifest, checker, interactor, refs[0], len(refs) > 1, testCases); err != nil {
+		return nil, err
+	}
+	if generator != nil {
+		refCode, err := manifest.Program(refs[0].Code, refs[0].Language)
+		if err != nil {
+			return nil, fmt.Errorf("reference/%s: %w", refs[0].Name, err)
+		}
+		if err := generateTests(ctx, exec, manifest, generator, inputValidator, refCode, refs[0].Language, testsDir); err != nil {
+			return nil, err
+		}
+		// Reload so that the tests are in the order the store will use.
+		if testCases, err = loadTestCases(testsDir); err != nil {
+			return nil, fmt.Errorf("loading test cases: %w", err)
+		}
+		if err := checkFunctionTests(manifest, testCases); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateGroups(manifest.Groups, testCases); err != nil {
+		return nil, err
+	}
+	for _, ref := range refs[1:] {
+		if err := runReferenceTests(ctx, exec, manifest, checker, interactor, ref, true, testCases); err != nil {
+			return nil, err
+		}
+	}
+	for _, sol := range wrong {
+		if err := runWrongSolution(ctx, exec, manifest, checker, interactor, sol, testCases); err != nil {
+			return nil, err
+		}
+	}
 
 	return &ValidatedProblem{
-		Manifest:      manifest,
-		Statement:     string(stmtBytes),
-		ReferenceCode: refCode,
-		ReferenceLang: refLang,
-		TestCases:     testCases,
-		Dir:           dir,
+		Manifest:       manifest,
+		Statement:      string(stmtBytes),
+		References:     refs,
+		WrongSolutions: wrong,
+		TestCases:      testCases,
+		Checker:        checker,
+		Interactor:     interactor,
+		Dir:            dir,
 	}, nil
 }
 
@@ -149,7 +243,10 @@ func validateManifest(m Manifest) error {
 	if m.MemoryLimitMb <= 0 || m.MemoryLimitMb > 1024 {
 		return fmt.Errorf("manifest.json: memory_limit_mb must be between 1 and 1024")
 	}
-	return nil
+	if err := validateFunction(m); err != nil {
+		return err
+	}
+	return validateComparator(m)
 }
 
 var extToLang = map[string]string{
@@ -159,43 +256,45 @@ var extToLang = map[string]string{
 	".java": "java",
 }
 
-func loadReference(refDir string) (code, lang string, err error) {
-	if _, err := os.Stat(refDir); os.IsNotExist(err) {
-		return "", "", fmt.Errorf("reference/ directory is missing")
+// loadSolutions loads every solution in dir sorted by file name. It returns
+// nil when dir does not exist.
+func loadSolutions(dir, what string) ([]Solution, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
 	}
-	entries, err := os.ReadDir(refDir)
 	if err != nil {
-		return "", "", fmt.Errorf("reading reference/: %w", err)
+		return nil, fmt.Errorf("reading %s/: %w", what, err)
 	}
 
-	var found []string
+	var solutions []Solution
 	for _, e := range entries {
 		if e.IsDir() {
 			continue
 		}
-		ext := strings.ToLower(filepath.Ext(e.Name()))
-		if _, ok := extToLang[ext]; ok {
-			found = append(found, e.Name())
+		lang, ok := extToLang[strings.ToLower(filepath.Ext(e.Name()))]
+		if !ok {
+			continue
+		}
+		code, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("reading %s/%s: %w", what, e.Name(), err)
 		}
+		solutions = append(solutions, Solution{Name: e.Name(), Code: string(code), Language: lang})
 	}
-	if len(found) == 0 {
-		return "", "", fmt.Errorf("reference/: no solution file found (.py, .go, .cpp, .java)")
-	}
-	if len(found) > 1 {
-		return "", "", fmt.Errorf("reference/: multiple solution files found, expected exactly one")
+	if len(solutions) == 0 {
+		return nil, fmt.Errorf("%s/: no solution file found (.py, .go, .cpp, .java)", what)
 	}
-
-	ext := strings.ToLower(filepath.Ext(found[0]))
-	lang = extToLang[ext]
-	codeBytes, err := os.ReadFile(filepath.Join(refDir, found[0]))
-	if err != nil {
-		return "", "", fmt.Errorf("reading reference solution: %w", err)
+	if len(solutions) > MaxSolutions {
+		return nil, fmt.Errorf("%s/: too many solutions (%d, max %d)", what, len(solutions), MaxSolutions)
 	}
-	return string(codeBytes), lang, nil
+	return solutions, nil
 }
 
-func loadAndValidateTestCases(testsDir string) ([]TestCase, error) {
-	if _, err := os.Stat(testsDir); os.IsNotExist(err) {
+// loadAndValidateTestCases loads the archive's own tests. They are optional
+// when the problem has generated tests, which count towards MaxTestCases.
+func loadAndValidateTestCases(testsDir string, generated int) ([]TestCase, error) {
+	if _, err := os.Stat(testsDir); os.IsNotExist(err) && generated == 0 {
 		return nil, fmt.Errorf("tests/ directory is missing")
 	}
 
@@ -203,35 +302,207 @@ func loadAndValidateTestCases(testsDir string) ([]TestCase, error) {
 	if err != nil {
 		return nil, fmt.Errorf("loading test cases: %w", err)
 	}
-	if len(cases) == 0 {
+	if len(cases) == 0 && generated == 0 {
 		return nil, fmt.Errorf("tests/: at least one test case (.in/.out pair) is required")
 	}
-	if len(cases) > MaxTestCases {
-		return nil, fmt.Errorf("tests/: too many test cases (%d, max %d)", len(cases), MaxTestCases)
+	if len(cases)+generated > MaxTestCases {
+		return nil, fmt.Errorf("tests/: too many test cases (%d, %d of them generated, max %d)", len(cases)+generated, generated, MaxTestCases)
 	}
 	return cases, nil
 }
 
-func runReferenceTests(ctx context.Context, exec executor.Executor, manifest Manifest, code, lang string, tests []TestCase) error {
+// judgement is the outcome of running a solution on a problem's tests: the
+// first test it failed, or VerdictAccepted.
+type judgement struct {
+	verdict Verdict
+	test    TestCase
+	result  executor.ExecutionResult
+	check   CheckResult
+}
+
+// judgeSolution runs sol on tests until it fails one. The solution is
+// compiled once for all tests.
+func judgeSolution(ctx context.Context, exec executor.Executor, manifest Manifest, checker *Checker, interactor *Interactor, sol Solution, tests []TestCase) (judgement, error) {
 	timeLimit := time.Duration(manifest.TimeLimitMs) * time.Millisecond
 	memLimit := int64(manifest.MemoryLimitMb) * 1024 * 1024
+	if interactor != nil {
+		return judgeInteraction(ctx, exec, interactor, sol, timeLimit, memLimit, tests)
+	}
+	code, err := manifest.Program(sol.Code, sol.Language)
+	if err != nil {
+		return judgement{}, err
+	}
+
+	inputs := make([]string, len(tests))
+	for i, tc := range tests {
+		inputs[i] = tc.Input
+	}
+	cmp := manifest.OutputComparator()
+	var results []executor.ExecutionResult
+	stopped := false
+	err = executor.RunBatch(ctx, exec, executor.BatchRequest{
+		Code:        code,
+		Language:    executor.Language(sol.Language),
+		Inputs:      inputs,
+		TimeLimit:   timeLimit,
+		MemoryLimit: memLimit,
+		OutputLimit: OutputLimit(tests),
+	}, func(i int, res executor.ExecutionResult) bool {
+		results = append(results, res)
+		// A checker runs after the batch; the comparator can stop at the
+		// first wrong answer.
+		stopped = RunFailureVerdict(res) != "" || (checker == nil && !cmp.Match(res.Stdout, tests[i].Expected))
+		return !stopped
+	})
+	switch {
+	case err != nil:
+		return judgement{}, fmt.Errorf("executor error: %w", err)
+	case len(results) < len(tests) && !stopped:
+		return judgement{}, fmt.Errorf("executor error: got %d results for %d tests", len(results), len(tests))
+	}
+
+	// Outputs are checked up to the first run that failed.
+	ran := len(results)
+	for i, result := range results {
+		if RunFailureVerdict(result) != "" {
+			ran = i
+			break
+		}
+	}
+	outputs := make([]string, ran)
+	for i := range outputs {
+		outputs[i] = results[i].Stdout
+	}
+	i, check, err := checkOutputs(ctx, exec, cmp, checker, tests[:ran], outputs)
+	switch {
+	case err != nil:
+		return judgement{}, err
+	case i >= 0:
+		return judgement{verdict: VerdictWrongAnswer, test: tests[i], result: results[i], check: check}, nil
+	case ran < len(results):
+		return judgement{verdict: RunFailureVerdict(results[ran]), test: tests[ran], result: results[ran]}, nil
+	}
+	return judgement{verdict: VerdictAccepted}, nil
+}
 
-	for _, tc := range tests {
-		result, err := exec.Run(ctx, executor.ExecutionRequest{
-			Code:        code,
-			Language:    executor.Language(lang),
-			Stdin:       tc.Input,
-			TimeLimit:   timeLimit,
-			MemoryLimit: memLimit,
-		})
+// checkOutputs checks outputs[i] as an answer for tests[i] until one is
+// rejected, returning its index and the check, or -1 when all are accepted.
+// A checker is compiled once for all outputs.
+func checkOutputs(ctx context.Context, exec executor.Executor, cmp Comparator, checker *Checker, tests []TestCase, outputs []string) (int, CheckResult, error) {
+	if checker == nil {
+		for i, tc := range tests {
+			if !cmp.Match(outputs[i], tc.Expected) {
+				return i, CheckResult{}, nil
+			}
+		}
+		return -1, CheckResult{}, nil
+	}
+	if len(tests) == 0 {
+		return -1, CheckResult{}, nil
+	}
+
+	rejected, done := -1, 0
+	var check CheckResult
+	var checkErr error
+	err := executor.RunBatch(ctx, exec, checker.BatchRequest(tests, outputs), func(i int, res executor.ExecutionResult) bool {
+		check, checkErr = CheckerResult(res)
+		if checkErr != nil {
+			checkErr = fmt.Errorf("test %q: %w", tests[i].Name, checkErr)
+			return false
+		}
+		done++
+		if !check.Accepted {
+			rejected = i
+		}
+		return check.Accepted
+	})
+	switch {
+	case err != nil:
+		return 0, CheckResult{}, fmt.Errorf("running checker: %w", err)
+	case checkErr != nil:
+		return 0, CheckResult{}, checkErr
+	case rejected >= 0:
+		return rejected, check, nil
+	case done < len(tests):
+		return 0, CheckResult{}, fmt.Errorf("running checker: got %d results for %d outputs", done, len(tests))
+	}
+	return -1, CheckResult{}, nil
+}
+
+// judgeInteraction is judgeSolution for interactive problems. The message
+// of the interactor rejecting sol is kept in the judgement's check.
+func judgeInteraction(ctx context.Context, exec executor.Executor, interactor *Interactor, sol Solution, timeLimit time.Duration, memLimit int64, tests []TestCase) (judgement, error) {
+	j := judgement{verdict: VerdictAccepted}
+	var judgeErr error
+	req := interactor.Request(sol.Code, executor.Language(sol.Language), timeLimit, memLimit, tests)
+	err := executor.RunInteractive(ctx, exec, req, func(i int, res executor.InteractiveResult) bool {
+		verdict, msg, err := InteractionVerdict(res)
 		if err != nil {
-			return fmt.Errorf("test %q: executor error: %w", tc.Name, err)
+			judgeErr = fmt.Errorf("test %q: %w", tests[i].Name, err)
+			return false
 		}
-		if !Match(result.Stdout, tc.Expected) {
-			return fmt.Errorf("test %q: reference solution output %q, expected %q", tc.Name, NormalizeOutput(result.Stdout), NormalizeOutput(tc.Expected))
+		if verdict == VerdictAccepted {
+			return true
 		}
+		j = judgement{verdict: verdict, test: tests[i], result: res.Program, check: CheckResult{Message: msg}}
+		return false
+	})
+	if err != nil {
+		return judgement{}, fmt.Errorf("executor error: %w", err)
+	}
+	if judgeErr != nil {
+		return judgement{}, judgeErr
+	}
+	return j, nil
+}
+
+// runReferenceTests checks that ref passes every test. Errors name the
+// solution when named is set, i.e. when the problem has several.
+func runReferenceTests(ctx context.Context, exec executor.Executor, manifest Manifest, checker *Checker, interactor *Interactor, ref Solution, named bool, tests []TestCase) error {
+	j, err := judgeSolution(ctx, exec, manifest, checker, interactor, ref, tests)
+	if err == nil {
+		err = referenceFailure(j, checker, interactor)
+	}
+	if err != nil && named {
+		return fmt.Errorf("reference/%s: %w", ref.Name, err)
+	}
+	return err
+}
+
+func referenceFailure(j judgement, checker *Checker, interactor *Interactor) error {
+	tc, result := j.test, j.result
+	switch {
+	case j.verdict == VerdictAccepted:
+		return nil
+	case j.verdict != VerdictWrongAnswer:
+		return fmt.Errorf("test %q: reference solution failed with verdict %s: %s", tc.Name, j.verdict, strings.TrimSpace(result.Stderr))
+	case interactor != nil:
+		return fmt.Errorf("test %q: interactor rejected reference solution: %s", tc.Name, j.check.Message)
+	case checker != nil:
+		return fmt.Errorf("test %q: checker rejected reference solution output: %s", tc.Name, j.check.Message)
+	default:
+		return fmt.Errorf("test %q: reference solution output %q, expected %q", tc.Name, NormalizeOutput(result.Stdout), NormalizeOutput(tc.Expected))
+	}
+}
+
+// runWrongSolution checks that the tests reject sol with a wrong answer or
+// by the time limit. Any other failure, such as a compilation error or a
+// crash, says nothing about the tests.
+func runWrongSolution(ctx context.Context, exec executor.Executor, manifest Manifest, checker *Checker, interactor *Interactor, sol Solution, tests []TestCase) error {
+	j, err := judgeSolution(ctx, exec, manifest, checker, interactor, sol, tests)
+	if err != nil {
+		return fmt.Errorf("wrong/%s: %w", sol.Name, err)
+	}
+	switch j.verdict {
+	case VerdictWrongAnswer, VerdictTimeLimit:
+		return nil
+	case VerdictAccepted:
+		return fmt.Errorf("wrong/%s: solution passed all tests", sol.Name)
+	case VerdictCompileError:
+		return fmt.Errorf("wrong/%s: compilation failed: %s", sol.Name, strings.TrimSpace(j.result.Stderr))
+	default:
+		return fmt.Errorf("wrong/%s: test %q: got %s, expected WA or TLE: %s", sol.Name, j.test.Name, j.verdict, strings.TrimSpace(j.result.Stderr))
 	}
-	return nil
 }
 
 func extractTarGz(r io.Reader, destDir string, maxBytes int64) error {
@@ -7,6 +7,8 @@ import (
 	"compress/gzip"
 	"context"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -23,6 +25,28 @@ func (e fixedOutputExec) Run(_ context.Context, _ executor.ExecutionRequest) (ex
 }
 func (e fixedOutputExec) IsReady() bool { return true }
 
+// checkerExec returns out for contestant runs and exits with checkerExit when
+// invoked as a checker (i.e. with arguments).
+type checkerExec struct {
+	out         string
+	checkerExit int
+}
+
+func (e checkerExec) Run(_ context.Context, req executor.ExecutionRequest) (executor.ExecutionResult, error) {
+	if len(req.Args) > 0 {
+		return executor.ExecutionResult{ExitCode: e.checkerExit, Stdout: "checker says hi"}, nil
+	}
+	return executor.ExecutionResult{Stdout: e.out}, nil
+}
+func (e checkerExec) IsReady() bool { return true }
+
+type timeoutExec struct{}
+
+func (timeoutExec) Run(_ context.Context, _ executor.ExecutionRequest) (executor.ExecutionResult, error) {
+	return executor.ExecutionResult{ExitCode: 124, TimedOut: true, Stderr: "Execution timed out."}, nil
+}
+func (timeoutExec) IsReady() bool { return true }
+
 type notReadyExec struct{}
 
 func (notReadyExec) Run(_ context.Context, _ executor.ExecutionRequest) (executor.ExecutionResult, error) {
@@ -161,7 +185,7 @@ func TestValidateArchive_SingleProblem_TarGz(t *testing.T) {
 	require.Len(t, vps, 1)
 	t.Cleanup(func() { os.RemoveAll(vps[0].Dir) })
 	assert.Equal(t, "Test", vps[0].Manifest.Title)
-	assert.Equal(t, "python", vps[0].ReferenceLang)
+	assert.Equal(t, "python", vps[0].References[0].Language)
 	assert.Len(t, vps[0].TestCases, 1)
 }
 
@@ -228,6 +252,12 @@ func TestValidateArchive_ReferenceTestFails(t *testing.T) {
 	require.ErrorContains(t, err, "reference solution output")
 }
 
+func TestValidateArchive_ReferenceTimesOut(t *testing.T) {
+	r := buildTarGz(t, validFiles())
+	_, err := ValidateArchive(context.Background(), r, int64(r.Len()), timeoutExec{})
+	require.ErrorContains(t, err, "verdict TLE")
+}
+
 func TestValidateArchive_SlugInManifest(t *testing.T) {
 	files := validFiles()
 	files["manifest.json"] = `{"slug":"existing-abc1","title":"Test","time_limit_ms":1000,"memory_limit_mb":256}`
@@ -238,3 +268,512 @@ func TestValidateArchive_SlugInManifest(t *testing.T) {
 	t.Cleanup(func() { os.RemoveAll(vps[0].Dir) })
 	assert.Equal(t, "existing-abc1", vps[0].Manifest.Slug)
 }
+
+func TestValidateArchive_CustomCheckerAccepts(t *testing.T) {
+	files := validFiles()
+	files["checker/checker.py"] = "import sys\nsys.exit(0)\n"
+	r := buildTarGz(t, files)
+	vps, err := ValidateArchive(context.Background(), r, int64(r.Len()), checkerExec{out: "any valid answer", checkerExit: 0})
+	require.NoError(t, err)
+	require.Len(t, vps, 1)
+	t.Cleanup(func() { os.RemoveAll(vps[0].Dir) })
+	require.NotNil(t, vps[0].Checker)
+	assert.Equal(t, "python", vps[0].Checker.Language)
+	assert.Equal(t, CheckerTypeCustom, checkerType(vps[0].Checker, vps[0].Interactor))
+}
+
+func TestValidateArchive_CustomCheckerRejects(t *testing.T) {
+	files := validFiles()
+	files["checker/checker.py"] = "import sys\nsys.exit(1)\n"
+	r := buildTarGz(t, files)
+	_, err := ValidateArchive(context.Background(), r, int64(r.Len()), checkerExec{out: "3", checkerExit: 1})
+	require.ErrorContains(t, err, "checker rejected reference solution output")
+}
+
+func TestValidateArchive_CustomCheckerFailure(t *testing.T) {
+	files := validFiles()
+	files["checker/checker.py"] = "raise SystemExit(3)\n"
+	r := buildTarGz(t, files)
+	_, err := ValidateArchive(context.Background(), r, int64(r.Len()), checkerExec{out: "3", checkerExit: 3})
+	require.ErrorContains(t, err, "checker failed with exit code 3")
+}
+
+func TestValidateArchive_CustomCheckerRunsInOneBatch(t *testing.T) {
+	files := validFiles()
+	files["checker/checker.py"] = "import sys\nsys.exit(0)\n"
+	for _, name := range []string{"02", "03"} {
+		files["tests/"+name+".in"] = "x\n"
+		files["tests/"+name+".out"] = "3\n"
+	}
+	exec := newBatchCountingExec(checkerExec{out: "any valid answer"})
+	r := buildTarGz(t, files)
+	vps, err := ValidateArchive(context.Background(), r, int64(r.Len()), exec)
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(vps[0].Dir) })
+
+	assert.Equal(t, 1, exec.batches[files["checker/checker.py"]])
+	assert.Equal(t, 3, exec.runs[files["checker/checker.py"]])
+}
+
+func TestValidateArchive_CheckerDirWithoutSource(t *testing.T) {
+	files := validFiles()
+	files["checker/README.md"] = "nothing here"
+	r := buildTarGz(t, files)
+	_, err := ValidateArchive(context.Background(), r, int64(r.Len()), fixedOutputExec{"3"})
+	require.ErrorContains(t, err, "no checker source found")
+}
+
+func TestValidateArchive_FloatComparator(t *testing.T) {
+	files := validFiles()
+	files["manifest.json"] = `{"title":"Test","time_limit_ms":1000,"memory_limit_mb":256,"comparator":"float","epsilon":0.01}`
+	r := buildTarGz(t, files)
+	vps, err := ValidateArchive(context.Background(), r, int64(r.Len()), fixedOutputExec{"3.001"})
+	require.NoError(t, err)
+	require.Len(t, vps, 1)
+	t.Cleanup(func() { os.RemoveAll(vps[0].Dir) })
+	assert.Equal(t, ComparatorFloat, vps[0].Manifest.Comparator)
+}
+
+func TestValidateArchive_ComparatorWithChecker(t *testing.T) {
+	files := validFiles()
+	files["manifest.json"] = `{"title":"Test","time_limit_ms":1000,"memory_limit_mb":256,"comparator":"tokens"}`
+	files["checker/checker.py"] = "import sys\nsys.exit(0)\n"
+	r := buildTarGz(t, files)
+	_, err := ValidateArchive(context.Background(), r, int64(r.Len()), checkerExec{out: "3"})
+	require.ErrorContains(t, err, "cannot be combined with a custom checker")
+}
+
+// generatorExec prints its first argument as the generator, doubles the
+// number it reads as the reference solution and, as the validator, accepts
+// inputs below 100.
+type generatorExec struct{}
+
+func (generatorExec) Run(_ context.Context, req executor.ExecutionRequest) (executor.ExecutionResult, error) {
+	if len(req.Args) > 0 {
+		return executor.ExecutionResult{Stdout: req.Args[0] + "\n"}, nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(req.Stdin))
+	if req.Code == "validate()\n" {
+		if err != nil || n >= 100 {
+			return executor.ExecutionResult{ExitCode: 1, Stderr: "n out of range"}, nil
+		}
+		return executor.ExecutionResult{}, nil
+	}
+	return executor.ExecutionResult{Stdout: strconv.Itoa(2*n) + "\n"}, nil
+}
+func (generatorExec) IsReady() bool { return true }
+
+func generatorFiles() map[string]string {
+	return map[string]string{
+		"manifest.json":          `{"title":"Test","time_limit_ms":1000,"memory_limit_mb":256}`,
+		"statement.md":           "# Test\n",
+		"reference/solution.py":  "solve()\n",
+		"generator/gen.py":       "generate()\n",
+		"generator/tests.txt":    "# n\n7\n\n42 extra\n",
+		"validator/validator.py": "validate()\n",
+	}
+}
+
+// bigOutputExec runs generators that print their argument n and solutions
+// printing n x's, "almost()" with a y in place of the last one. Like the
+// real executors it keeps only OutputLimit bytes of stdout.
+type bigOutputExec struct{}
+
+func (bigOutputExec) Run(_ context.Context, req executor.ExecutionRequest) (executor.ExecutionResult, error) {
+	if len(req.Args) > 0 {
+		return executor.ExecutionResult{Stdout: req.Args[0] + "\n"}, nil
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(req.Stdin))
+	out := strings.Repeat("x", n) + "\n"
+	if req.Code == "almost()\n" {
+		out = strings.Repeat("x", n-1) + "y\n"
+	}
+	limit := req.OutputLimit
+	if limit == 0 {
+		limit = executor.DefaultOutputLimit
+	}
+	if len(out) > limit {
+		out = out[:limit] + "...[truncated]"
+	}
+	return executor.ExecutionResult{Stdout: out}, nil
+}
+func (bigOutputExec) IsReady() bool { return true }
+
+func TestValidateArchive_GeneratedTestWithLargeOutput(t *testing.T) {
+	files := map[string]string{
+		"manifest.json":       `{"title":"Test","time_limit_ms":1000,"memory_limit_mb":256}`,
+		"statement.md":        "# Test\n",
+		"reference/a.py":      "solve()\n",
+		"reference/b.py":      "solve()\n",
+		"wrong/almost.py":     "almost()\n",
+		"generator/gen.py":    "generate()\n",
+		"generator/tests.txt": "20000\n",
+	}
+	r := buildTarGz(t, files)
+	vps, err := ValidateArchive(context.Background(), r, int64(r.Len()), bigOutputExec{})
+	require.NoError(t, err, "the second reference solution must pass and the wrong one fail")
+	require.Len(t, vps, 1)
+	t.Cleanup(func() { os.RemoveAll(vps[0].Dir) })
+	require.Len(t, vps[0].TestCases, 1)
+	assert.Len(t, vps[0].TestCases[0].Expected, 20001)
+}
+
+func TestValidateArchive_GeneratedTests(t *testing.T) {
+	files := generatorFiles()
+	files["tests/01.in"] = "1\n"
+	files["tests/01.out"] = "2\n"
+	r := buildTarGz(t, files)
+	vps, err := ValidateArchive(context.Background(), r, int64(r.Len()), generatorExec{})
+	require.NoError(t, err)
+	require.Len(t, vps, 1)
+	t.Cleanup(func() { os.RemoveAll(vps[0].Dir) })
+
+	tests := vps[0].TestCases
+	require.Len(t, tests, 3)
+	assert.Equal(t, TestCase{Name: "01", Input: "1\n", Expected: "2\n"}, tests[0])
+	assert.Equal(t, TestCase{Name: "gen-001", Input: "7\n", Expected: "14\n"}, tests[1])
+	assert.Equal(t, TestCase{Name: "gen-002", Input: "42\n", Expected: "84\n"}, tests[2])
+
+	stored, err := loadTestCases(filepath.Join(vps[0].Dir, "tests"))
+	require.NoError(t, err)
+	assert.Equal(t, tests, stored)
+}
+
+func TestValidateArchive_GeneratorOnly(t *testing.T) {
+	r := buildTarGz(t, generatorFiles())
+	vps, err := ValidateArchive(context.Background(), r, int64(r.Len()), generatorExec{})
+	require.NoError(t, err)
+	require.Len(t, vps, 1)
+	t.Cleanup(func() { os.RemoveAll(vps[0].Dir) })
+	require.Len(t, vps[0].TestCases, 2)
+	assert.False(t, vps[0].TestCases[0].Sample, "generated tests are never samples")
+}
+
+func TestValidateArchive_GeneratorWithoutTests(t *testing.T) {
+	files := generatorFiles()
+	files["generator/tests.txt"] = "# nothing yet\n"
+	r := buildTarGz(t, files)
+	_, err := ValidateArchive(context.Background(), r, int64(r.Len()), generatorExec{})
+	require.ErrorContains(t, err, "at least one test is required")
+}
+
+func TestValidateArchive_TooManyTests(t *testing.T) {
+	files := generatorFiles()
+	files["generator/tests.txt"] = strings.Repeat("1\n", MaxTestCases)
+	files["tests/01.in"] = "1\n"
+	files["tests/01.out"] = "2\n"
+	r := buildTarGz(t, files)
+	_, err := ValidateArchive(context.Background(), r, int64(r.Len()), generatorExec{})
+	require.ErrorContains(t, err, "too many test cases (201, 200 of them generated, max 200)")
+}
+
+// batchCountingExec runs the programs of the executor it wraps in batches
+// and counts the batches and runs of each program.
+type batchCountingExec struct {
+	executor.Executor
+	batches map[string]int
+	runs    map[string]int
+}
+
+func newBatchCountingExec(exec executor.Executor) batchCountingExec {
+	return batchCountingExec{Executor: exec, batches: map[string]int{}, runs: map[string]int{}}
+}
+
+func (e batchCountingExec) RunBatch(ctx context.Context, req executor.BatchRequest, onResult func(i int, res executor.ExecutionResult) bool) error {
+	e.batches[req.Code]++
+	return executor.RunBatch(ctx, e.Executor, req, func(i int, res executor.ExecutionResult) bool {
+		e.runs[req.Code]++
+		return onResult(i, res)
+	})
+}
+
+func TestValidateArchive_GeneratorRunsInBatches(t *testing.T) {
+	files := generatorFiles()
+	files["tests/01.in"] = "1\n"
+	files["tests/01.out"] = "2\n"
+	exec := newBatchCountingExec(generatorExec{})
+	r := buildTarGz(t, files)
+	vps, err := ValidateArchive(context.Background(), r, int64(r.Len()), exec)
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(vps[0].Dir) })
+
+	assert.Equal(t, 1, exec.batches["generate()\n"])
+	assert.Equal(t, 2, exec.batches["validate()\n"], "once for the archive's tests, once for the generated ones")
+}
+
+func TestValidateArchive_ValidatorRejectsArchiveTest(t *testing.T) {
+	files := generatorFiles()
+	files["tests/01.in"] = "100\n"
+	files["tests/01.out"] = "200\n"
+	r := buildTarGz(t, files)
+	_, err := ValidateArchive(context.Background(), r, int64(r.Len()), generatorExec{})
+	require.ErrorContains(t, err, `test "01": validator rejected input: n out of range`)
+}
+
+func TestValidateArchive_ValidatorRejectsGeneratedTest(t *testing.T) {
+	files := generatorFiles()
+	files["generator/tests.txt"] = "5\n500\n"
+	r := buildTarGz(t, files)
+	_, err := ValidateArchive(context.Background(), r, int64(r.Len()), generatorExec{})
+	require.ErrorContains(t, err, `test "gen-002": validator rejected input`)
+}
+
+func TestValidateArchive_GeneratedNameConflict(t *testing.T) {
+	files := generatorFiles()
+	files["tests/gen-001.in"] = "1\n"
+	files["tests/gen-001.out"] = "2\n"
+	r := buildTarGz(t, files)
+	_, err := ValidateArchive(context.Background(), r, int64(r.Len()), generatorExec{})
+	require.ErrorContains(t, err, "reserved for generated tests")
+}
+
+// solutionsExec runs the solutions used by the reference/ and wrong/ tests:
+// "ok" prints the expected 3, "wrong" prints 4, "slow" times out, "crash"
+// exits with an error and "broken" does not compile.
+type solutionsExec struct{}
+
+func (solutionsExec) Run(_ context.Context, req executor.ExecutionRequest) (executor.ExecutionResult, error) {
+	switch strings.TrimSpace(req.Code) {
+	case "wrong":
+		return executor.ExecutionResult{Stdout: "4"}, nil
+	case "slow":
+		return executor.ExecutionResult{ExitCode: 124, TimedOut: true}, nil
+	case "crash":
+		return executor.ExecutionResult{ExitCode: 1, Stderr: "IndexError"}, nil
+	case "broken":
+		return executor.ExecutionResult{CompileFailed: true, Stderr: "syntax error"}, nil
+	default:
+		return executor.ExecutionResult{Stdout: "3"}, nil
+	}
+}
+func (solutionsExec) IsReady() bool { return true }
+
+func TestValidateArchive_MultipleReferences(t *testing.T) {
+	files := validFiles()
+	files["reference/solution.py"] = "ok\n"
+	files["reference/solution.go"] = "ok\n"
+	r := buildTarGz(t, files)
+	vps, err := ValidateArchive(context.Background(), r, int64(r.Len()), solutionsExec{})
+	require.NoError(t, err)
+	require.Len(t, vps, 1)
+	t.Cleanup(func() { os.RemoveAll(vps[0].Dir) })
+	require.Len(t, vps[0].References, 2)
+	assert.Equal(t, "solution.go", vps[0].References[0].Name)
+	assert.Equal(t, "go", vps[0].References[0].Language)
+	assert.Equal(t, "python", vps[0].References[1].Language)
+}
+
+func TestValidateArchive_SecondReferenceFails(t *testing.T) {
+	files := validFiles()
+	files["reference/a.py"] = "ok\n"
+	files["reference/b.cpp"] = "wrong\n"
+	delete(files, "reference/solution.py")
+	r := buildTarGz(t, files)
+	_, err := ValidateArchive(context.Background(), r, int64(r.Len()), solutionsExec{})
+	require.ErrorContains(t, err, `reference/b.cpp: test "01": reference solution output "4", expected "3"`)
+}
+
+func TestValidateArchive_WrongSolutionsRejected(t *testing.T) {
+	files := validFiles()
+	files["reference/solution.py"] = "ok\n"
+	files["wrong/wa.py"] = "wrong\n"
+	files["wrong/tle.py"] = "slow\n"
+	r := buildTarGz(t, files)
+	vps, err := ValidateArchive(context.Background(), r, int64(r.Len()), solutionsExec{})
+	require.NoError(t, err)
+	require.Len(t, vps, 1)
+	t.Cleanup(func() { os.RemoveAll(vps[0].Dir) })
+	assert.Len(t, vps[0].WrongSolutions, 2)
+}
+
+func TestValidateArchive_SolutionsRunInBatches(t *testing.T) {
+	files := validFiles()
+	files["reference/solution.py"] = "ok\n"
+	files["wrong/wa.py"] = "wrong\n"
+	for _, name := range []string{"02", "03"} {
+		files["tests/"+name+".in"] = "x\n"
+		files["tests/"+name+".out"] = "3\n"
+	}
+	exec := newBatchCountingExec(solutionsExec{})
+	r := buildTarGz(t, files)
+	vps, err := ValidateArchive(context.Background(), r, int64(r.Len()), exec)
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(vps[0].Dir) })
+
+	assert.Equal(t, 1, exec.batches["ok\n"])
+	assert.Equal(t, 3, exec.runs["ok\n"])
+	assert.Equal(t, 1, exec.batches["wrong\n"])
+	assert.Equal(t, 1, exec.runs["wrong\n"], "stops at the first failed test")
+}
+
+func TestValidateArchive_WrongSolutionAccepted(t *testing.T) {
+	files := validFiles()
+	files["reference/solution.py"] = "ok\n"
+	files["wrong/naive.py"] = "ok\n"
+	r := buildTarGz(t, files)
+	_, err := ValidateArchive(context.Background(), r, int64(r.Len()), solutionsExec{})
+	require.ErrorContains(t, err, "wrong/naive.py: solution passed all tests")
+}
+
+func TestValidateArchive_WrongSolutionDoesNotCompile(t *testing.T) {
+	files := validFiles()
+	files["reference/solution.py"] = "ok\n"
+	files["wrong/typo.py"] = "broken\n"
+	r := buildTarGz(t, files)
+	_, err := ValidateArchive(context.Background(), r, int64(r.Len()), solutionsExec{})
+	require.ErrorContains(t, err, "wrong/typo.py: compilation failed: syntax error")
+}
+
+func TestValidateArchive_WrongSolutionCrashes(t *testing.T) {
+	files := validFiles()
+	files["reference/solution.py"] = "ok\n"
+	files["wrong/crash.py"] = "crash\n"
+	r := buildTarGz(t, files)
+	_, err := ValidateArchive(context.Background(), r, int64(r.Len()), solutionsExec{})
+	require.ErrorContains(t, err, `wrong/crash.py: test "01": got RE, expected WA or TLE: IndexError`)
+}
+
+// interactiveExec plays interactions for the interactive tests: the
+// solutions behave as in solutionsExec, with the interactor rejecting
+// "wrong"; an interactor "crash" fails on every test.
+type interactiveExec struct{ solutionsExec }
+
+func (interactiveExec) RunInteractive(_ context.Context, req executor.InteractiveRequest, onResult func(i int, res executor.InteractiveResult) bool) error {
+	for i := range req.Tests {
+		var res executor.InteractiveResult
+		switch {
+		case strings.TrimSpace(req.Interactor.Code) == "crash":
+			res.Interactor = executor.ExecutionResult{ExitCode: 3, Stderr: "index out of range"}
+		case strings.TrimSpace(req.Code) == "wrong":
+			res.Interactor = executor.ExecutionResult{ExitCode: 1, Stderr: "expected 3, got 4"}
+		case strings.TrimSpace(req.Code) == "slow":
+			res.Program = executor.ExecutionResult{ExitCode: 124, TimedOut: true}
+		case strings.TrimSpace(req.Code) == "broken":
+			res.Program = executor.ExecutionResult{CompileFailed: true, Stderr: "syntax error"}
+		}
+		if !onResult(i, res) || res.Program.CompileFailed {
+			return nil
+		}
+	}
+	return nil
+}
+
+func interactiveFiles() map[string]string {
+	files := validFiles()
+	files["manifest.json"] = `{"title":"Guess","time_limit_ms":1000,"memory_limit_mb":256,"interactive":true}`
+	files["reference/solution.py"] = "ok\n"
+	files["interactor/interactor.py"] = "import sys\n"
+	return files
+}
+
+func TestValidateArchive_Interactive(t *testing.T) {
+	files := interactiveFiles()
+	files["wrong/wa.py"] = "wrong\n"
+	files["wrong/tle.py"] = "slow\n"
+	r := buildTarGz(t, files)
+	vps, err := ValidateArchive(context.Background(), r, int64(r.Len()), interactiveExec{})
+	require.NoError(t, err)
+	require.Len(t, vps, 1)
+	t.Cleanup(func() { os.RemoveAll(vps[0].Dir) })
+	require.NotNil(t, vps[0].Interactor)
+	assert.Equal(t, "python", vps[0].Interactor.Language)
+	assert.Equal(t, CheckerTypeInteractive, checkerType(vps[0].Checker, vps[0].Interactor))
+}
+
+func TestValidateArchive_InteractorRejectsReference(t *testing.T) {
+	files := interactiveFiles()
+	files["reference/solution.py"] = "wrong\n"
+	r := buildTarGz(t, files)
+	_, err := ValidateArchive(context.Background(), r, int64(r.Len()), interactiveExec{})
+	require.ErrorContains(t, err, `test "01": interactor rejected reference solution: expected 3, got 4`)
+}
+
+func TestValidateArchive_InteractorFails(t *testing.T) {
+	files := interactiveFiles()
+	files["interactor/interactor.py"] = "crash\n"
+	r := buildTarGz(t, files)
+	_, err := ValidateArchive(context.Background(), r, int64(r.Len()), interactiveExec{})
+	require.ErrorContains(t, err, "interactor failed with exit code 3: index out of range")
+}
+
+func TestValidateArchive_InteractiveInconsistent(t *testing.T) {
+	cases := map[string]struct {
+		edit func(files map[string]string)
+		want string
+	}{
+		"no interactor": {
+			edit: func(files map[string]string) { delete(files, "interactor/interactor.py") },
+			want: "interactive problems need an interactor/ directory",
+		},
+		"not interactive": {
+			edit: func(files map[string]string) { files["manifest.json"] = validFiles()["manifest.json"] },
+			want: "interactor/ is only used when manifest.json sets interactive",
+		},
+		"with checker": {
+			edit: func(files map[string]string) { files["checker/checker.py"] = "import sys\n" },
+			want: "interactive problems cannot have a checker/",
+		},
+		"with generator": {
+			edit: func(files map[string]string) {
+				files["generator/gen.py"] = "generate()\n"
+				files["generator/tests.txt"] = "7\n"
+			},
+			want: "generator/ is not supported for interactive problems",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			files := interactiveFiles()
+			tc.edit(files)
+			r := buildTarGz(t, files)
+			_, err := ValidateArchive(context.Background(), r, int64(r.Len()), interactiveExec{})
+			require.ErrorContains(t, err, tc.want)
+		})
+	}
+}
+
+func TestValidateArchive_InteractiveUnsupported(t *testing.T) {
+	r := buildTarGz(t, interactiveFiles())
+	_, err := ValidateArchive(context.Background(), r, int64(r.Len()), solutionsExec{})
+	require.ErrorIs(t, err, executor.ErrInteractiveUnsupported)
+}
+
+// harnessExec answers [0, 1] when the code it runs was wrapped in the
+// harness of the twoSum function, and nothing otherwise.
+type harnessExec struct{}
+
+func (harnessExec) Run(_ context.Context, req executor.ExecutionRequest) (executor.ExecutionResult, error) {
+	if strings.Contains(req.Code, "Solution().twoSum(*args)") {
+		return executor.ExecutionResult{Stdout: "[0, 1]\n"}, nil
+	}
+	return executor.ExecutionResult{}, nil
+}
+func (harnessExec) IsReady() bool { return true }
+
+func functionFiles() map[string]string {
+	files := validFiles()
+	files["manifest.json"] = `{"title":"Two Sum","time_limit_ms":1000,"memory_limit_mb":256,"function":{"name":"twoSum",` +
+		`"params":[{"name":"nums","type":"int[]"},{"name":"target","type":"int"}],"returns":"int[]"}}`
+	files["reference/solution.py"] = "class Solution:\n    def twoSum(self, nums, target):\n        return [0, 1]\n"
+	files["tests/01.in"] = "[2,7,11,15]\n9\n"
+	files["tests/01.out"] = "[0,1]\n"
+	return files
+}
+
+func TestValidateArchive_Function(t *testing.T) {
+	r := buildTarGz(t, functionFiles())
+	vps, err := ValidateArchive(context.Background(), r, int64(r.Len()), harnessExec{})
+	require.NoError(t, err)
+	require.Len(t, vps, 1)
+	t.Cleanup(func() { os.RemoveAll(vps[0].Dir) })
+	require.NotNil(t, vps[0].Manifest.Function)
+	assert.Equal(t, "twoSum", vps[0].Manifest.Function.Name)
+}
+
+func TestValidateArchive_FunctionTestMismatch(t *testing.T) {
+	files := functionFiles()
+	files["tests/01.in"] = "[2,7,11,15]\n"
+	r := buildTarGz(t, files)
+	_, err := ValidateArchive(context.Background(), r, int64(r.Len()), harnessExec{})
+	require.ErrorContains(t, err, `test "01": missing argument target`)
+}
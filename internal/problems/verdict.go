@@ -0,0 +1,31 @@
+package problems
+
+import "bytebattle/internal/executor"
+
+type Verdict string
+
+const (
+	VerdictAccepted     Verdict = "AC"
+	VerdictWrongAnswer  Verdict = "WA"
+	VerdictTimeLimit    Verdict = "TLE"
+	VerdictMemoryLimit  Verdict = "MLE"
+	VerdictRuntimeError Verdict = "RE"
+	VerdictCompileError Verdict = "CE"
+)
+
+// RunFailureVerdict classifies a run that did not finish cleanly. It returns
+// "" when the program exited normally and its output still has to be checked.
+func RunFailureVerdict(res executor.ExecutionResult) Verdict {
+	switch {
+	case res.CompileFailed:
+		return VerdictCompileError
+	case res.TimedOut:
+		return VerdictTimeLimit
+	case res.OOMKilled:
+		return VerdictMemoryLimit
+	case res.ExitCode != 0:
+		return VerdictRuntimeError
+	default:
+		return ""
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"bytebattle/internal/service"
 )
 
+// ratingHistoryLimit is how many recent rating changes /auth/me/stats returns.
+const ratingHistoryLimit = 20
+
 func (s *HTTPServer) PostAuthEnter(ctx context.Context, req api.PostAuthEnterRequestObject) (api.PostAuthEnterResponseObject, error) {
 	if err := s.entrance.SendCode(ctx, req.Body.Email); err != nil {
 		return nil, entranceAppErr(err)
@@ -61,13 +64,58 @@ func (s *HTTPServer) GetAuthMeStats(ctx context.Context, _ api.GetAuthMeStatsReq
 	if err != nil {
 		return nil, apierr.New(apierr.ErrInternal, "internal server error")
 	}
+	history, err := s.users.GetRatingHistory(ctx, userID, ratingHistoryLimit)
+	if err != nil {
+		return nil, apierr.New(apierr.ErrInternal, "internal server error")
+	}
+	changes := make([]api.RatingChange, len(history))
+	for i, h := range history {
+		changes[i] = api.RatingChange{
+			GameId:    int(h.GameID),
+			Place:     int(h.Place),
+			OldRating: int(h.OldRating),
+			NewRating: int(h.NewRating),
+			CreatedAt: h.CreatedAt.Time,
+		}
+	}
 	return api.GetAuthMeStats200JSONResponse{
 		Wins:           int(stats.Wins),
 		GamesPlayed:    int(stats.GamesPlayed),
 		ProblemsSolved: int(stats.ProblemsSolved),
+		Rating:         int(stats.Rating),
+		RatingHistory:  changes,
 	}, nil
 }
 
+func (s *HTTPServer) GetLeaderboard(ctx context.Context, req api.GetLeaderboardRequestObject) (api.GetLeaderboardResponseObject, error) {
+	limit := 50
+	if req.Params.Limit != nil && *req.Params.Limit > 0 {
+		limit = min(*req.Params.Limit, 100)
+	}
+	offset := 0
+	if req.Params.Offset != nil && *req.Params.Offset >= 0 {
+		offset = *req.Params.Offset
+	}
+
+	rows, total, err := s.users.GetLeaderboard(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]api.LeaderboardEntry, len(rows))
+	for i, r := range rows {
+		entries[i] = api.LeaderboardEntry{
+			Rank:       offset + i + 1,
+			UserId:     r.ID,
+			Rating:     int(r.Rating),
+			RatedGames: int(r.RatedGames),
+		}
+		if r.Name.Valid {
+			entries[i].Name = &r.Name.String
+		}
+	}
+	return api.GetLeaderboard200JSONResponse{Entries: entries, Total: total}, nil
+}
+
 func (s *HTTPServer) PostAuthLogout(ctx context.Context, _ api.PostAuthLogoutRequestObject) (api.PostAuthLogoutResponseObject, error) {
 	if session, ok := sessionFromContext(ctx); ok {
 		if err := s.sessionService.EndSession(ctx, int(session.ID)); err != nil {
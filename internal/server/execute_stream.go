@@ -0,0 +1,87 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"bytebattle/internal/api"
+	"bytebattle/internal/apierr"
+	"bytebattle/internal/executor"
+	"bytebattle/internal/service"
+)
+
+// handleExecuteStream runs code like PostExecute but streams the program's
+// output as server-sent events while it runs:
+//
+//	event: output  {"stream": "stdout"|"stderr", "data": "..."}
+//	event: result  ExecuteResponse, once the program has finished
+//	event: error   ErrorResponse, when the run failed
+//
+// Requests rejected before the run starts get a plain JSON error response.
+func (s *HTTPServer) handleExecuteStream(w http.ResponseWriter, r *http.Request) {
+	userID, _ := userIDFromContext(r.Context())
+
+	var body api.ExecuteRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeHTTPError(w, apierr.New(apierr.ErrValidation, "invalid request body"))
+		return
+	}
+	if !s.executionService.TryAcquireSlot(userID, service.SlotExecute) {
+		writeHTTPError(w, apierr.New(apierr.ErrExecutionInProgress, "execution already in progress"))
+		return
+	}
+	defer s.executionService.ReleaseSlot(userID, service.SlotExecute)
+	if err := s.executionService.CheckRateLimit(userID); err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+	_ = rc.Flush()
+
+	result, err := s.executionService.ExecuteStream(r.Context(), executor.ExecutionRequest{
+		Code:     body.Code,
+		Language: executor.Language(body.Language),
+		Stdin:    body.Input,
+	}, func(chunk executor.OutputChunk) {
+		writeSSE(w, "output", chunk)
+		_ = rc.Flush()
+	})
+	if err != nil {
+		writeSSE(w, "error", errorResponse(err))
+	} else {
+		writeSSE(w, "result", executeResponse(result))
+	}
+	_ = rc.Flush()
+}
+
+func writeSSE(w http.ResponseWriter, event string, data any) {
+	b, _ := json.Marshal(data)
+	_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b)
+}
+
+func executeResponse(result executor.ExecutionResult) api.ExecuteResponse {
+	return api.ExecuteResponse{
+		Stdout:       result.Stdout,
+		Stderr:       result.Stderr,
+		ExitCode:     result.ExitCode,
+		TimeUsedMs:   int(result.TimeUsed.Milliseconds()),
+		CpuTimeMs:    int(result.CPUTime.Milliseconds()),
+		MemoryUsedKb: int(result.MemoryUsed / 1024),
+	}
+}
+
+// errorResponse is the body writeHTTPError would send for err.
+func errorResponse(err error) api.ErrorResponse {
+	var ae *apierr.AppError
+	if errors.As(err, &ae) {
+		return api.ErrorResponse{ErrorCode: ae.ErrorCode, Message: ae.Message}
+	}
+	return api.ErrorResponse{ErrorCode: apierr.ErrInternal, Message: "internal error"}
+}
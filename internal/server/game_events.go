@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+	"log"
+
+	sqlcdb "bytebattle/internal/db/sqlc"
+	"bytebattle/internal/ws"
+
+	"github.com/google/uuid"
+)
+
+// signalPlayerState asks for a player's connections to be sent their
+// player_state, e.g. once the game they are waiting in starts.
+const signalPlayerState = "player_state"
+
+// handleHubSignal runs for each local connection of a signalled player.
+func (s *HTTPServer) handleHubSignal(gameID int32, c *ws.Client, signal string) {
+	switch signal {
+	case signalSubmissionResults:
+		go s.deliverUndeliveredResults(context.Background(), int(gameID), c.UserID, c)
+	case signalPlayerState:
+		go s.sendPlayerState(context.Background(), int(gameID), c.UserID, c)
+	}
+}
+
+// gameEvents pushes lobby changes made through GameService to the game's
+// WebSocket room.
+type gameEvents struct {
+	s *HTTPServer
+}
+
+func (e gameEvents) ParticipantJoined(ctx context.Context, gameID int32, userID uuid.UUID) {
+	e.s.broadcastEvent(ctx, gameID, ws.ServerMessage{Type: ws.TypeParticipantJoined, UserID: userID})
+}
+
+func (e gameEvents) ParticipantLeft(ctx context.Context, gameID int32, userID uuid.UUID) {
+	e.s.broadcastEvent(ctx, gameID, ws.ServerMessage{Type: ws.TypeParticipantLeft, UserID: userID})
+}
+
+func (e gameEvents) GameStarted(ctx context.Context, game sqlcdb.Game) {
+	msg := ws.ServerMessage{Type: ws.TypeGameStarted}
+	if game.StartedAt.Valid {
+		msg.StartedAt = &game.StartedAt.Time
+	}
+	e.s.broadcastEvent(ctx, game.ID, msg)
+
+	participants, err := e.s.gameService.GetParticipants(ctx, int(game.ID))
+	if err != nil {
+		log.Printf("game %d started: get participants: %v", game.ID, err)
+		return
+	}
+	for _, p := range participants {
+		e.s.hub.Signal(game.ID, p.ID, signalPlayerState)
+	}
+}
+
+func (e gameEvents) GameCancelled(ctx context.Context, game sqlcdb.Game) {
+	e.s.broadcastEvent(ctx, game.ID, ws.ServerMessage{Type: ws.TypeGameCancelled})
+}
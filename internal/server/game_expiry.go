@@ -0,0 +1,38 @@
+package server
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"bytebattle/internal/ws"
+)
+
+const gameExpiryInterval = 5 * time.Second
+
+// expireGamesLoop finishes timed games once their time limit has passed, so a
+// game ends on time even when no client calls POST /games/{id}/timeout.
+func (s *HTTPServer) expireGamesLoop() {
+	ticker := time.NewTicker(gameExpiryInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.expireGames(context.Background())
+	}
+}
+
+func (s *HTTPServer) expireGames(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, gameExpiryInterval)
+	defer cancel()
+
+	games, err := s.gameService.FinishExpiredGames(ctx)
+	if err != nil {
+		log.Printf("expire games: %v", err)
+	}
+	for i := range games {
+		s.broadcastEvent(ctx, games[i].ID, ws.ServerMessage{
+			Type:     ws.TypeGameFinished,
+			WinnerID: games[i].WinnerID.UUID,
+			Reason:   ws.FinishReasonTimeLimit,
+		})
+	}
+}
@@ -73,7 +73,7 @@ func (s *HTTPServer) ListGames(ctx context.Context, req api.ListGamesRequestObje
 	apiGames := make([]api.Game, len(games))
 	for i := range games {
 		showToken := games[i].IsPublic || isParticipantOf(userID, participantMap[games[i].ID])
-		apiGames[i] = toAPIGame(games[i], participantMap[games[i].ID], problemIDsMap[games[i].ID], showToken)
+		apiGames[i] = toAPIGame(games[i], participantMap[games[i].ID], problemIDsMap[games[i].ID], nil, showToken)
 	}
 
 	return api.ListGames200JSONResponse{Games: apiGames, Total: total}, nil
@@ -181,7 +181,11 @@ func (s *HTTPServer) CreateGame(ctx context.Context, req api.CreateGameRequestOb
 		v := int16(*req.Body.TimeLimitMinutes)
 		timeLimitMinutes = &v
 	}
-	game, err := s.gameService.CreateGame(ctx, userID, req.Body.ProblemIds, isPublic, isSolo, timeLimitMinutes)
+	var mode string
+	if req.Body.Mode != nil {
+		mode = string(*req.Body.Mode)
+	}
+	game, err := s.gameService.CreateGame(ctx, userID, req.Body.ProblemIds, isPublic, isSolo, timeLimitMinutes, mode)
 	if err != nil {
 		return nil, err
 	}
@@ -277,10 +281,10 @@ func (s *HTTPServer) CancelGame(ctx context.Context, req api.CancelGameRequestOb
 
 func (s *HTTPServer) PostExecute(ctx context.Context, request api.PostExecuteRequestObject) (api.PostExecuteResponseObject, error) {
 	userID, _ := userIDFromContext(ctx)
-	if !s.executionService.TryAcquireSlot(userID, "execute") {
+	if !s.executionService.TryAcquireSlot(userID, service.SlotExecute) {
 		return nil, apierr.New(apierr.ErrExecutionInProgress, "execution already in progress")
 	}
-	defer s.executionService.ReleaseSlot(userID, "execute")
+	defer s.executionService.ReleaseSlot(userID, service.SlotExecute)
 	if err := s.executionService.CheckRateLimit(userID); err != nil {
 		return nil, err
 	}
@@ -292,12 +296,7 @@ func (s *HTTPServer) PostExecute(ctx context.Context, request api.PostExecuteReq
 	if err != nil {
 		return nil, err
 	}
-	return api.PostExecute200JSONResponse{
-		Stdout:     result.Stdout,
-		Stderr:     result.Stderr,
-		ExitCode:   result.ExitCode,
-		TimeUsedMs: int(result.TimeUsed.Milliseconds()),
-	}, nil
+	return api.PostExecute200JSONResponse(executeResponse(result)), nil
 }
 
 func (s *HTTPServer) GetGameSolutions(ctx context.Context, req api.GetGameSolutionsRequestObject) (api.GetGameSolutionsResponseObject, error) {
@@ -335,6 +334,58 @@ func (s *HTTPServer) GetGameSolutions(ctx context.Context, req api.GetGameSoluti
 	return api.GetGameSolutions200JSONResponse{Solutions: solutions}, nil
 }
 
+func (s *HTTPServer) GetGameSubmissions(ctx context.Context, req api.GetGameSubmissionsRequestObject) (api.GetGameSubmissionsResponseObject, error) {
+	userID, _ := userIDFromContext(ctx)
+	game, err := s.gameService.GetGame(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.gameService.CanAccessGame(ctx, game, userID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.gameService.GetGameSubmissions(ctx, game, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	submissions := make([]api.GameSubmission, len(rows))
+	for i := range rows {
+		r := &rows[i]
+		sub := api.GameSubmission{
+			Id:          r.ID,
+			UserId:      r.UserID,
+			ProblemId:   r.ProblemID,
+			Code:        r.Code,
+			Language:    r.Language,
+			Verdict:     api.GameSubmissionVerdict(r.Verdict),
+			SubmittedAt: r.CreatedAt.Time,
+		}
+		if r.Name.Valid {
+			sub.Name = &r.Name.String
+		}
+		if r.FailedTest.Valid {
+			v := int(r.FailedTest.Int32)
+			sub.FailedTest = &v
+		}
+		if r.Score.Valid {
+			v := int(r.Score.Int32)
+			sub.Score = &v
+		}
+		if r.ExecutionTime.Valid {
+			v := int(r.ExecutionTime.Int32)
+			sub.TimeMs = &v
+		}
+		if r.MemoryUsed.Valid {
+			v := int(r.MemoryUsed.Int32)
+			sub.MemoryKb = &v
+		}
+		submissions[i] = sub
+	}
+
+	return api.GetGameSubmissions200JSONResponse{Submissions: submissions}, nil
+}
+
 func (s *HTTPServer) GetGameByToken(ctx context.Context, req api.GetGameByTokenRequestObject) (api.GetGameByTokenResponseObject, error) {
 	game, err := s.gameService.GetGameByToken(ctx, req.InviteToken)
 	if err != nil {
@@ -369,18 +420,34 @@ func (s *HTTPServer) enrichGame(ctx context.Context, game sqlcdb.Game, showToken
 	if err != nil {
 		return api.Game{}, err
 	}
-	return toAPIGame(game, participants, problemIDs, showToken), nil
+	var scores map[string][]int
+	if game.Mode == service.GameModeScoring {
+		if scores, err = s.gameService.GetGameScores(ctx, int(game.ID)); err != nil {
+			return api.Game{}, err
+		}
+	}
+	return toAPIGame(game, participants, problemIDs, scores, showToken), nil
 }
 
-func toAPIGame(g sqlcdb.Game, participants []service.Participant, problemIDs []string, showToken bool) api.Game {
+// toAPIGame converts a game. scores, the participants' scores in a scoring
+// game, may be nil to leave them out.
+func toAPIGame(g sqlcdb.Game, participants []service.Participant, problemIDs []string, scores map[string][]int, showToken bool) api.Game {
 	apiParticipants := make([]api.GameParticipant, len(participants))
 	for i, p := range participants {
 		apiParticipants[i] = api.GameParticipant{Id: p.ID, Name: p.Name}
+		if scores != nil {
+			ps, ok := scores[p.ID.String()]
+			if !ok {
+				ps = make([]int, len(problemIDs))
+			}
+			apiParticipants[i].Scores = &ps
+		}
 	}
 	result := api.Game{
 		Id:           int(g.ID),
 		IsPublic:     g.IsPublic,
 		IsSolo:       g.IsSolo,
+		Mode:         api.GameMode(g.Mode),
 		ProblemIds:   problemIDs,
 		CreatorId:    g.CreatorID,
 		Status:       api.GameStatus(g.Status),
@@ -430,6 +497,24 @@ func isParticipantOf(userID uuid.UUID, participants []service.Participant) bool
 
 func toAPIProblem(p *problems.Problem) api.Problem {
 	testCount := len(p.TestCases)
+	sampleTests := p.SampleTests()
+	samples := make([]api.ProblemSample, len(sampleTests))
+	for i, tc := range sampleTests {
+		samples[i] = api.ProblemSample{Input: tc.Input, Output: tc.Expected}
+	}
+	maxScore := p.MaxScore()
+	var groups *[]api.ProblemGroup
+	if len(p.Manifest.Groups) > 0 {
+		gs := make([]api.ProblemGroup, len(p.Manifest.Groups))
+		for i, g := range p.Manifest.Groups {
+			gs[i] = api.ProblemGroup{Name: g.Name, Points: g.Points}
+		}
+		groups = &gs
+	}
+	var starterCode *map[string]string
+	if code := p.Manifest.StarterCode(); code != nil {
+		starterCode = &code
+	}
 	return api.Problem{
 		Id:            p.Slug,
 		Title:         p.Manifest.Title,
@@ -438,6 +523,10 @@ func toAPIProblem(p *problems.Problem) api.Problem {
 		TimeLimitMs:   p.Manifest.TimeLimitMs,
 		MemoryLimitMb: p.Manifest.MemoryLimitMb,
 		TestCount:     &testCount,
+		Samples:       &samples,
+		MaxScore:      &maxScore,
+		Groups:        groups,
+		StarterCode:   starterCode,
 	}
 }
 
@@ -467,7 +556,24 @@ func (s *HTTPServer) handleGameWS(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "game not found", http.StatusNotFound)
 		return
 	}
-	if game.Status != "active" {
+
+	// last_seq is the sequence number of the last room event the client has
+	// seen; the events after it are replayed before new ones are delivered.
+	lastSeq := int64(-1)
+	if v := r.URL.Query().Get("last_seq"); v != "" {
+		lastSeq, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || lastSeq < 0 {
+			http.Error(w, "invalid last_seq", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Pending games have a lobby room. A client that lost its connection
+	// around the end of a game may still reconnect to a finished or cancelled
+	// game to replay the events it missed.
+	pending := game.Status == "pending"
+	replayOnly := (game.Status == "finished" || game.Status == "cancelled") && lastSeq >= 0
+	if game.Status != "active" && !pending && !replayOnly {
 		http.Error(w, "game is not active", http.StatusBadRequest)
 		return
 	}
@@ -477,7 +583,9 @@ func (s *HTTPServer) handleGameWS(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "internal error", http.StatusInternalServerError)
 		return
 	}
-	if !ok {
+	// Anyone may watch a public game as a spectator.
+	spectator := !ok
+	if spectator && !game.IsPublic {
 		writeHTTPError(w, apierr.New(apierr.ErrNotParticipant, "not a participant"))
 		return
 	}
@@ -492,20 +600,44 @@ func (s *HTTPServer) handleGameWS(w http.ResponseWriter, r *http.Request) {
 	}
 
 	client := ws.NewClient(conn, session.UserID)
-	s.hub.Join(int32(gameID), client)
-	defer s.hub.Leave(int32(gameID), client)
-	defer client.Close() // signals WritePump to exit cleanly
-
+	client.Spectator = spectator
 	go client.WritePump()
 
-	joinedMsg, _ := json.Marshal(ws.ServerMessage{
-		Type:   ws.TypePlayerJoined,
-		UserID: session.UserID,
+	if replayOnly {
+		s.replayEvents(r.Context(), int32(gameID), lastSeq, client)
+		client.Close()
+		return
+	}
+
+	s.hub.JoinAfter(int32(gameID), client, func() {
+		if lastSeq >= 0 {
+			s.replayEvents(r.Context(), int32(gameID), lastSeq, client)
+		}
 	})
-	s.hub.Broadcast(int32(gameID), joinedMsg)
+	defer func() {
+		s.hub.Leave(int32(gameID), client)
+		if spectator {
+			s.broadcastSpectatorCount(int32(gameID))
+		}
+	}()
+	defer client.Close() // signals WritePump to exit cleanly
+
+	if spectator {
+		s.broadcastSpectatorCount(int32(gameID))
+		s.sendSpectatorState(r.Context(), gameID, client)
+	} else {
+		s.broadcastEvent(r.Context(), int32(gameID), ws.ServerMessage{
+			Type:   ws.TypePlayerJoined,
+			UserID: session.UserID,
+		})
 
-	// Send initial problem state to the connecting player.
-	s.sendPlayerState(r.Context(), gameID, session.UserID, client)
+		// Send initial problem state to the connecting player; the problems
+		// stay hidden until the game starts.
+		if !pending {
+			s.sendPlayerState(r.Context(), gameID, session.UserID, client)
+			s.deliverUndeliveredResults(r.Context(), gameID, session.UserID, client)
+		}
+	}
 
 	connCtx, connCancel := context.WithCancel(r.Context())
 	defer connCancel()
@@ -526,70 +658,19 @@ func (s *HTTPServer) handleGameWS(w http.ResponseWriter, r *http.Request) {
 		}
 
 		var msg ws.ClientMessage
-		if err := json.Unmarshal(data, &msg); err != nil || msg.Type != ws.TypeSubmit {
+		if err := json.Unmarshal(data, &msg); err != nil || (msg.Type != ws.TypeSubmit && msg.Type != ws.TypeRun) {
 			continue
 		}
-
-		go s.processSubmit(connCtx, int32(gameID), session.UserID, msg)
-	}
-}
-
-func (s *HTTPServer) processSubmit(ctx context.Context, gameID int32, userID uuid.UUID, msg ws.ClientMessage) {
-	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
-	defer cancel()
-
-	result, err := s.submissionService.Submit(ctx, int(gameID), userID, msg.Code, executor.Language(msg.Language))
-	if err != nil {
-		log.Printf("processSubmit: %v", err)
-		var appErr *apierr.AppError
-		if errors.As(err, &appErr) {
-			s.broadcastError(gameID, userID, appErr.ErrorCode, appErr.Message)
-		} else {
-			s.broadcastError(gameID, userID, apierr.ErrInternal, "internal error")
+		if spectator {
+			client.Send(wsErrorMessage(uuid.Nil, apierr.New(apierr.ErrNotParticipant, "spectators cannot run code")))
+			continue
 		}
-		return
-	}
-
-	if result.AlreadyAdvanced {
-		return
-	}
-
-	resultMsg, _ := json.Marshal(ws.ServerMessage{
-		Type:       ws.TypeSubmissionResult,
-		UserID:     userID,
-		Accepted:   result.Accepted,
-		Stdout:     result.Stdout,
-		Stderr:     result.Stderr,
-		FailedTest: result.FailedTest,
-	})
-	s.hub.SendToUser(gameID, userID, resultMsg)
-
-	if !result.Accepted {
-		return
-	}
-
-	if result.WinnerID != uuid.Nil {
-		finMsg, _ := json.Marshal(ws.ServerMessage{
-			Type:     ws.TypeGameFinished,
-			WinnerID: result.WinnerID,
-		})
-		s.hub.Broadcast(gameID, finMsg)
-		return
-	}
 
-	if result.ProblemID != "" {
-		progress, err := s.gameService.GetAllParticipantsProblemIndices(ctx, int(gameID))
-		if err != nil {
-			log.Printf("processSubmit: get progress: %v", err)
+		if msg.Type == ws.TypeRun {
+			go s.processRun(connCtx, gameID, client, msg)
+			continue
 		}
-		advMsg, _ := json.Marshal(ws.ServerMessage{
-			Type:       ws.TypePlayerAdvanced,
-			UserID:     userID,
-			ProblemID:  result.ProblemID,
-			ProblemIdx: result.ProblemIdx,
-			Progress:   progress,
-		})
-		s.hub.Broadcast(gameID, advMsg)
+		s.enqueueSubmission(connCtx, int32(gameID), session.UserID, msg)
 	}
 }
 
@@ -603,15 +684,55 @@ func (s *HTTPServer) sendPlayerState(ctx context.Context, gameID int, userID uui
 		return
 	}
 	progress, _ := s.gameService.GetAllParticipantsProblemIndices(ctx, gameID)
+	scores, _ := s.gameService.GetGameScores(ctx, gameID)
+	spectators := s.hub.SpectatorCount(int32(gameID))
 	stateMsg, _ := json.Marshal(ws.ServerMessage{
-		Type:       ws.TypePlayerState,
-		ProblemID:  problemID,
-		ProblemIdx: int(playerIdx),
-		Progress:   progress,
+		Type:           ws.TypePlayerState,
+		ProblemID:      problemID,
+		ProblemIdx:     int(playerIdx),
+		Progress:       progress,
+		Scores:         scores,
+		SpectatorCount: &spectators,
 	})
 	client.Send(stateMsg)
 }
 
+// sendSpectatorState sends a new spectator everyone's progress and scores.
+func (s *HTTPServer) sendSpectatorState(ctx context.Context, gameID int, client *ws.Client) {
+	progress, err := s.gameService.GetAllParticipantsProblemIndices(ctx, gameID)
+	if err != nil {
+		return
+	}
+	scores, _ := s.gameService.GetGameScores(ctx, gameID)
+	spectators := s.hub.SpectatorCount(int32(gameID))
+	stateMsg, _ := json.Marshal(ws.ServerMessage{
+		Type:           ws.TypeSpectatorState,
+		Progress:       progress,
+		Scores:         scores,
+		SpectatorCount: &spectators,
+	})
+	client.Send(stateMsg)
+}
+
+// broadcastSpectatorCount tells the room, on every instance, how many
+// spectators it has after one joined or left here. The count is not a logged
+// event: only the latest one matters.
+func (s *HTTPServer) broadcastSpectatorCount(gameID int32) {
+	s.hub.AnnounceSpectators(gameID)
+	s.sendSpectatorCount(gameID)
+}
+
+// sendSpectatorCount tells the room's clients on this instance how many
+// spectators it has.
+func (s *HTTPServer) sendSpectatorCount(gameID int32) {
+	spectators := s.hub.SpectatorCount(gameID)
+	msg, _ := json.Marshal(ws.ServerMessage{
+		Type:           ws.TypeSpectators,
+		SpectatorCount: &spectators,
+	})
+	s.hub.BroadcastLocal(gameID, msg)
+}
+
 func (s *HTTPServer) handleUploadProblem(w http.ResponseWriter, r *http.Request) {
 	userID, _ := userIDFromContext(r.Context())
 
@@ -745,6 +866,28 @@ func mapUploadError(err error) error {
 	return apierr.New(apierr.ErrInternal, "upload failed")
 }
 
+// broadcastEvent records msg in the game's event log and broadcasts it with
+// its sequence number. If it cannot be recorded it is still broadcast, just
+// without a number.
+func (s *HTTPServer) broadcastEvent(ctx context.Context, gameID int32, msg ws.ServerMessage) {
+	payload, _ := json.Marshal(msg)
+	logged, err := s.gameService.AppendEvent(ctx, gameID, payload)
+	if err != nil {
+		log.Printf("append event to game %d: %v", gameID, err)
+		logged = payload
+	}
+	s.hub.Broadcast(gameID, logged)
+}
+
+func (s *HTTPServer) replayEvents(ctx context.Context, gameID int32, afterSeq int64, client *ws.Client) {
+	events, err := s.gameService.EventsAfter(ctx, gameID, afterSeq)
+	if err != nil {
+		log.Printf("replay events of game %d: %v", gameID, err)
+		return
+	}
+	client.SendAll(events)
+}
+
 func (s *HTTPServer) broadcastError(gameID int32, userID uuid.UUID, code, msg string) {
 	errMsg, _ := json.Marshal(ws.ServerMessage{
 		Type:      ws.TypeError,
@@ -1,12 +1,14 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"bytebattle/internal/api"
@@ -18,6 +20,7 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/go-chi/httprate"
+	"github.com/google/uuid"
 	gorillaws "github.com/gorilla/websocket"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -34,6 +37,14 @@ func httpRatePerMinute() int {
 	return n
 }
 
+func submissionWorkerCount() int {
+	n, err := strconv.Atoi(os.Getenv("SUBMISSION_WORKERS"))
+	if err != nil || n <= 0 {
+		return defaultSubmissionWorkers
+	}
+	return n
+}
+
 func allowedOrigins() []string {
 	v := os.Getenv("ALLOWED_ORIGINS")
 	if v == "" {
@@ -72,6 +83,16 @@ type HTTPServer struct {
 	submissionService *service.SubmissionService
 	hub               *ws.Hub
 	entrance          service.EntranceService
+
+	// instanceID identifies this process in the submission queue.
+	instanceID     string
+	submissionWake chan struct{}
+
+	router http.Handler
+	// stop ends the background work started by New, which Shutdown waits
+	// for in workers.
+	stop    context.CancelFunc
+	workers sync.WaitGroup
 }
 
 func New(
@@ -84,7 +105,8 @@ func New(
 	submissionService *service.SubmissionService,
 	hub *ws.Hub,
 	entrance service.EntranceService,
-) http.Handler {
+) *HTTPServer {
+	ctx, stop := context.WithCancel(context.Background())
 	s := &HTTPServer{
 		pool:              pool,
 		users:             users,
@@ -95,7 +117,15 @@ func New(
 		submissionService: submissionService,
 		hub:               hub,
 		entrance:          entrance,
+		instanceID:        uuid.NewString(),
+		submissionWake:    make(chan struct{}, 1),
+		stop:              stop,
 	}
+	hub.HandleSignals(s.handleHubSignal)
+	hub.HandleSpectators(s.sendSpectatorCount)
+	gameService.SetNotifier(gameEvents{s})
+	go s.expireGamesLoop()
+	s.startSubmissionWorkers(ctx, submissionWorkerCount())
 
 	origins := allowedOrigins()
 	corsAllowed := origins
@@ -120,6 +150,7 @@ func New(
 	r.Get("/health", s.handleHealth)
 	r.Get("/", s.handleRoot)
 	r.Get("/api/games/{id}/ws", s.handleGameWS)
+	r.With(s.requireAuth).Post("/api/execute/stream", s.handleExecuteStream)
 	r.With(s.requireAuth).Post("/api/problems", s.handleUploadProblem)
 	r.With(s.requireAuth).Post("/api/problems/{slug}/versions", s.handleUploadProblemVersion)
 
@@ -131,7 +162,29 @@ func New(
 	strictHandler := api.NewStrictHandlerWithOptions(s, []api.StrictMiddlewareFunc{s.strictAuthMiddleware(publicOps)}, strictOpts)
 	api.HandlerFromMuxWithBaseURL(strictHandler, r, "/api")
 
-	return r
+	s.router = r
+	return s
+}
+
+func (s *HTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.router.ServeHTTP(w, r)
+}
+
+// Shutdown stops the submission workers and waits for the jobs they are
+// running to be put back in the queue or finished, or for ctx to end. The
+// hub is closed after them, as their results may still be published.
+func (s *HTTPServer) Shutdown(ctx context.Context) error {
+	s.stop()
+	done := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	return s.hub.Close(ctx)
 }
 
 func requestErrorHandler(w http.ResponseWriter, _ *http.Request, err error) {
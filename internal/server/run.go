@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"bytebattle/internal/executor"
+	"bytebattle/internal/problems"
+	"bytebattle/internal/ws"
+)
+
+// processRun executes a "run" message and sends the result to the
+// connection it came from only.
+func (s *HTTPServer) processRun(ctx context.Context, gameID int, client *ws.Client, msg ws.ClientMessage) {
+	result, err := s.submissionService.Run(ctx, gameID, client.UserID, msg.ProblemIndex, msg.Code, executor.Language(msg.Language), msg.Input)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("run: %v", err)
+		}
+		client.Send(wsErrorMessage(client.UserID, err))
+		return
+	}
+
+	tests := make([]ws.TestResult, len(result.Tests))
+	for i, tr := range result.Tests {
+		tests[i] = ws.TestResult{Verdict: string(tr.Verdict), TimeMs: tr.TimeMs, MemoryKb: tr.MemoryKb}
+	}
+	out := ws.ServerMessage{
+		Type:       ws.TypeRunResult,
+		UserID:     client.UserID,
+		Accepted:   result.Verdict == problems.VerdictAccepted,
+		Verdict:    string(result.Verdict),
+		Tests:      tests,
+		Stdout:     result.Stdout,
+		Stderr:     result.Stderr,
+		FailedTest: result.FailedTest,
+		Input:      result.Input,
+		Expected:   result.Expected,
+		TimeMs:     result.TimeMs,
+		MemoryKb:   result.MemoryKb,
+	}
+	if msg.Input != nil {
+		out.ExitCode = &result.ExitCode
+	}
+	data, _ := json.Marshal(out)
+	client.Send(data)
+}
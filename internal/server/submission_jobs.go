@@ -0,0 +1,253 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"bytebattle/internal/apierr"
+	sqlcdb "bytebattle/internal/db/sqlc"
+	"bytebattle/internal/executor"
+	"bytebattle/internal/service"
+	"bytebattle/internal/ws"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultSubmissionWorkers = 8
+	submissionPollInterval   = time.Second
+	submissionJobTimeout     = 2 * time.Minute
+	// A job still running after submissionStaleAfter lost its worker.
+	submissionStaleAfter = 5 * time.Minute
+	// Jobs queued by another instance are taken over once they have waited
+	// this long, e.g. because that instance was restarted.
+	submissionOrphanedAfter = 15 * time.Second
+
+	// signalSubmissionResults tells the instance a player is connected to
+	// that they have results waiting.
+	signalSubmissionResults = "submission_results"
+)
+
+// enqueueSubmission stores a submit message as a job. Judging happens on the
+// submission workers, so neither a dropped connection nor a restart loses it.
+func (s *HTTPServer) enqueueSubmission(ctx context.Context, gameID int32, userID uuid.UUID, msg ws.ClientMessage) {
+	_, err := s.submissionService.Enqueue(ctx, int(gameID), userID, msg.ProblemIndex, msg.Code, executor.Language(msg.Language), s.instanceID)
+	if err != nil {
+		log.Printf("enqueue submission: %v", err)
+		var appErr *apierr.AppError
+		if errors.As(err, &appErr) {
+			s.broadcastError(gameID, userID, appErr.ErrorCode, appErr.Message)
+		} else {
+			s.broadcastError(gameID, userID, apierr.ErrInternal, "internal error")
+		}
+		return
+	}
+	select {
+	case s.submissionWake <- struct{}{}:
+	default:
+	}
+}
+
+// startSubmissionWorkers runs n submission workers until ctx is done.
+func (s *HTTPServer) startSubmissionWorkers(ctx context.Context, n int) {
+	s.workers.Add(n + 1)
+	for range n {
+		go s.submissionWorker(ctx)
+	}
+	go s.requeueStaleSubmissionsLoop(ctx)
+}
+
+func (s *HTTPServer) submissionWorker(ctx context.Context) {
+	defer s.workers.Done()
+	for {
+		for ctx.Err() == nil && s.processNextSubmission(ctx) {
+		}
+		select {
+		case <-s.submissionWake:
+		case <-time.After(submissionPollInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *HTTPServer) requeueStaleSubmissionsLoop(ctx context.Context) {
+	defer s.workers.Done()
+	ticker := time.NewTicker(submissionStaleAfter / 5)
+	defer ticker.Stop()
+	for {
+		n, err := s.submissionService.RequeueStaleJobs(ctx, submissionStaleAfter)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("requeue stale submissions: %v", err)
+		} else if n > 0 {
+			log.Printf("requeued %d stale submissions", n)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// processNextSubmission runs one job and reports whether there was one.
+func (s *HTTPServer) processNextSubmission(ctx context.Context) bool {
+	job, ok, err := s.submissionService.ClaimJob(ctx, s.instanceID, submissionOrphanedAfter)
+	if err != nil {
+		log.Printf("claim submission: %v", err)
+		return false
+	}
+	if !ok {
+		return false
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, submissionJobTimeout)
+	result, err := s.submissionService.RunJob(runCtx, job)
+	cancel()
+	// A job interrupted by shutdown is still put back in the queue, for
+	// another instance to run.
+	ctx = context.WithoutCancel(ctx)
+	if err != nil {
+		log.Printf("submission job %d: %v", job.ID, err)
+		retried, retryErr := s.submissionService.RetryJob(ctx, job, err)
+		if retryErr != nil {
+			log.Printf("retry submission job %d: %v", job.ID, retryErr)
+		}
+		if !retried {
+			s.finishSubmission(ctx, job, wsErrorMessage(job.UserID, err), err)
+		}
+		return true
+	}
+
+	if result.AlreadyAdvanced {
+		s.finishSubmission(ctx, job, nil, nil)
+		return true
+	}
+	s.finishSubmission(ctx, job, submissionResultMessage(job.UserID, result), nil)
+	s.broadcastSubmissionOutcome(ctx, job.GameID, job.UserID, result)
+	return true
+}
+
+// finishSubmission stores the job's outcome and pushes msg to the player if
+// they are connected; otherwise it is sent when they reconnect.
+func (s *HTTPServer) finishSubmission(ctx context.Context, job sqlcdb.SubmissionJob, msg []byte, jobErr error) {
+	if err := s.submissionService.FinishJob(ctx, job, msg, jobErr); err != nil {
+		log.Printf("finish submission job %d: %v", job.ID, err)
+	}
+	if msg == nil {
+		return
+	}
+	if !s.hub.HasUser(job.GameID, job.UserID) {
+		// The player may be connected to another instance.
+		s.hub.Signal(job.GameID, job.UserID, signalSubmissionResults)
+		return
+	}
+	if ok, err := s.submissionService.ClaimDelivery(ctx, job.ID); err != nil || !ok {
+		return
+	}
+	s.hub.SendToUser(job.GameID, job.UserID, msg)
+}
+
+// deliverUndeliveredResults sends a reconnecting player the results of
+// submissions that finished while they were away.
+func (s *HTTPServer) deliverUndeliveredResults(ctx context.Context, gameID int, userID uuid.UUID, client *ws.Client) {
+	rows, err := s.submissionService.UndeliveredResults(ctx, gameID, userID)
+	if err != nil {
+		log.Printf("list undelivered submissions: %v", err)
+		return
+	}
+	for _, row := range rows {
+		if ok, err := s.submissionService.ClaimDelivery(ctx, row.ID); err == nil && ok {
+			client.Send(row.Result)
+		}
+	}
+}
+
+// wsErrorMessage reports err to a player: application errors as they are,
+// anything else as an internal error.
+func wsErrorMessage(userID uuid.UUID, err error) []byte {
+	code, message := apierr.ErrInternal, "internal error"
+	var appErr *apierr.AppError
+	if errors.As(err, &appErr) {
+		code, message = appErr.ErrorCode, appErr.Message
+	}
+	msg, _ := json.Marshal(ws.ServerMessage{
+		Type:      ws.TypeError,
+		UserID:    userID,
+		ErrorCode: code,
+		Message:   message,
+	})
+	return msg
+}
+
+func submissionResultMessage(userID uuid.UUID, result service.SubmissionResult) []byte {
+	tests := make([]ws.TestResult, len(result.Tests))
+	for i, tr := range result.Tests {
+		tests[i] = ws.TestResult{Verdict: string(tr.Verdict), TimeMs: tr.TimeMs, MemoryKb: tr.MemoryKb}
+	}
+	var groups []ws.GroupResult
+	for _, g := range result.Groups {
+		groups = append(groups, ws.GroupResult{Name: g.Name, Points: g.Points, MaxPoints: g.MaxPoints, Passed: g.Passed})
+	}
+	score := result.Score
+	msg, _ := json.Marshal(ws.ServerMessage{
+		Type:       ws.TypeSubmissionResult,
+		UserID:     userID,
+		Accepted:   result.Accepted,
+		Verdict:    string(result.Verdict),
+		Tests:      tests,
+		Stdout:     result.Stdout,
+		Stderr:     result.Stderr,
+		FailedTest: result.FailedTest,
+		Input:      result.Input,
+		Expected:   result.Expected,
+		ProblemIdx: result.ProblemIdx,
+		Score:      &score,
+		MaxScore:   result.MaxScore,
+		Groups:     groups,
+	})
+	return msg
+}
+
+// broadcastSubmissionOutcome tells the room that a player won, moved on to
+// the next problem or improved their score.
+func (s *HTTPServer) broadcastSubmissionOutcome(ctx context.Context, gameID int32, userID uuid.UUID, result service.SubmissionResult) {
+	if result.WinnerID != uuid.Nil {
+		s.broadcastEvent(ctx, gameID, ws.ServerMessage{
+			Type:     ws.TypeGameFinished,
+			WinnerID: result.WinnerID,
+		})
+		return
+	}
+
+	if result.ScoreImproved {
+		scores, err := s.gameService.GetGameScores(ctx, int(gameID))
+		if err != nil {
+			log.Printf("broadcast submission outcome: get scores: %v", err)
+		}
+		s.broadcastEvent(ctx, gameID, ws.ServerMessage{
+			Type:       ws.TypeScoreUpdated,
+			UserID:     userID,
+			ProblemIdx: result.ProblemIdx,
+			Scores:     scores,
+		})
+		return
+	}
+
+	if result.Accepted && result.ProblemID != "" {
+		progress, err := s.gameService.GetAllParticipantsProblemIndices(ctx, int(gameID))
+		if err != nil {
+			log.Printf("broadcast submission outcome: get progress: %v", err)
+		}
+		s.broadcastEvent(ctx, gameID, ws.ServerMessage{
+			Type:       ws.TypePlayerAdvanced,
+			UserID:     userID,
+			ProblemID:  result.ProblemID,
+			ProblemIdx: result.ProblemIdx,
+			Progress:   progress,
+		})
+	}
+}
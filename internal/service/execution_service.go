@@ -22,9 +22,15 @@ var DefaultRateLimitConfig = RateLimitConfig{
 	Burst: 10,
 }
 
+// Slot kinds: a user may have one execution of each kind at a time.
+const (
+	SlotExecute = "execute" // POST /execute
+	SlotRun     = "run"     // "run" messages on the game WebSocket
+)
+
 type slotKey struct {
 	userID uuid.UUID
-	kind   string // execute/submit
+	kind   string // SlotExecute/SlotRun
 }
 
 type ExecutionService struct {
@@ -89,6 +95,24 @@ func (s *ExecutionService) Execute(ctx context.Context, req executor.ExecutionRe
 	return s.executor.Run(ctx, req)
 }
 
+// ExecuteStream runs req like Execute, passing the program's output to
+// onOutput as it is produced when the executor can stream it.
+func (s *ExecutionService) ExecuteStream(ctx context.Context, req executor.ExecutionRequest, onOutput func(executor.OutputChunk)) (executor.ExecutionResult, error) {
+	return executor.RunStream(ctx, s.executor, req, onOutput)
+}
+
+// ExecuteBatch compiles the program once and runs it against every input when
+// the executor supports it, falling back to one Execute per input otherwise.
+func (s *ExecutionService) ExecuteBatch(ctx context.Context, req executor.BatchRequest, onResult func(i int, res executor.ExecutionResult) bool) error {
+	return executor.RunBatch(ctx, s.executor, req, onResult)
+}
+
+// ExecuteInteractive runs req when the executor supports interactive
+// problems and fails with executor.ErrInteractiveUnsupported otherwise.
+func (s *ExecutionService) ExecuteInteractive(ctx context.Context, req executor.InteractiveRequest, onResult func(i int, res executor.InteractiveResult) bool) error {
+	return executor.RunInteractive(ctx, s.executor, req, onResult)
+}
+
 func (s *ExecutionService) TryAcquireSlot(userID uuid.UUID, kind string) bool {
 	k := slotKey{userID, kind}
 	ch, _ := s.slots.LoadOrStore(k, make(chan struct{}, 1))
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+
+	sqlcdb "bytebattle/internal/db/sqlc"
+
+	"github.com/google/uuid"
+)
+
+// MaxReplayEvents bounds how many missed events a reconnecting client is sent.
+const MaxReplayEvents = 1000
+
+// AppendEvent adds a room event to the game's event log and returns payload
+// with the event's sequence number set as "seq".
+func (s *GameService) AppendEvent(ctx context.Context, gameID int32, payload []byte) ([]byte, error) {
+	return s.q.AppendGameEvent(ctx, sqlcdb.AppendGameEventParams{GameID: gameID, Payload: payload})
+}
+
+// EventsAfter returns the game's events numbered after seq, oldest first.
+func (s *GameService) EventsAfter(ctx context.Context, gameID int32, seq int64) ([][]byte, error) {
+	return s.q.ListGameEventsAfter(ctx, sqlcdb.ListGameEventsAfterParams{
+		GameID:    gameID,
+		AfterSeq:  seq,
+		MaxEvents: MaxReplayEvents,
+	})
+}
+
+// GameNotifier is told about lobby changes once they are committed, so that
+// they can be pushed to the game's WebSocket room.
+type GameNotifier interface {
+	ParticipantJoined(ctx context.Context, gameID int32, userID uuid.UUID)
+	ParticipantLeft(ctx context.Context, gameID int32, userID uuid.UUID)
+	GameStarted(ctx context.Context, game sqlcdb.Game)
+	GameCancelled(ctx context.Context, game sqlcdb.Game)
+}
+
+type nopGameNotifier struct{}
+
+func (nopGameNotifier) ParticipantJoined(context.Context, int32, uuid.UUID) {}
+func (nopGameNotifier) ParticipantLeft(context.Context, int32, uuid.UUID)   {}
+func (nopGameNotifier) GameStarted(context.Context, sqlcdb.Game)            {}
+func (nopGameNotifier) GameCancelled(context.Context, sqlcdb.Game)          {}
+
+// SetNotifier sets the notifier told about lobby changes; by default nobody
+// is.
+func (s *GameService) SetNotifier(n GameNotifier) {
+	s.notifier = n
+}
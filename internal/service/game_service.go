@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"bytebattle/internal/apierr"
 	sqlcdb "bytebattle/internal/db/sqlc"
@@ -25,20 +26,27 @@ const (
 	gameStatusFinished  = "finished"
 	gameStatusCancelled = "cancelled"
 	maxGameProblems     = 20 // sync with CHECK (problem_index < 20) in migration 000009
+
+	// In a race players solve the problems in order and the first to solve
+	// them all wins. In a scoring game they may submit to any problem, earn
+	// points per test group and the highest total when time runs out wins.
+	GameModeRace    = "race"
+	GameModeScoring = "scoring"
 )
 
 var errGameAlreadyFinished = errors.New("game already finished")
 
 type GameService struct {
-	q    *sqlcdb.Queries
-	pool *pgxpool.Pool
+	q        *sqlcdb.Queries
+	pool     *pgxpool.Pool
+	notifier GameNotifier
 }
 
 func NewGameService(q *sqlcdb.Queries, pool *pgxpool.Pool) *GameService {
-	return &GameService{q: q, pool: pool}
+	return &GameService{q: q, pool: pool, notifier: nopGameNotifier{}}
 }
 
-func (s *GameService) CreateGame(ctx context.Context, creatorID uuid.UUID, problemSlugs []string, isPublic, isSolo bool, timeLimitMinutes *int16) (sqlcdb.Game, error) {
+func (s *GameService) CreateGame(ctx context.Context, creatorID uuid.UUID, problemSlugs []string, isPublic, isSolo bool, timeLimitMinutes *int16, mode string) (sqlcdb.Game, error) {
 	if len(problemSlugs) == 0 {
 		return sqlcdb.Game{}, apierr.New(apierr.ErrValidation, "at least one problem is required")
 	}
@@ -50,6 +58,17 @@ func (s *GameService) CreateGame(ctx context.Context, creatorID uuid.UUID, probl
 			return sqlcdb.Game{}, apierr.New(apierr.ErrValidation, "problem slug cannot be empty")
 		}
 	}
+	switch mode {
+	case "":
+		mode = GameModeRace
+	case GameModeRace:
+	case GameModeScoring:
+		if timeLimitMinutes == nil {
+			return sqlcdb.Game{}, apierr.New(apierr.ErrValidation, "scoring games need a time limit")
+		}
+	default:
+		return sqlcdb.Game{}, apierr.New(apierr.ErrValidation, "unknown game mode")
+	}
 
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
@@ -68,6 +87,7 @@ func (s *GameService) CreateGame(ctx context.Context, creatorID uuid.UUID, probl
 		IsPublic:         isPublic,
 		IsSolo:           isSolo,
 		TimeLimitMinutes: timeLimitPgx,
+		Mode:             mode,
 	})
 	if err != nil {
 		return sqlcdb.Game{}, err
@@ -167,6 +187,8 @@ func (s *GameService) JoinGame(ctx context.Context, gameID int, userID uuid.UUID
 		return sqlcdb.Game{}, err
 	}
 
+	s.notifier.ParticipantJoined(ctx, game.ID, userID)
+
 	return game, nil
 }
 
@@ -362,6 +384,8 @@ func (s *GameService) StartGame(ctx context.Context, id int, userID uuid.UUID) (
 		return sqlcdb.Game{}, err
 	}
 
+	s.notifier.GameStarted(ctx, game)
+
 	return game, nil
 }
 
@@ -391,24 +415,114 @@ func (s *GameService) HandleAcceptedSubmission(ctx context.Context, id int, user
 		return game, false, err
 	}
 
-	tx, err := s.pool.Begin(ctx)
+	updated, err := s.finishWithWinner(ctx, id, userID)
 	if err != nil {
 		return sqlcdb.Game{}, false, err
 	}
+	return updated, true, nil
+}
+
+// RecordScore keeps score as the player's score on the problem at
+// problemIndex if it beats their best so far. It reports whether it did and
+// the player's total over all problems, or errGameAlreadyFinished if the game
+// has ended or run out of time.
+func (s *GameService) RecordScore(ctx context.Context, gameID int, userID uuid.UUID, problemIndex int32, score int) (bool, int, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Holding the game row keeps the game from finishing until the score
+	// is in, so the final standings include it.
+	qtx := s.q.WithTx(tx)
+	game, err := qtx.GetGameForUpdate(ctx, int32(gameID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, 0, apierr.New(apierr.ErrGameNotFound, "game not found")
+	}
+	if err != nil {
+		return false, 0, err
+	}
+	if game.Status != gameStatusActive {
+		return false, 0, errGameAlreadyFinished
+	}
+	if deadline, ok := GameDeadline(game); ok && !time.Now().Before(deadline) {
+		return false, 0, errGameAlreadyFinished
+	}
+
+	rows, err := qtx.RecordGameScore(ctx, sqlcdb.RecordGameScoreParams{
+		GameID:       game.ID,
+		UserID:       userID,
+		ProblemIndex: problemIndex,
+		Score:        int32(score),
+	})
+	if err != nil {
+		return false, 0, fmt.Errorf("record game score: %w", err)
+	}
+	total, err := qtx.GetParticipantTotalScore(ctx, sqlcdb.GetParticipantTotalScoreParams{
+		GameID: game.ID,
+		UserID: userID,
+	})
+	if err != nil {
+		return false, 0, fmt.Errorf("get total score: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, 0, err
+	}
+	return rows > 0, int(total), nil
+}
+
+// HandleFullScore ends a scoring game won by a player who has scored every
+// point there is.
+func (s *GameService) HandleFullScore(ctx context.Context, id int, userID uuid.UUID) (sqlcdb.Game, error) {
+	return s.finishWithWinner(ctx, id, userID)
+}
+
+// GetGameScores returns each participant's best score per problem, indexed
+// by problem, for participants who have scored.
+func (s *GameService) GetGameScores(ctx context.Context, gameID int) (map[string][]int, error) {
+	rows, err := s.q.GetGameScores(ctx, int32(gameID))
+	if err != nil {
+		return nil, err
+	}
+	problems, err := s.q.CountGameProblems(ctx, int32(gameID))
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string][]int)
+	for _, r := range rows {
+		key := r.UserID.String()
+		if result[key] == nil {
+			result[key] = make([]int, problems)
+		}
+		if int64(r.ProblemIndex) < problems {
+			result[key][r.ProblemIndex] = int(r.Score)
+		}
+	}
+	return result, nil
+}
+
+// finishWithWinner ends an active game won by userID, or by the creator of
+// a solo game. It returns errGameAlreadyFinished if the game has ended.
+func (s *GameService) finishWithWinner(ctx context.Context, id int, userID uuid.UUID) (sqlcdb.Game, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return sqlcdb.Game{}, err
+	}
 	defer tx.Rollback(ctx)
 
 	qtx := s.q.WithTx(tx)
 	game, err := qtx.GetGameForUpdate(ctx, int32(id))
 	if errors.Is(err, pgx.ErrNoRows) {
-		return sqlcdb.Game{}, false, apierr.New(apierr.ErrGameNotFound, "game not found")
+		return sqlcdb.Game{}, apierr.New(apierr.ErrGameNotFound, "game not found")
 	}
 	if err != nil {
-		return sqlcdb.Game{}, false, err
+		return sqlcdb.Game{}, err
 	}
 
 	// Another player may have already finished.
 	if game.Status != gameStatusActive {
-		return game, false, errGameAlreadyFinished
+		return game, errGameAlreadyFinished
 	}
 
 	// For solo games, the creator is always the winner when all problems are solved.
@@ -422,14 +536,17 @@ func (s *GameService) HandleAcceptedSubmission(ctx context.Context, id int, user
 		WinnerID: uuid.NullUUID{UUID: winnerID, Valid: true},
 	})
 	if err != nil {
-		return sqlcdb.Game{}, false, err
+		return sqlcdb.Game{}, err
+	}
+	if err := updateRatings(ctx, qtx, updated); err != nil {
+		return sqlcdb.Game{}, err
 	}
 
 	if err := tx.Commit(ctx); err != nil {
-		return sqlcdb.Game{}, false, err
+		return sqlcdb.Game{}, err
 	}
 
-	return updated, true, nil
+	return updated, nil
 }
 
 func (s *GameService) GetParticipantProblemIndex(ctx context.Context, gameID int, userID uuid.UUID) (int32, error) {
@@ -508,6 +625,9 @@ func (s *GameService) completeGame(ctx context.Context, id int, winnerID uuid.UU
 	if err != nil {
 		return sqlcdb.Game{}, err
 	}
+	if err := updateRatings(ctx, qtx, game); err != nil {
+		return sqlcdb.Game{}, err
+	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return sqlcdb.Game{}, err
@@ -536,6 +656,88 @@ func (s *GameService) TimeoutGame(ctx context.Context, id int, userID uuid.UUID)
 	return s.q.TimeoutGame(ctx, game.ID)
 }
 
+// GameDeadline reports when a timed game runs out of time.
+func GameDeadline(game sqlcdb.Game) (time.Time, bool) {
+	if !game.TimeLimitMinutes.Valid || !game.StartedAt.Valid {
+		return time.Time{}, false
+	}
+	return game.StartedAt.Time.Add(time.Duration(game.TimeLimitMinutes.Int16) * time.Minute), true
+}
+
+// FinishExpiredGames finishes every active game whose time limit has passed.
+// In multiplayer games the participant who solved the most problems, or
+// scored the most points in a scoring game, wins, ties going to whoever got
+// there first; nobody wins if nothing was solved.
+// Solo games simply end without a winner, as with TimeoutGame.
+func (s *GameService) FinishExpiredGames(ctx context.Context) ([]sqlcdb.Game, error) {
+	ids, err := s.q.ListExpiredActiveGames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list expired games: %w", err)
+	}
+
+	finished := make([]sqlcdb.Game, 0, len(ids))
+	var errs []error
+	for _, id := range ids {
+		game, ok, err := s.finishExpiredGame(ctx, id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("finish expired game %d: %w", id, err))
+			continue
+		}
+		if ok {
+			finished = append(finished, game)
+		}
+	}
+	return finished, errors.Join(errs...)
+}
+
+func (s *GameService) finishExpiredGame(ctx context.Context, id int32) (sqlcdb.Game, bool, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return sqlcdb.Game{}, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.q.WithTx(tx)
+	game, err := qtx.GetGameForUpdate(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return sqlcdb.Game{}, false, nil
+	}
+	if err != nil {
+		return sqlcdb.Game{}, false, err
+	}
+	// The last problem may have been solved since the game was listed.
+	if game.Status != gameStatusActive {
+		return sqlcdb.Game{}, false, nil
+	}
+
+	var winner uuid.NullUUID
+	if !game.IsSolo {
+		getLeader := qtx.GetLeadingParticipant
+		if game.Mode == GameModeScoring {
+			getLeader = qtx.GetLeadingScorer
+		}
+		leader, err := getLeader(ctx, game.ID)
+		switch {
+		case err == nil:
+			winner = uuid.NullUUID{UUID: leader, Valid: true}
+		case !errors.Is(err, pgx.ErrNoRows):
+			return sqlcdb.Game{}, false, err
+		}
+	}
+
+	game, err = qtx.CompleteGame(ctx, sqlcdb.CompleteGameParams{ID: game.ID, WinnerID: winner})
+	if err != nil {
+		return sqlcdb.Game{}, false, err
+	}
+	if err := updateRatings(ctx, qtx, game); err != nil {
+		return sqlcdb.Game{}, false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return sqlcdb.Game{}, false, err
+	}
+	return game, true, nil
+}
+
 func (s *GameService) CancelGame(ctx context.Context, id int, userID uuid.UUID) (sqlcdb.Game, error) {
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
@@ -573,6 +775,8 @@ func (s *GameService) CancelGame(ctx context.Context, id int, userID uuid.UUID)
 		return sqlcdb.Game{}, err
 	}
 
+	s.notifier.GameCancelled(ctx, game)
+
 	return game, nil
 }
 
@@ -619,6 +823,8 @@ func (s *GameService) LeaveGame(ctx context.Context, gameID int, userID uuid.UUI
 		return sqlcdb.Game{}, err
 	}
 
+	s.notifier.ParticipantLeft(ctx, game.ID, userID)
+
 	return game, nil
 }
 
@@ -636,6 +842,19 @@ func (s *GameService) GetGameSolutions(ctx context.Context, gameID int) ([]sqlcd
 	return s.q.GetGameSolutions(ctx, pgtype.Int4{Int32: int32(gameID), Valid: true})
 }
 
+// GetGameSubmissions lists every attempt made in the game. While the game is
+// still running a user only sees their own attempts.
+func (s *GameService) GetGameSubmissions(ctx context.Context, game sqlcdb.Game, userID uuid.UUID) ([]sqlcdb.ListGameSubmissionsRow, error) {
+	var filter uuid.NullUUID
+	if game.Status != gameStatusFinished {
+		filter = uuid.NullUUID{UUID: userID, Valid: true}
+	}
+	return s.q.ListGameSubmissions(ctx, sqlcdb.ListGameSubmissionsParams{
+		GameID: pgtype.Int4{Int32: game.ID, Valid: true},
+		UserID: filter,
+	})
+}
+
 func (s *GameService) DeleteGame(ctx context.Context, id int, userID uuid.UUID) error {
 	game, err := s.q.GetGameByID(ctx, int32(id))
 	if errors.Is(err, pgx.ErrNoRows) {
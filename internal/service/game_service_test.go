@@ -0,0 +1,28 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	sqlcdb "bytebattle/internal/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGameDeadline(t *testing.T) {
+	started := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	deadline, ok := GameDeadline(sqlcdb.Game{
+		StartedAt:        pgtype.Timestamptz{Time: started, Valid: true},
+		TimeLimitMinutes: pgtype.Int2{Int16: 30, Valid: true},
+	})
+	assert.True(t, ok)
+	assert.Equal(t, started.Add(30*time.Minute), deadline)
+
+	_, ok = GameDeadline(sqlcdb.Game{StartedAt: pgtype.Timestamptz{Time: started, Valid: true}})
+	assert.False(t, ok, "untimed game has no deadline")
+
+	_, ok = GameDeadline(sqlcdb.Game{TimeLimitMinutes: pgtype.Int2{Int16: 30, Valid: true}})
+	assert.False(t, ok, "game that has not started has no deadline")
+}
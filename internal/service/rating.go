@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+
+	sqlcdb "bytebattle/internal/db/sqlc"
+
+	"github.com/google/uuid"
+)
+
+// ratingK is the Elo K-factor: the most a player's rating can move in one
+// game. In games with more than two players it is shared between all the
+// pairwise match-ups so that big lobbies do not swing ratings harder.
+const ratingK = 32
+
+// standing is a participant's final position in a game. Equal places are draws.
+type standing struct {
+	userID uuid.UUID
+	place  int
+	rating int32
+}
+
+// rankStandings orders participants by the final standings of a game: the
+// winner first, then everybody else by their total score in a scoring game
+// or by the number of problems solved in a race. Participants who did
+// equally well share a place.
+func rankStandings(rows []sqlcdb.GetGameStandingsForUpdateRow, winner uuid.NullUUID) []standing {
+	rows = slices.Clone(rows)
+	isWinner := func(r sqlcdb.GetGameStandingsForUpdateRow) bool {
+		return winner.Valid && r.UserID == winner.UUID
+	}
+	cmp := func(a, b sqlcdb.GetGameStandingsForUpdateRow) int {
+		if wa, wb := isWinner(a), isWinner(b); wa != wb {
+			if wa {
+				return -1
+			}
+			return 1
+		}
+		if a.TotalScore != b.TotalScore {
+			return int(b.TotalScore - a.TotalScore)
+		}
+		return int(b.CurrentProblemIndex) - int(a.CurrentProblemIndex)
+	}
+	slices.SortStableFunc(rows, cmp)
+
+	result := make([]standing, len(rows))
+	for i, r := range rows {
+		place := i + 1
+		if i > 0 && cmp(rows[i-1], r) == 0 {
+			place = result[i-1].place
+		}
+		result[i] = standing{userID: r.UserID, place: place, rating: r.Rating}
+	}
+	return result
+}
+
+// eloDeltas computes the rating change of every participant treating the game
+// as a round of pairwise Elo matches: each player is scored against every
+// other player by their relative places, and the sum is scaled by K/(n-1).
+func eloDeltas(standings []standing) []int32 {
+	n := len(standings)
+	deltas := make([]int32, n)
+	if n < 2 {
+		return deltas
+	}
+	k := float64(ratingK) / float64(n-1)
+	for i, a := range standings {
+		var sum float64
+		for j, b := range standings {
+			if i == j {
+				continue
+			}
+			expected := 1 / (1 + math.Pow(10, float64(b.rating-a.rating)/400))
+			actual := 0.5
+			switch {
+			case a.place < b.place:
+				actual = 1
+			case a.place > b.place:
+				actual = 0
+			}
+			sum += actual - expected
+		}
+		deltas[i] = int32(math.Round(k * sum))
+	}
+	return deltas
+}
+
+// updateRatings recalculates the ratings of everyone who played a finished
+// multiplayer game and records the change in the rating history. It must run
+// in the transaction that finishes the game so a game is rated exactly once.
+func updateRatings(ctx context.Context, qtx *sqlcdb.Queries, game sqlcdb.Game) error {
+	if game.IsSolo {
+		return nil
+	}
+	rows, err := qtx.GetGameStandingsForUpdate(ctx, game.ID)
+	if err != nil {
+		return fmt.Errorf("get standings: %w", err)
+	}
+	if len(rows) < 2 {
+		return nil
+	}
+
+	standings := rankStandings(rows, game.WinnerID)
+	for i, delta := range eloDeltas(standings) {
+		st := standings[i]
+		newRating := st.rating + delta
+		if err := qtx.UpdateUserRating(ctx, sqlcdb.UpdateUserRatingParams{ID: st.userID, Rating: newRating}); err != nil {
+			return fmt.Errorf("update rating of %s: %w", st.userID, err)
+		}
+		if err := qtx.InsertRatingHistory(ctx, sqlcdb.InsertRatingHistoryParams{
+			UserID:    st.userID,
+			GameID:    game.ID,
+			Place:     int32(st.place),
+			OldRating: st.rating,
+			NewRating: newRating,
+		}); err != nil {
+			return fmt.Errorf("record rating of %s: %w", st.userID, err)
+		}
+	}
+	return nil
+}
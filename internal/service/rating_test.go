@@ -0,0 +1,90 @@
+package service
+
+import (
+	"testing"
+
+	sqlcdb "bytebattle/internal/db/sqlc"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankStandings(t *testing.T) {
+	a, b, c, d := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	rows := []sqlcdb.GetGameStandingsForUpdateRow{
+		{UserID: a, CurrentProblemIndex: 1, Rating: 1200},
+		{UserID: b, CurrentProblemIndex: 3, Rating: 1300},
+		{UserID: c, CurrentProblemIndex: 3, Rating: 1100},
+		{UserID: d, CurrentProblemIndex: 1, Rating: 1250},
+	}
+
+	got := rankStandings(rows, uuid.NullUUID{UUID: c, Valid: true})
+
+	assert.Equal(t, []standing{
+		{userID: c, place: 1, rating: 1100},
+		{userID: b, place: 2, rating: 1300},
+		{userID: a, place: 3, rating: 1200},
+		{userID: d, place: 3, rating: 1250},
+	}, got)
+}
+
+func TestRankStandings_ScoringGameByTotalScore(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	// Scoring games leave current_problem_index alone.
+	rows := []sqlcdb.GetGameStandingsForUpdateRow{
+		{UserID: a, Rating: 1200, TotalScore: 0},
+		{UserID: b, Rating: 1200, TotalScore: 90},
+		{UserID: c, Rating: 1200, TotalScore: 150},
+	}
+
+	got := rankStandings(rows, uuid.NullUUID{UUID: c, Valid: true})
+
+	assert.Equal(t, []standing{
+		{userID: c, place: 1, rating: 1200},
+		{userID: b, place: 2, rating: 1200},
+		{userID: a, place: 3, rating: 1200},
+	}, got)
+	deltas := eloDeltas(got)
+	assert.Greater(t, deltas[1], deltas[2], "90 points rate better than none")
+}
+
+func TestRankStandings_NoWinnerIsADraw(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	got := rankStandings([]sqlcdb.GetGameStandingsForUpdateRow{
+		{UserID: a, Rating: 1200},
+		{UserID: b, Rating: 1200},
+	}, uuid.NullUUID{})
+
+	assert.Equal(t, 1, got[0].place)
+	assert.Equal(t, 1, got[1].place)
+}
+
+func TestEloDeltas(t *testing.T) {
+	t.Run("equal ratings two players", func(t *testing.T) {
+		got := eloDeltas([]standing{{place: 1, rating: 1200}, {place: 2, rating: 1200}})
+		assert.Equal(t, []int32{16, -16}, got)
+	})
+
+	t.Run("upset gains more", func(t *testing.T) {
+		got := eloDeltas([]standing{{place: 1, rating: 1000}, {place: 2, rating: 1400}})
+		assert.Equal(t, []int32{29, -29}, got)
+	})
+
+	t.Run("draw moves towards each other", func(t *testing.T) {
+		got := eloDeltas([]standing{{place: 1, rating: 1000}, {place: 1, rating: 1400}})
+		assert.Equal(t, []int32{13, -13}, got)
+	})
+
+	t.Run("multiplayer shares K", func(t *testing.T) {
+		got := eloDeltas([]standing{
+			{place: 1, rating: 1200},
+			{place: 2, rating: 1200},
+			{place: 3, rating: 1200},
+		})
+		assert.Equal(t, []int32{16, 0, -16}, got)
+	})
+
+	t.Run("single player", func(t *testing.T) {
+		assert.Equal(t, []int32{0}, eloDeltas([]standing{{place: 1, rating: 1200}}))
+	})
+}
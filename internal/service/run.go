@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+
+	"bytebattle/internal/apierr"
+	"bytebattle/internal/executor"
+	"bytebattle/internal/problems"
+
+	"github.com/google/uuid"
+)
+
+// RunResult is the outcome of running code without submitting it. A run on
+// custom input has no expected output, so Verdict is only set when the
+// program failed; a run on the sample tests is judged like a submission,
+// with Input and Expected describing the failed sample.
+type RunResult struct {
+	Verdict    problems.Verdict
+	Tests      []TestResult
+	FailedTest *int
+	Stdout     string
+	Stderr     string
+	Input      string
+	Expected   string
+	ExitCode   int
+	TimeMs     int64
+	MemoryKb   int64
+}
+
+// Run executes code with the limits of the player's current problem, or the
+// one at problemIndex in a scoring game, on input when it is not nil and on
+// the problem's sample tests otherwise.
+// Nothing is recorded and the game is not affected. Runs have their own slot
+// and count against the user's execution rate limit.
+func (s *SubmissionService) Run(ctx context.Context, gameID int, userID uuid.UUID, problemIndex *int32, code string, language executor.Language, input *string) (RunResult, error) {
+	if !s.execSvc.TryAcquireSlot(userID, SlotRun) {
+		return RunResult{}, apierr.New(apierr.ErrExecutionInProgress, "execution already in progress")
+	}
+	defer s.execSvc.ReleaseSlot(userID, SlotRun)
+	if err := s.execSvc.CheckRateLimit(userID); err != nil {
+		return RunResult{}, err
+	}
+
+	ap, err := s.getProblemForSubmission(ctx, gameID, userID, problemIndex)
+	if err != nil {
+		return RunResult{}, err
+	}
+
+	if input == nil {
+		outcome, err := s.executeAgainstProblem(ctx, ap.problem, ap.problem.SampleTests(), ap.limits, code, language, false)
+		if err != nil {
+			return RunResult{}, err
+		}
+		return RunResult{
+			Verdict:    outcome.verdict,
+			Tests:      outcome.tests,
+			FailedTest: outcome.failedTest,
+			Stdout:     outcome.stdout,
+			Stderr:     outcome.stderr,
+			Input:      outcome.input,
+			Expected:   outcome.expected,
+			TimeMs:     outcome.timeMs,
+			MemoryKb:   outcome.memoryKb,
+		}, nil
+	}
+
+	prog, err := program(ap.problem, code, language)
+	if err != nil {
+		return RunResult{}, err
+	}
+	res, err := s.execSvc.Execute(ctx, executor.ExecutionRequest{
+		Code:        prog,
+		Language:    language,
+		Stdin:       *input,
+		TimeLimit:   ap.limits.time,
+		MemoryLimit: ap.limits.memory,
+	})
+	if err != nil {
+		return RunResult{}, err
+	}
+	timeMs := res.CPUTime.Milliseconds()
+	if res.CPUTime == 0 {
+		timeMs = res.TimeUsed.Milliseconds()
+	}
+	return RunResult{
+		Verdict:  problems.RunFailureVerdict(res),
+		Stdout:   res.Stdout,
+		Stderr:   res.Stderr,
+		ExitCode: res.ExitCode,
+		TimeMs:   timeMs,
+		MemoryKb: res.MemoryUsed / 1024,
+	}, nil
+}
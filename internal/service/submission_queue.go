@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"bytebattle/internal/apierr"
+	sqlcdb "bytebattle/internal/db/sqlc"
+	"bytebattle/internal/executor"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// MaxSubmissionAttempts is how many times a job is run before an
+	// infrastructure failure is reported to the player.
+	MaxSubmissionAttempts = 3
+	submissionRetryDelay  = 5 * time.Second
+)
+
+// Enqueue stores a submission as a pending job and returns its ID. The game
+// state is checked up front so that the player gets an immediate error for a
+// submission that cannot be judged; the job itself is run by ClaimJob/RunJob.
+// problemIndex selects the problem in a scoring game and is ignored in a
+// race, where players submit to their current problem.
+func (s *SubmissionService) Enqueue(ctx context.Context, gameID int, userID uuid.UUID, problemIndex *int32, code string, language executor.Language, instanceID string) (int64, error) {
+	if err := s.execSvc.CheckRateLimit(userID); err != nil {
+		return 0, err
+	}
+
+	ap, err := s.getProblemForSubmission(ctx, gameID, userID, problemIndex)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := program(ap.problem, code, language); err != nil {
+		return 0, err
+	}
+
+	id, err := s.q.InsertSubmissionJob(ctx, sqlcdb.InsertSubmissionJobParams{
+		GameID:       int32(gameID),
+		UserID:       userID,
+		ProblemIndex: ap.index,
+		Code:         code,
+		Language:     string(language),
+		InstanceID:   instanceID,
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return 0, apierr.New(apierr.ErrExecutionInProgress, "execution already in progress")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("insert submission job: %w", err)
+	}
+	return id, nil
+}
+
+// ClaimJob takes the next pending job for instanceID, see the
+// ClaimSubmissionJob query. ok is false when there is nothing to do.
+func (s *SubmissionService) ClaimJob(ctx context.Context, instanceID string, orphanedAfter time.Duration) (job sqlcdb.SubmissionJob, ok bool, err error) {
+	job, err = s.q.ClaimSubmissionJob(ctx, sqlcdb.ClaimSubmissionJobParams{
+		InstanceID:     instanceID,
+		OrphanedBefore: pgtype.Timestamptz{Time: time.Now().Add(-orphanedAfter), Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return sqlcdb.SubmissionJob{}, false, nil
+	}
+	if err != nil {
+		return sqlcdb.SubmissionJob{}, false, err
+	}
+	return job, true, nil
+}
+
+// RunJob judges a claimed job. A job that has already used up its attempts,
+// e.g. because it kept crashing the instance running it, fails without being
+// run again.
+func (s *SubmissionService) RunJob(ctx context.Context, job sqlcdb.SubmissionJob) (SubmissionResult, error) {
+	if job.Attempts > MaxSubmissionAttempts {
+		return SubmissionResult{}, fmt.Errorf("submission job %d: gave up after %d attempts", job.ID, MaxSubmissionAttempts)
+	}
+	return s.judge(ctx, job.ID, int(job.GameID), job.UserID, job.ProblemIndex, job.Code, executor.Language(job.Language))
+}
+
+// FinishJob marks a job done. result is the message to deliver to the
+// player, nil when there is nothing to report.
+func (s *SubmissionService) FinishJob(ctx context.Context, job sqlcdb.SubmissionJob, result []byte, jobErr error) error {
+	var lastError pgtype.Text
+	if jobErr != nil {
+		lastError = pgtype.Text{String: jobErr.Error(), Valid: true}
+	}
+	return s.q.FinishSubmissionJob(ctx, sqlcdb.FinishSubmissionJobParams{ID: job.ID, Result: result, LastError: lastError})
+}
+
+// RetryJob puts a job that failed with jobErr back in the queue and reports
+// whether it did. Application errors, such as the game having ended, are
+// final, and so is the last attempt.
+func (s *SubmissionService) RetryJob(ctx context.Context, job sqlcdb.SubmissionJob, jobErr error) (bool, error) {
+	var appErr *apierr.AppError
+	if errors.As(jobErr, &appErr) || job.Attempts >= MaxSubmissionAttempts {
+		return false, nil
+	}
+	err := s.q.RetrySubmissionJob(ctx, sqlcdb.RetrySubmissionJobParams{
+		ID:        job.ID,
+		RunAfter:  pgtype.Timestamptz{Time: time.Now().Add(time.Duration(job.Attempts) * submissionRetryDelay), Valid: true},
+		LastError: pgtype.Text{String: jobErr.Error(), Valid: true},
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RequeueStaleJobs returns jobs that have been running for longer than
+// staleAfter to the queue; their worker is assumed to be dead.
+func (s *SubmissionService) RequeueStaleJobs(ctx context.Context, staleAfter time.Duration) (int64, error) {
+	return s.q.RequeueStaleSubmissionJobs(ctx, pgtype.Timestamptz{Time: time.Now().Add(-staleAfter), Valid: true})
+}
+
+// UndeliveredResults returns the results of finished jobs that never reached
+// the player, oldest first.
+func (s *SubmissionService) UndeliveredResults(ctx context.Context, gameID int, userID uuid.UUID) ([]sqlcdb.ListUndeliveredSubmissionJobsRow, error) {
+	return s.q.ListUndeliveredSubmissionJobs(ctx, sqlcdb.ListUndeliveredSubmissionJobsParams{
+		GameID: int32(gameID),
+		UserID: userID,
+	})
+}
+
+// ClaimDelivery marks a job's result delivered. Only the first caller gets
+// true, so a result is sent at most once even when the worker finishing the
+// job and a reconnecting player race for it.
+func (s *SubmissionService) ClaimDelivery(ctx context.Context, jobID int64) (bool, error) {
+	rows, err := s.q.MarkSubmissionJobDelivered(ctx, jobID)
+	return rows == 1, err
+}
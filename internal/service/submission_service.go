@@ -2,9 +2,11 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"bytebattle/internal/apierr"
 	sqlcdb "bytebattle/internal/db/sqlc"
@@ -18,12 +20,36 @@ import (
 type SubmissionResult struct {
 	Accepted        bool
 	AlreadyAdvanced bool
+	Verdict         problems.Verdict
+	Tests           []TestResult
 	FailedTest      *int
-	Stdout          string
-	Stderr          string
-	WinnerID        uuid.UUID
-	ProblemID       string
-	ProblemIdx      int
+	// Stdout and Stderr are the output of the failed test when it is a
+	// sample or the program did not compile. Input and Expected are only
+	// set for a sample.
+	Stdout     string
+	Stderr     string
+	Input      string
+	Expected   string
+	WinnerID   uuid.UUID
+	ProblemID  string
+	ProblemIdx int
+	// Score is what the submission earned out of MaxScore, the problem's
+	// worth; Groups are its test groups when it has any. ScoreImproved is
+	// set in a scoring game when Score is the player's new best.
+	Score         int
+	MaxScore      int
+	Groups        []problems.GroupResult
+	ScoreImproved bool
+}
+
+// TestResult is the verdict of a single test case. Tests after the first
+// failing one are not run and therefore not reported, unless the problem has
+// test groups to score. TimeMs is CPU time when the executor measures it,
+// wall-clock time otherwise.
+type TestResult struct {
+	Verdict  problems.Verdict `json:"verdict"`
+	TimeMs   int64            `json:"time_ms"`
+	MemoryKb int64            `json:"memory_kb"`
 }
 
 type SubmissionService struct {
@@ -35,45 +61,105 @@ type SubmissionService struct {
 
 type activeProblem struct {
 	problem   *problems.Problem
+	index     int32
 	versionID int64
+	limits    runLimits
+	scoring   bool // the game is a scoring game
+}
+
+// runLimits bounds each test run of a submission; they come from the
+// problem version the game was created with.
+type runLimits struct {
+	time   time.Duration
+	memory int64
 }
 
 type executionOutcome struct {
-	accepted   bool
+	verdict    problems.Verdict
+	tests      []TestResult
 	failedTest *int
-	stdout     string
-	stderr     string
+	// The output of hidden tests is withheld: a failure on one of them is
+	// reported by verdict only. input and expected belong to a failed sample.
+	stdout   string
+	stderr   string
+	input    string
+	expected string
+	// timeMs and memoryKb are the maxima over all tests that ran.
+	timeMs   int64
+	memoryKb int64
 }
 
 func NewSubmissionService(execSvc *ExecutionService, gameSvc *GameService, store *problems.Store, q sqlcdb.Querier) *SubmissionService {
 	return &SubmissionService{execSvc: execSvc, gameSvc: gameSvc, store: store, q: q}
 }
 
-func (s *SubmissionService) Submit(ctx context.Context, gameID int, userID uuid.UUID, code string, language executor.Language) (SubmissionResult, error) {
-	if !s.execSvc.TryAcquireSlot(userID, "submit") {
-		return SubmissionResult{}, apierr.New(apierr.ErrExecutionInProgress, "execution already in progress")
+// judge runs a queued submission against the problem at problemIndex and
+// applies the result to the game. In a race that is the player's current
+// problem: a player who has moved past it since the submission was queued is
+// reported as AlreadyAdvanced without running anything, which makes retrying
+// a job that was interrupted after advancing the player safe.
+func (s *SubmissionService) judge(ctx context.Context, jobID int64, gameID int, userID uuid.UUID, problemIndex int32, code string, language executor.Language) (SubmissionResult, error) {
+	ap, err := s.getProblemForSubmission(ctx, gameID, userID, &problemIndex)
+	if err != nil {
+		return SubmissionResult{}, err
+	}
+	if ap.index != problemIndex {
+		return SubmissionResult{AlreadyAdvanced: true}, nil
 	}
-	defer s.execSvc.ReleaseSlot(userID, "submit")
 
-	if err := s.execSvc.CheckRateLimit(userID); err != nil {
+	allTests := len(ap.problem.Manifest.Groups) > 0
+	outcome, err := s.executeAgainstProblem(ctx, ap.problem, ap.problem.TestCases, ap.limits, code, language, allTests)
+	if err != nil {
 		return SubmissionResult{}, err
 	}
+	verdicts := make([]problems.Verdict, len(outcome.tests))
+	for i, tr := range outcome.tests {
+		verdicts[i] = tr.Verdict
+	}
+	score, groups := ap.problem.Score(verdicts)
+	testResults, _ := json.Marshal(outcome.tests)
+	s.recordSubmission(ctx, jobID, gameID, userID, ap, code, language, outcome, score, testResults)
+
+	if outcome.verdict == problems.VerdictAccepted {
+		s.saveSolution(ctx, gameID, userID, ap, code, language, outcome, testResults)
+	}
 
-	ap, err := s.getCurrentProblemForSubmission(ctx, gameID, userID)
+	var res SubmissionResult
+	switch {
+	case ap.scoring:
+		res, err = s.completeScoredSubmission(ctx, gameID, userID, ap.index, score)
+	case outcome.verdict == problems.VerdictAccepted:
+		res, err = s.completeAcceptedSubmission(ctx, gameID, userID)
+	}
 	if err != nil {
 		return SubmissionResult{}, err
 	}
-
-	outcome := s.executeAgainstProblem(ctx, ap.problem, code, language)
-	if !outcome.accepted {
-		return SubmissionResult{
-			Accepted:   false,
-			FailedTest: outcome.failedTest,
-			Stdout:     outcome.stdout,
-			Stderr:     outcome.stderr,
-		}, nil
+	res.Accepted = outcome.verdict == problems.VerdictAccepted
+	if !res.Accepted {
+		res.Stdout, res.Stderr = outcome.stdout, outcome.stderr
+		res.Input, res.Expected = outcome.input, outcome.expected
 	}
+	res.Verdict = outcome.verdict
+	res.Tests = outcome.tests
+	res.FailedTest = outcome.failedTest
+	res.Score = score
+	res.MaxScore = ap.problem.MaxScore()
+	res.Groups = groups
+	return res, nil
+}
 
+// saveSolution stores an accepted submission as the player's solution of the
+// problem. A failure is logged: the verdict stands without it.
+func (s *SubmissionService) saveSolution(
+	ctx context.Context,
+	gameID int,
+	userID uuid.UUID,
+	ap *activeProblem,
+	code string,
+	language executor.Language,
+	outcome executionOutcome,
+	testResults []byte,
+) {
 	if err := s.q.InsertSolution(ctx, sqlcdb.InsertSolutionParams{
 		UserID:           userID,
 		ProblemID:        ap.problem.Slug,
@@ -81,14 +167,56 @@ func (s *SubmissionService) Submit(ctx context.Context, gameID int, userID uuid.
 		GameID:           pgtype.Int4{Int32: int32(gameID), Valid: true},
 		Code:             code,
 		Language:         string(language),
+		Verdict:          string(outcome.verdict),
+		TestResults:      testResults,
+		ExecutionTime:    pgtype.Int4{Int32: int32(outcome.timeMs), Valid: true},
+		MemoryUsed:       pgtype.Int4{Int32: int32(outcome.memoryKb), Valid: outcome.memoryKb > 0},
 	}); err != nil {
 		log.Printf("warn: failed to save solution user=%s problem=%s game=%d: %v", userID, ap.problem.Slug, gameID, err)
 	}
+}
 
-	return s.completeAcceptedSubmission(ctx, gameID, userID)
+// recordSubmission stores the attempt in the submissions history, once per
+// job however often the job is retried. A failure is logged but does not fail
+// the submission: the verdict is already known.
+func (s *SubmissionService) recordSubmission(
+	ctx context.Context,
+	jobID int64,
+	gameID int,
+	userID uuid.UUID,
+	ap *activeProblem,
+	code string,
+	language executor.Language,
+	outcome executionOutcome,
+	score int,
+	testResults []byte,
+) {
+	var failedTest pgtype.Int4
+	if outcome.failedTest != nil {
+		failedTest = pgtype.Int4{Int32: int32(*outcome.failedTest), Valid: true}
+	}
+	if err := s.q.InsertSubmission(ctx, sqlcdb.InsertSubmissionParams{
+		UserID:           userID,
+		GameID:           pgtype.Int4{Int32: int32(gameID), Valid: true},
+		ProblemID:        ap.problem.Slug,
+		ProblemVersionID: ap.versionID,
+		Code:             code,
+		Language:         string(language),
+		Verdict:          string(outcome.verdict),
+		FailedTest:       failedTest,
+		ExecutionTime:    pgtype.Int4{Int32: int32(outcome.timeMs), Valid: len(outcome.tests) > 0},
+		MemoryUsed:       pgtype.Int4{Int32: int32(outcome.memoryKb), Valid: outcome.memoryKb > 0},
+		TestResults:      testResults,
+		Score:            pgtype.Int4{Int32: int32(score), Valid: true},
+		JobID:            pgtype.Int8{Int64: jobID, Valid: true},
+	}); err != nil {
+		log.Printf("warn: failed to record submission user=%s problem=%s game=%d: %v", userID, ap.problem.Slug, gameID, err)
+	}
 }
 
-func (s *SubmissionService) getCurrentProblemForSubmission(ctx context.Context, gameID int, userID uuid.UUID) (*activeProblem, error) {
+// getProblemForSubmission returns the problem a player submits to: their
+// current problem in a race, the one at problemIndex in a scoring game.
+func (s *SubmissionService) getProblemForSubmission(ctx context.Context, gameID int, userID uuid.UUID, problemIndex *int32) (*activeProblem, error) {
 	game, err := s.gameSvc.GetGame(ctx, gameID)
 	if err != nil {
 		return nil, fmt.Errorf("get game: %w", err)
@@ -96,11 +224,22 @@ func (s *SubmissionService) getCurrentProblemForSubmission(ctx context.Context,
 	if game.Status != "active" {
 		return nil, apierr.New(apierr.ErrGameNotInProgress, "game is not in progress")
 	}
+	// The game may not have been finished by the expiry loop yet.
+	if deadline, ok := GameDeadline(game); ok && !time.Now().Before(deadline) {
+		return nil, apierr.New(apierr.ErrGameNotInProgress, "game time is over")
+	}
 
 	playerIdx, err := s.gameSvc.GetParticipantProblemIndex(ctx, gameID, userID)
 	if err != nil {
 		return nil, fmt.Errorf("get participant problem index: %w", err)
 	}
+	scoring := game.Mode == GameModeScoring
+	if scoring {
+		if problemIndex == nil {
+			return nil, apierr.New(apierr.ErrValidation, "problem_index is required in a scoring game")
+		}
+		playerIdx = *problemIndex
+	}
 
 	gameProblem, err := s.gameSvc.GetGameProblemByIndex(ctx, int32(gameID), playerIdx)
 	if err != nil {
@@ -113,36 +252,297 @@ func (s *SubmissionService) getCurrentProblemForSubmission(ctx context.Context,
 	}
 	return &activeProblem{
 		problem:   problem,
+		index:     playerIdx,
 		versionID: gameProblem.ProblemVersionID,
+		limits: runLimits{
+			time:   time.Duration(gameProblem.LimitsTimeMs) * time.Millisecond,
+			memory: int64(gameProblem.LimitsMemoryKb) * 1024,
+		},
+		scoring: scoring,
 	}, nil
 }
 
+// program returns the program to run for code, which in function problems
+// is wrapped in the harness calling the function.
+func program(problem *problems.Problem, code string, language executor.Language) (string, error) {
+	prog, err := problem.Manifest.Program(code, string(language))
+	if err != nil {
+		return "", apierr.New(apierr.ErrValidation, err.Error())
+	}
+	return prog, nil
+}
+
+// executeAgainstProblem compiles code once and runs it against every test
+// case, stopping at the first one that is not accepted unless allTests is
+// set, as it is to score test groups. The outcome reports the first failure.
+// Executor, checker and interactor failures are returned as errors rather
+// than verdicts: they are not the contestant's fault.
 func (s *SubmissionService) executeAgainstProblem(
 	ctx context.Context,
 	problem *problems.Problem,
+	tests []problems.TestCase,
+	limits runLimits,
 	code string,
 	language executor.Language,
-) executionOutcome {
-	outcome := executionOutcome{accepted: true}
-
-	for i, tc := range problem.TestCases {
-		result, execErr := s.execSvc.Execute(ctx, executor.ExecutionRequest{
-			Code:     code,
-			Language: language,
-			Stdin:    tc.Input,
-		})
-		outcome.stdout = result.Stdout
-		outcome.stderr = result.Stderr
+	allTests bool,
+) (executionOutcome, error) {
+	cmp := problem.Manifest.OutputComparator()
+	code, err := program(problem, code, language)
+	if err != nil {
+		return executionOutcome{}, err
+	}
+	var results []executor.ExecutionResult
+	// The interactor or the checker, if any, has decided every verdict.
+	var verdicts []problems.Verdict
+	if problem.Interactor != nil {
+		results, verdicts, err = s.executeInteractive(ctx, problem, tests, limits, code, language, allTests)
+	} else {
+		results, err = s.executeBatch(ctx, problem, tests, limits, code, language, allTests)
+		if err == nil && problem.Checker != nil {
+			verdicts, err = s.checkOutputs(ctx, problem.Checker, tests, results, allTests)
+		}
+	}
+	if err != nil {
+		return executionOutcome{}, fmt.Errorf("execute problem %s: %w", problem.Slug, err)
+	}
 
-		if execErr != nil || !problems.Match(result.Stdout, tc.Expected) {
-			outcome.accepted = false
+	outcome := executionOutcome{verdict: problems.VerdictAccepted}
+	for i, result := range results {
+		tc := tests[i]
+		if outcome.failedTest == nil {
+			outcome.stdout, outcome.stderr = "", ""
+			if tc.Sample || result.CompileFailed {
+				outcome.stdout, outcome.stderr = result.Stdout, result.Stderr
+			}
+		}
+
+		verdict := problems.RunFailureVerdict(result)
+		if verdicts != nil {
+			verdict = verdicts[i]
+		} else if verdict == "" {
+			verdict = problems.VerdictWrongAnswer
+			if cmp.Match(result.Stdout, tc.Expected) {
+				verdict = problems.VerdictAccepted
+			}
+		}
+
+		timeMs := result.CPUTime.Milliseconds()
+		if result.CPUTime == 0 {
+			timeMs = result.TimeUsed.Milliseconds()
+		}
+		outcome.tests = append(outcome.tests, TestResult{
+			Verdict:  verdict,
+			TimeMs:   timeMs,
+			MemoryKb: result.MemoryUsed / 1024,
+		})
+		outcome.timeMs = max(outcome.timeMs, timeMs)
+		outcome.memoryKb = max(outcome.memoryKb, result.MemoryUsed/1024)
+		if verdict != problems.VerdictAccepted && outcome.failedTest == nil {
+			outcome.verdict = verdict
 			idx := i
 			outcome.failedTest = &idx
-			break
+			if tc.Sample {
+				outcome.input, outcome.expected = tc.Input, tc.Expected
+			}
 		}
+		if verdict != problems.VerdictAccepted && (!allTests || result.CompileFailed) {
+			return outcome, nil
+		}
+	}
+	if len(results) != len(tests) {
+		return executionOutcome{}, fmt.Errorf("execute problem %s: got %d results for %d tests", problem.Slug, len(results), len(tests))
+	}
+	return outcome, nil
+}
+
+// executeBatch runs code on the inputs of tests, stopping as
+// executeAgainstProblem does where it can tell without a checker.
+func (s *SubmissionService) executeBatch(
+	ctx context.Context,
+	problem *problems.Problem,
+	tests []problems.TestCase,
+	limits runLimits,
+	code string,
+	language executor.Language,
+	allTests bool,
+) ([]executor.ExecutionResult, error) {
+	inputs := make([]string, len(tests))
+	for i, tc := range tests {
+		inputs[i] = tc.Input
+	}
+
+	cmp := problem.Manifest.OutputComparator()
+	var results []executor.ExecutionResult
+	err := s.execSvc.ExecuteBatch(ctx, executor.BatchRequest{
+		Code:        code,
+		Language:    language,
+		Inputs:      inputs,
+		TimeLimit:   limits.time,
+		MemoryLimit: limits.memory,
+		OutputLimit: problems.OutputLimit(tests),
+	}, func(i int, res executor.ExecutionResult) bool {
+		results = append(results, res)
+		if allTests {
+			return !res.CompileFailed
+		}
+		if problems.RunFailureVerdict(res) != "" {
+			return false
+		}
+		// Custom checkers need the executor themselves and run after the
+		// batch; built-in comparators can stop at the first wrong answer.
+		return problem.Checker != nil || cmp.Match(res.Stdout, tests[i].Expected)
+	})
+	return results, err
+}
+
+// checkOutputs judges the outputs of the runs in results that did not fail
+// with the problem's checker, compiled once for all of them. It stops at the
+// first wrong answer unless allTests is set; the verdicts of the tests after
+// it are left empty.
+func (s *SubmissionService) checkOutputs(
+	ctx context.Context,
+	checker *problems.Checker,
+	tests []problems.TestCase,
+	results []executor.ExecutionResult,
+	allTests bool,
+) ([]problems.Verdict, error) {
+	verdicts := make([]problems.Verdict, len(results))
+	var checked []int
+	var checkedTests []problems.TestCase
+	var outputs []string
+	for i, res := range results {
+		verdicts[i] = problems.RunFailureVerdict(res)
+		if verdicts[i] == "" {
+			checked = append(checked, i)
+			checkedTests = append(checkedTests, tests[i])
+			outputs = append(outputs, res.Stdout)
+		}
+	}
+	if len(checked) == 0 {
+		return verdicts, nil
+	}
+
+	var checkErr error
+	done, stopped := 0, false
+	err := s.execSvc.ExecuteBatch(ctx, checker.BatchRequest(checkedTests, outputs), func(j int, res executor.ExecutionResult) bool {
+		i := checked[j]
+		check, err := problems.CheckerResult(res)
+		if err != nil {
+			checkErr = fmt.Errorf("check test %s: %w", tests[i].Name, err)
+			return false
+		}
+		done++
+		verdicts[i] = problems.VerdictWrongAnswer
+		if check.Accepted {
+			verdicts[i] = problems.VerdictAccepted
+		}
+		stopped = !check.Accepted && !allTests
+		return !stopped
+	})
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("run checker: %w", err)
+	case checkErr != nil:
+		return nil, checkErr
+	case done < len(checked) && !stopped:
+		return nil, fmt.Errorf("run checker: got %d results for %d outputs", done, len(checked))
+	}
+	return verdicts, nil
+}
+
+// executeInteractive runs code against the problem's interactor on tests and
+// returns the program's results along with the verdicts of the interactor.
+func (s *SubmissionService) executeInteractive(
+	ctx context.Context,
+	problem *problems.Problem,
+	tests []problems.TestCase,
+	limits runLimits,
+	code string,
+	language executor.Language,
+	allTests bool,
+) ([]executor.ExecutionResult, []problems.Verdict, error) {
+	var results []executor.ExecutionResult
+	var verdicts []problems.Verdict
+	var judgeErr error
+	req := problem.Interactor.Request(code, language, limits.time, limits.memory, tests)
+	err := s.execSvc.ExecuteInteractive(ctx, req, func(i int, res executor.InteractiveResult) bool {
+		verdict, _, err := problems.InteractionVerdict(res)
+		if err != nil {
+			judgeErr = fmt.Errorf("test %s: %w", tests[i].Name, err)
+			return false
+		}
+		results = append(results, res.Program)
+		verdicts = append(verdicts, verdict)
+		if allTests {
+			return verdict != problems.VerdictCompileError
+		}
+		return verdict == problems.VerdictAccepted
+	})
+	if err == nil {
+		err = judgeErr
+	}
+	return results, verdicts, err
+}
+
+// completeScoredSubmission records the score of a submission in a scoring
+// game, unless the game is over. A player who has scored every point of every
+// problem wins.
+func (s *SubmissionService) completeScoredSubmission(ctx context.Context, gameID int, userID uuid.UUID, problemIndex int32, score int) (SubmissionResult, error) {
+	res := SubmissionResult{ProblemIdx: int(problemIndex)}
+	if score == 0 {
+		return res, nil
+	}
+	improved, total, err := s.gameSvc.RecordScore(ctx, gameID, userID, problemIndex, score)
+	if errors.Is(err, errGameAlreadyFinished) {
+		// The verdict stands, but the game is over and the score is not
+		// counted.
+		return res, nil
+	}
+	if err != nil {
+		return SubmissionResult{}, err
+	}
+	res.ScoreImproved = improved
+	if !improved {
+		return res, nil
 	}
 
-	return outcome
+	maxTotal, err := s.gameMaxScore(ctx, gameID)
+	if err != nil {
+		return SubmissionResult{}, err
+	}
+	if total < maxTotal {
+		return res, nil
+	}
+	game, err := s.gameSvc.HandleFullScore(ctx, gameID, userID)
+	if errors.Is(err, errGameAlreadyFinished) {
+		return res, nil
+	}
+	if err != nil {
+		return SubmissionResult{}, fmt.Errorf("complete game: %w", err)
+	}
+	res.WinnerID = game.WinnerID.UUID
+	return res, nil
+}
+
+// gameMaxScore is the sum of what the game's problems are worth.
+func (s *SubmissionService) gameMaxScore(ctx context.Context, gameID int) (int, error) {
+	count, err := s.q.CountGameProblems(ctx, int32(gameID))
+	if err != nil {
+		return 0, fmt.Errorf("count game problems: %w", err)
+	}
+	total := 0
+	for i := range int32(count) {
+		gameProblem, err := s.gameSvc.GetGameProblemByIndex(ctx, int32(gameID), i)
+		if err != nil {
+			return 0, fmt.Errorf("get game problem by index: %w", err)
+		}
+		problem, err := s.store.GetByPath(gameProblem.ArtifactPath)
+		if err != nil {
+			return 0, fmt.Errorf("get problem: %w", err)
+		}
+		total += problem.MaxScore()
+	}
+	return total, nil
 }
 
 func (s *SubmissionService) completeAcceptedSubmission(
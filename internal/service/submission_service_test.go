@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"bytebattle/internal/apierr"
+	"bytebattle/internal/executor"
+	"bytebattle/internal/problems"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+// doublingExecutor prints twice the number it reads.
+type doublingExecutor struct{}
+
+func (doublingExecutor) Run(_ context.Context, req executor.ExecutionRequest) (executor.ExecutionResult, error) {
+	n, _ := strconv.Atoi(strings.TrimSpace(req.Stdin))
+	return executor.ExecutionResult{Stdout: strconv.Itoa(2*n) + "\n", Stderr: "debug"}, nil
+}
+
+func (doublingExecutor) IsReady() bool { return true }
+
+func newDoublingSubmissionService() *SubmissionService {
+	return &SubmissionService{execSvc: NewExecutionService(doublingExecutor{}, RateLimitConfig{Rate: rate.Inf, Burst: 1})}
+}
+
+func TestExecuteAgainstProblem_SampleFailureShowsDiff(t *testing.T) {
+	s := newDoublingSubmissionService()
+	problem := &problems.Problem{TestCases: []problems.TestCase{
+		{Name: "samples/01", Input: "2\n", Expected: "5\n", Sample: true},
+		{Name: "01", Input: "3\n", Expected: "6\n"},
+	}}
+
+	outcome, err := s.executeAgainstProblem(context.Background(), problem, problem.TestCases, runLimits{}, "x", "go", false)
+	require.NoError(t, err)
+	assert.Equal(t, problems.VerdictWrongAnswer, outcome.verdict)
+	require.NotNil(t, outcome.failedTest)
+	assert.Equal(t, 0, *outcome.failedTest)
+	assert.Equal(t, "4\n", outcome.stdout)
+	assert.Equal(t, "2\n", outcome.input)
+	assert.Equal(t, "5\n", outcome.expected)
+}
+
+func TestExecuteAgainstProblem_HiddenFailureShowsVerdictOnly(t *testing.T) {
+	s := newDoublingSubmissionService()
+	problem := &problems.Problem{TestCases: []problems.TestCase{
+		{Name: "samples/01", Input: "2\n", Expected: "4\n", Sample: true},
+		{Name: "01", Input: "3\n", Expected: "7\n"},
+	}}
+
+	outcome, err := s.executeAgainstProblem(context.Background(), problem, problem.TestCases, runLimits{}, "x", "go", false)
+	require.NoError(t, err)
+	assert.Equal(t, problems.VerdictWrongAnswer, outcome.verdict)
+	require.NotNil(t, outcome.failedTest)
+	assert.Equal(t, 1, *outcome.failedTest)
+	assert.Empty(t, outcome.stdout)
+	assert.Empty(t, outcome.stderr)
+	assert.Empty(t, outcome.input)
+	assert.Empty(t, outcome.expected)
+}
+
+func TestExecuteAgainstProblem_AllTestsKeepsRunning(t *testing.T) {
+	s := newDoublingSubmissionService()
+	problem := &problems.Problem{TestCases: []problems.TestCase{
+		{Name: "samples/01", Input: "2\n", Expected: "4\n", Sample: true},
+		{Name: "01", Input: "3\n", Expected: "7\n"},
+		{Name: "02", Input: "5\n", Expected: "10\n"},
+	}}
+
+	outcome, err := s.executeAgainstProblem(context.Background(), problem, problem.TestCases, runLimits{}, "x", "go", true)
+	require.NoError(t, err)
+	assert.Equal(t, problems.VerdictWrongAnswer, outcome.verdict)
+	require.NotNil(t, outcome.failedTest)
+	assert.Equal(t, 1, *outcome.failedTest)
+	require.Len(t, outcome.tests, 3)
+	assert.Equal(t, problems.VerdictAccepted, outcome.tests[2].Verdict)
+	assert.Empty(t, outcome.stdout, "the output of the failed hidden test is withheld")
+}
+
+// checkingDoublingExecutor runs programs as doublingExecutor does and
+// checkers in batches, accepting any output that has the answer's parity.
+type checkingDoublingExecutor struct {
+	doublingExecutor
+	checkerBatches *int
+}
+
+func (e checkingDoublingExecutor) RunBatch(ctx context.Context, req executor.BatchRequest, onResult func(i int, res executor.ExecutionResult) bool) error {
+	if req.Language != "python" {
+		return executor.RunBatch(ctx, e.doublingExecutor, req, onResult)
+	}
+	*e.checkerBatches++
+	for i, files := range req.Files {
+		out, _ := strconv.Atoi(strings.TrimSpace(files["output.txt"]))
+		ans, _ := strconv.Atoi(strings.TrimSpace(files["answer.txt"]))
+		res := executor.ExecutionResult{}
+		if out%2 != ans%2 {
+			res.ExitCode = 1
+		}
+		if !onResult(i, res) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func TestExecuteAgainstProblem_CheckerRunsOnceForAllTests(t *testing.T) {
+	batches := 0
+	s := &SubmissionService{execSvc: NewExecutionService(checkingDoublingExecutor{checkerBatches: &batches}, RateLimitConfig{Rate: rate.Inf, Burst: 1})}
+	problem := &problems.Problem{
+		Checker: &problems.Checker{Code: "check()", Language: "python"},
+		TestCases: []problems.TestCase{
+			{Name: "01", Input: "2\n", Expected: "6\n"},
+			{Name: "02", Input: "3\n", Expected: "7\n"},
+			{Name: "03", Input: "5\n", Expected: "10\n"},
+		},
+	}
+
+	outcome, err := s.executeAgainstProblem(context.Background(), problem, problem.TestCases, runLimits{}, "x", "go", false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, batches)
+	assert.Equal(t, problems.VerdictWrongAnswer, outcome.verdict)
+	require.NotNil(t, outcome.failedTest)
+	assert.Equal(t, 1, *outcome.failedTest)
+	require.Len(t, outcome.tests, 2)
+	assert.Equal(t, problems.VerdictAccepted, outcome.tests[0].Verdict)
+}
+
+// repeatingExecutor prints its input's number of x's, keeping only
+// OutputLimit bytes of them as the real executors do.
+type repeatingExecutor struct{}
+
+func (repeatingExecutor) Run(_ context.Context, req executor.ExecutionRequest) (executor.ExecutionResult, error) {
+	n, _ := strconv.Atoi(strings.TrimSpace(req.Stdin))
+	out := strings.Repeat("x", n)
+	limit := req.OutputLimit
+	if limit == 0 {
+		limit = executor.DefaultOutputLimit
+	}
+	if len(out) > limit {
+		out = out[:limit] + "...[truncated]"
+	}
+	return executor.ExecutionResult{Stdout: out}, nil
+}
+
+func (repeatingExecutor) IsReady() bool { return true }
+
+func TestExecuteAgainstProblem_LargeExpectedOutput(t *testing.T) {
+	s := &SubmissionService{execSvc: NewExecutionService(repeatingExecutor{}, RateLimitConfig{Rate: rate.Inf, Burst: 1})}
+	problem := &problems.Problem{TestCases: []problems.TestCase{
+		{Name: "gen-001", Input: "20000\n", Expected: strings.Repeat("x", 20000) + "\n"},
+	}}
+
+	outcome, err := s.executeAgainstProblem(context.Background(), problem, problem.TestCases, runLimits{}, "x", "go", false)
+	require.NoError(t, err)
+	assert.Equal(t, problems.VerdictAccepted, outcome.verdict)
+}
+
+// interactiveDoublingExecutor plays interactions in which the interactor
+// accepts twice the number of the test, as the answer file says. The
+// program's answer is the number in its code.
+type interactiveDoublingExecutor struct{ doublingExecutor }
+
+func (interactiveDoublingExecutor) RunInteractive(_ context.Context, req executor.InteractiveRequest, onResult func(i int, res executor.InteractiveResult) bool) error {
+	for i, files := range req.Tests {
+		res := executor.InteractiveResult{Program: executor.ExecutionResult{Stdout: req.Code + "\n"}}
+		if strings.TrimSpace(files["answer.txt"]) != req.Code {
+			res.Interactor = executor.ExecutionResult{ExitCode: 1, Stderr: "wrong guess"}
+		}
+		if !onResult(i, res) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func TestExecuteAgainstProblem_InteractorDecidesVerdict(t *testing.T) {
+	s := &SubmissionService{execSvc: NewExecutionService(interactiveDoublingExecutor{}, RateLimitConfig{Rate: rate.Inf, Burst: 1})}
+	problem := &problems.Problem{
+		Interactor: &problems.Interactor{Code: "interact()", Language: "python"},
+		TestCases: []problems.TestCase{
+			{Name: "samples/01", Input: "2\n", Expected: "4\n", Sample: true},
+			{Name: "01", Input: "3\n", Expected: "6\n"},
+		},
+	}
+
+	outcome, err := s.executeAgainstProblem(context.Background(), problem, problem.TestCases, runLimits{}, "4", "go", false)
+	require.NoError(t, err)
+	assert.Equal(t, problems.VerdictWrongAnswer, outcome.verdict)
+	require.NotNil(t, outcome.failedTest)
+	assert.Equal(t, 1, *outcome.failedTest)
+	require.Len(t, outcome.tests, 2)
+	assert.Equal(t, problems.VerdictAccepted, outcome.tests[0].Verdict)
+}
+
+// codeRecordingExecutor records the code it was asked to run.
+type codeRecordingExecutor struct{ code *string }
+
+func (e codeRecordingExecutor) Run(_ context.Context, req executor.ExecutionRequest) (executor.ExecutionResult, error) {
+	*e.code = req.Code
+	return executor.ExecutionResult{Stdout: "[0,1]\n"}, nil
+}
+
+func (codeRecordingExecutor) IsReady() bool { return true }
+
+func TestExecuteAgainstProblem_FunctionRunsHarness(t *testing.T) {
+	var code string
+	s := &SubmissionService{execSvc: NewExecutionService(codeRecordingExecutor{&code}, RateLimitConfig{Rate: rate.Inf, Burst: 1})}
+	problem := &problems.Problem{
+		Manifest: problems.Manifest{Function: &problems.FunctionSignature{
+			Name:    "twoSum",
+			Params:  []problems.FunctionParam{{Name: "nums", Type: "int[]"}, {Name: "target", Type: "int"}},
+			Returns: "int[]",
+		}},
+		TestCases: []problems.TestCase{{Name: "01", Input: "[2,7]\n9\n", Expected: "[0, 1]\n"}},
+	}
+
+	outcome, err := s.executeAgainstProblem(context.Background(), problem, problem.TestCases, runLimits{}, "class Solution: ...\n", "python", false)
+	require.NoError(t, err)
+	assert.Equal(t, problems.VerdictAccepted, outcome.verdict)
+	assert.True(t, strings.HasPrefix(code, "class Solution: ...\n"))
+	assert.Contains(t, code, "Solution().twoSum(*args)")
+
+	_, err = s.executeAgainstProblem(context.Background(), problem, problem.TestCases, runLimits{}, "echo", "sh", false)
+	var appErr *apierr.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apierr.ErrValidation, appErr.ErrorCode)
+}
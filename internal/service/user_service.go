@@ -29,6 +29,24 @@ func (s *UserService) GetStats(ctx context.Context, id uuid.UUID) (sqlcdb.GetUse
 	return s.q.GetUserStats(ctx, uuid.NullUUID{UUID: id, Valid: true})
 }
 
+// GetRatingHistory returns the user's most recent rating changes, newest first.
+func (s *UserService) GetRatingHistory(ctx context.Context, id uuid.UUID, limit int) ([]sqlcdb.ListUserRatingHistoryRow, error) {
+	return s.q.ListUserRatingHistory(ctx, sqlcdb.ListUserRatingHistoryParams{UserID: id, Limit: int32(limit)})
+}
+
+// GetLeaderboard ranks users who have played at least one rated game.
+func (s *UserService) GetLeaderboard(ctx context.Context, limit, offset int) ([]sqlcdb.GetLeaderboardRow, int64, error) {
+	total, err := s.q.CountLeaderboard(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	rows, err := s.q.GetLeaderboard(ctx, sqlcdb.GetLeaderboardParams{Limit: int32(limit), Offset: int32(offset)})
+	if err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
 func (s *UserService) UpdateName(ctx context.Context, id uuid.UUID, name string) (sqlcdb.User, error) {
 	trimmed := strings.TrimSpace(name)
 	if trimmed == "" || len(trimmed) > 100 {
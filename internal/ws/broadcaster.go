@@ -0,0 +1,34 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// Envelope is a hub message on its way to the other instances.
+type Envelope struct {
+	// Origin identifies the publishing hub, which ignores its own envelopes.
+	Origin string `json:"origin"`
+	GameID int32  `json:"game_id"`
+	// UserID addresses a single player; uuid.Nil means the whole room.
+	UserID uuid.UUID `json:"user_id,omitempty"`
+	// Signal, if set, is passed to the hub's signal handler instead of
+	// sending Msg.
+	Signal string `json:"signal,omitempty"`
+	// Spectators, if set, is how many spectators the publishing hub has in
+	// the room, see AnnounceSpectators.
+	Spectators *int            `json:"spectators,omitempty"`
+	Msg        json.RawMessage `json:"msg,omitempty"`
+}
+
+// Broadcaster carries envelopes between the hubs of several instances.
+// Delivery is best effort: envelopes published while an instance is
+// disconnected from the broadcaster are lost.
+type Broadcaster interface {
+	Publish(ctx context.Context, env Envelope) error
+	// Run calls deliver for every envelope published by any instance,
+	// including this one, until ctx is done.
+	Run(ctx context.Context, deliver func(Envelope))
+}
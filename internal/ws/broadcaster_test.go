@@ -0,0 +1,199 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestHub returns a hub publishing through b that is closed when the
+// test ends.
+func newTestHub(t *testing.T, b Broadcaster) *Hub {
+	t.Helper()
+	h := NewHub(WithBroadcaster(b))
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = h.Close(ctx)
+	})
+	return h
+}
+
+// memBus connects the hubs of a test the way Postgres connects instances.
+type memBus struct {
+	mu        sync.Mutex
+	delivers  []func(Envelope)
+	published int
+}
+
+func (b *memBus) Publish(_ context.Context, env Envelope) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.published++
+	for _, deliver := range b.delivers {
+		deliver(env)
+	}
+	return nil
+}
+
+func (b *memBus) Run(ctx context.Context, deliver func(Envelope)) {
+	b.mu.Lock()
+	b.delivers = append(b.delivers, deliver)
+	b.mu.Unlock()
+	<-ctx.Done()
+}
+
+func (b *memBus) hubs(t *testing.T, n int) []*Hub {
+	t.Helper()
+	hubs := make([]*Hub, n)
+	for i := range hubs {
+		hubs[i] = newTestHub(t, b)
+	}
+	require.Eventually(t, func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return len(b.delivers) == n
+	}, time.Second, time.Millisecond)
+	return hubs
+}
+
+func TestHub_BroadcastReachesOtherInstances(t *testing.T) {
+	hubs := (&memBus{}).hubs(t, 2)
+	local := &Client{send: make(chan []byte, 8)}
+	remote := &Client{send: make(chan []byte, 8)}
+	hubs[0].Join(1, local)
+	hubs[1].Join(1, remote)
+
+	hubs[0].Broadcast(1, []byte(`"hi"`))
+
+	assert.Equal(t, []byte(`"hi"`), <-local.send)
+	assert.Equal(t, []byte(`"hi"`), <-remote.send)
+	assert.Empty(t, local.send, "own envelope must not be delivered twice")
+}
+
+// stuckBus is a broadcaster whose Publish waits for release.
+type stuckBus struct {
+	release   chan struct{}
+	published chan Envelope
+}
+
+func (b *stuckBus) Publish(ctx context.Context, env Envelope) error {
+	select {
+	case <-b.release:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	b.published <- env
+	return nil
+}
+
+func (b *stuckBus) Run(ctx context.Context, _ func(Envelope)) { <-ctx.Done() }
+
+func TestHub_BroadcastDoesNotWaitForPublish(t *testing.T) {
+	bus := &stuckBus{release: make(chan struct{}), published: make(chan Envelope, 8)}
+	h := newTestHub(t, bus)
+	c := &Client{send: make(chan []byte, 8)}
+	h.Join(1, c)
+
+	h.Broadcast(1, []byte(`"1"`))
+	h.Broadcast(1, []byte(`"2"`))
+	assert.Equal(t, []byte(`"1"`), <-c.send)
+	assert.Equal(t, []byte(`"2"`), <-c.send)
+
+	close(bus.release)
+	assert.Equal(t, json.RawMessage(`"1"`), (<-bus.published).Msg)
+	assert.Equal(t, json.RawMessage(`"2"`), (<-bus.published).Msg, "envelopes are published in order")
+}
+
+func TestHub_ClosePublishesQueuedEnvelopes(t *testing.T) {
+	bus := &stuckBus{release: make(chan struct{}), published: make(chan Envelope, 8)}
+	h := NewHub(WithBroadcaster(bus))
+	h.Broadcast(1, []byte(`"1"`))
+	h.Broadcast(1, []byte(`"2"`))
+
+	closed := make(chan error, 1)
+	go func() { closed <- h.Close(context.Background()) }()
+	close(bus.release)
+	require.NoError(t, <-closed)
+	require.Len(t, bus.published, 2)
+	assert.Equal(t, json.RawMessage(`"1"`), (<-bus.published).Msg)
+	assert.Equal(t, json.RawMessage(`"2"`), (<-bus.published).Msg)
+
+	h.Broadcast(1, []byte(`"3"`))
+	assert.Empty(t, bus.published, "nothing is published after Close")
+}
+
+func TestHub_CloseGivesUpWhenContextEnds(t *testing.T) {
+	bus := &stuckBus{release: make(chan struct{}), published: make(chan Envelope, 8)}
+	h := NewHub(WithBroadcaster(bus))
+	h.Broadcast(1, []byte(`"1"`))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, h.Close(ctx), context.DeadlineExceeded)
+	assert.Empty(t, bus.published)
+}
+
+func TestHub_SendToUserAcrossInstances(t *testing.T) {
+	bus := &memBus{}
+	hubs := bus.hubs(t, 2)
+	userID := uuid.New()
+	c := &Client{UserID: userID, send: make(chan []byte, 8)}
+	hubs[1].Join(1, c)
+
+	hubs[1].SendToUser(1, userID, []byte(`"local"`))
+	assert.Equal(t, []byte(`"local"`), <-c.send)
+	assert.Zero(t, bus.published, "a locally connected user needs no fan-out")
+
+	hubs[0].SendToUser(1, userID, []byte(`"remote"`))
+	assert.Equal(t, []byte(`"remote"`), <-c.send)
+}
+
+func TestHub_SignalAcrossInstances(t *testing.T) {
+	hubs := (&memBus{}).hubs(t, 2)
+	userID := uuid.New()
+	c := &Client{UserID: userID, send: make(chan []byte, 8)}
+	hubs[1].Join(7, c)
+
+	got := make(chan string, 8)
+	hubs[1].HandleSignals(func(gameID int32, client *Client, signal string) {
+		assert.Equal(t, int32(7), gameID)
+		assert.Same(t, c, client)
+		got <- signal
+	})
+
+	// Envelopes are published in order, so the other user's signal has been
+	// dealt with by the time the ping arrives.
+	hubs[0].Signal(7, uuid.New(), "other user")
+	hubs[0].Signal(7, userID, "ping")
+	assert.Equal(t, "ping", <-got)
+	assert.Empty(t, got)
+}
+
+func TestHub_SpectatorCountAcrossInstances(t *testing.T) {
+	hubs := (&memBus{}).hubs(t, 2)
+	changed := make(chan int32, 8)
+	hubs[0].HandleSpectators(func(gameID int32) { changed <- gameID })
+	hubs[0].Join(1, &Client{Spectator: true, send: make(chan []byte, 8)})
+	spectator := &Client{Spectator: true, send: make(chan []byte, 8)}
+	hubs[1].Join(1, spectator)
+	hubs[1].Join(1, &Client{send: make(chan []byte, 8)})
+
+	hubs[1].AnnounceSpectators(1)
+	assert.Equal(t, int32(1), <-changed)
+	assert.Equal(t, 2, hubs[0].SpectatorCount(1))
+	assert.Equal(t, 1, hubs[1].SpectatorCount(1), "not announced by the first hub")
+
+	hubs[1].AnnounceSpectators(1)
+	hubs[1].Leave(1, spectator)
+	hubs[1].AnnounceSpectators(1)
+	assert.Equal(t, int32(1), <-changed)
+	assert.Empty(t, changed, "an unchanged count is not handled again")
+	assert.Equal(t, 1, hubs[0].SpectatorCount(1))
+}
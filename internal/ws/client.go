@@ -1,6 +1,7 @@
 package ws
 
 import (
+	"encoding/json"
 	"sync"
 	"time"
 
@@ -16,11 +17,17 @@ const (
 
 type Client struct {
 	UserID uuid.UUID
-	send   chan []byte
-	conn   *websocket.Conn
-	mu     sync.Mutex
-	closed bool
-	once   sync.Once
+	// Spectator clients watch a public game they do not play in. They get the
+	// room's broadcasts but no messages addressed to a user.
+	Spectator bool
+	send      chan []byte
+	conn      *websocket.Conn
+	mu        sync.Mutex
+	closed    bool
+	// While holding, messages are kept in held instead of the send buffer,
+	// see hold.
+	holding bool
+	held    [][]byte
 }
 
 func NewClient(conn *websocket.Conn, userID uuid.UUID) *Client {
@@ -33,24 +40,124 @@ func NewClient(conn *websocket.Conn, userID uuid.UUID) *Client {
 
 // Close signals WritePump to exit cleanly by closing the send channel.
 func (c *Client) Close() {
-	c.once.Do(func() {
-		c.mu.Lock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closeLocked()
+}
+
+func (c *Client) closeLocked() {
+	if !c.closed {
 		c.closed = true
 		close(c.send)
-		c.mu.Unlock()
-	})
+	}
 }
 
-// Send enqueues a message for this client. Non-blocking; drops if buffer is full or client is closed.
+// Send enqueues a message for this client. Non-blocking; a client whose
+// buffer is full is too slow to keep up and is closed rather than silently
+// missing messages, so that it reconnects and replays what it missed.
 func (c *Client) Send(msg []byte) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	if c.closed {
 		return
 	}
+	if c.holding {
+		if len(c.held) >= maxHeld {
+			c.closeLocked()
+			return
+		}
+		c.held = append(c.held, msg)
+		return
+	}
 	select {
 	case c.send <- msg:
 	default:
+		c.closeLocked()
+	}
+}
+
+// maxHeld bounds the messages held back for a client, see hold.
+const maxHeld = 1024
+
+// sendPollInterval is how often SendAll checks for buffer space.
+const sendPollInterval = 10 * time.Millisecond
+
+// SendAll enqueues msgs in order, waiting for buffer space rather than
+// treating a full buffer as a slow client. Used to replay missed events,
+// which may not fit the buffer at once. msgs go ahead of the messages held
+// back by hold, which are sent after them; held room events that msgs
+// already include are dropped. The client's lock is only taken to enqueue,
+// so that Send never waits on a slow replay.
+func (c *Client) SendAll(msgs [][]byte) {
+	var replayed int64
+	for _, msg := range msgs {
+		replayed = max(replayed, eventSeq(msg))
+	}
+	c.mu.Lock()
+	c.holding = true
+	held := c.held[:0:0]
+	for _, msg := range c.held {
+		if seq := eventSeq(msg); seq == 0 || seq > replayed {
+			held = append(held, msg)
+		}
+	}
+	c.held = append(msgs[:len(msgs):len(msgs)], held...)
+	c.mu.Unlock()
+	c.release()
+}
+
+// eventSeq returns the Seq of a room event, or 0 for other messages.
+func eventSeq(msg []byte) int64 {
+	var event struct {
+		Seq int64 `json:"seq"`
+	}
+	if json.Unmarshal(msg, &event) != nil {
+		return 0
+	}
+	return event.Seq
+}
+
+// hold makes Send keep messages back until release, for a client that is
+// being sent what it missed.
+func (c *Client) hold() {
+	c.mu.Lock()
+	c.holding = true
+	c.mu.Unlock()
+}
+
+// release enqueues the held messages, waiting for buffer space, and ends
+// holding. A client whose buffer stays full for writeWait is closed.
+func (c *Client) release() {
+	deadline := time.Now().Add(writeWait)
+	for {
+		c.mu.Lock()
+		if c.closed {
+			c.held = nil
+			c.mu.Unlock()
+			return
+		}
+		for len(c.held) > 0 {
+			select {
+			case c.send <- c.held[0]:
+				c.held = c.held[1:]
+				deadline = time.Now().Add(writeWait)
+				continue
+			default:
+			}
+			break
+		}
+		if len(c.held) == 0 {
+			c.holding, c.held = false, nil
+			c.mu.Unlock()
+			return
+		}
+		if time.Now().After(deadline) {
+			c.closeLocked()
+			c.mu.Unlock()
+			return
+		}
+		c.mu.Unlock()
+		time.Sleep(sendPollInterval)
 	}
 }
 
@@ -1,15 +1,56 @@
 package ws
 
 import (
+	"context"
+	"log"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-// Hub manages WebSocket rooms, one room per game.
+const (
+	publishTimeout = 5 * time.Second
+	// publishQueueSize bounds the envelopes waiting for the broadcaster;
+	// further ones are dropped rather than holding up the hub's callers.
+	publishQueueSize = 1024
+	// spectatorsInterval is how often a hub announces its spectators to the
+	// other instances; an instance not heard from for spectatorsExpiry is
+	// taken to have none left, e.g. because it crashed.
+	spectatorsInterval = 30 * time.Second
+	spectatorsExpiry   = 3 * spectatorsInterval
+)
+
+// Hub manages WebSocket rooms, one room per game. Messages are delivered to
+// local clients first and then, if the hub has a Broadcaster, published to
+// the hubs of the other instances.
 type Hub struct {
 	mu    sync.RWMutex
 	rooms map[int32]*room
+
+	origin       string
+	broadcaster  Broadcaster
+	onSignal     func(gameID int32, c *Client, signal string)
+	onSpectators func(gameID int32)
+	// remoteSpectators counts the spectators of each room on the other
+	// instances by their hub's origin. Guarded by mu.
+	remoteSpectators map[int32]map[string]remoteSpectators
+
+	// outbox holds the envelopes waiting to be published; it is closed, under
+	// outboxMu, when the hub is. published is closed once publishLoop has
+	// handed all of them to the broadcaster.
+	outboxMu  sync.RWMutex
+	outbox    chan Envelope
+	closed    bool
+	published chan struct{}
+	dropped   atomic.Int64
+	stop      context.CancelFunc
+}
+
+type remoteSpectators struct {
+	count int
+	seen  time.Time
 }
 
 type room struct {
@@ -17,11 +58,81 @@ type room struct {
 	clients map[*Client]struct{}
 }
 
-func NewHub() *Hub {
-	return &Hub{rooms: make(map[int32]*room)}
+type HubOption func(*Hub)
+
+// WithBroadcaster fans the hub's messages out to other instances through b.
+func WithBroadcaster(b Broadcaster) HubOption {
+	return func(h *Hub) {
+		h.broadcaster = b
+	}
+}
+
+func NewHub(opts ...HubOption) *Hub {
+	h := &Hub{
+		rooms:            make(map[int32]*room),
+		remoteSpectators: make(map[int32]map[string]remoteSpectators),
+		origin:           uuid.NewString(),
+		stop:             func() {},
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.broadcaster != nil {
+		ctx, stop := context.WithCancel(context.Background())
+		h.stop = stop
+		h.outbox = make(chan Envelope, publishQueueSize)
+		h.published = make(chan struct{})
+		go h.broadcaster.Run(ctx, h.receive)
+		go h.publishLoop(ctx)
+		go h.announceSpectatorsLoop(ctx)
+	}
+	return h
+}
+
+// Close stops exchanging messages with other instances. The messages still
+// queued are published first, unless ctx ends before they are.
+func (h *Hub) Close(ctx context.Context) error {
+	defer h.stop()
+	if h.broadcaster == nil {
+		return nil
+	}
+	h.outboxMu.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.outbox)
+	}
+	h.outboxMu.Unlock()
+
+	select {
+	case <-h.published:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// HandleSignals sets the function called for every local connection of the
+// user a Signal is addressed to.
+func (h *Hub) HandleSignals(fn func(gameID int32, c *Client, signal string)) {
+	h.mu.Lock()
+	h.onSignal = fn
+	h.mu.Unlock()
 }
 
 func (h *Hub) Join(gameID int32, c *Client) {
+	h.JoinAfter(gameID, c, nil)
+}
+
+// JoinAfter adds c to the room and runs before, if not nil, so that the
+// messages before sends to c, such as replayed events, reach it ahead of any
+// new ones. The room's messages for c are held back by c meanwhile rather
+// than the room waiting: before may be slow.
+func (h *Hub) JoinAfter(gameID int32, c *Client, before func()) {
+	if before != nil {
+		c.hold()
+		defer c.release()
+	}
+
 	h.mu.Lock()
 	r, ok := h.rooms[gameID]
 	if !ok {
@@ -33,6 +144,9 @@ func (h *Hub) Join(gameID int32, c *Client) {
 	r.mu.Lock()
 	r.clients[c] = struct{}{}
 	r.mu.Unlock()
+	if before != nil {
+		before()
+	}
 }
 
 func (h *Hub) Leave(gameID int32, c *Client) {
@@ -55,6 +169,159 @@ func (h *Hub) Leave(gameID int32, c *Client) {
 }
 
 func (h *Hub) Broadcast(gameID int32, msg []byte) {
+	h.BroadcastLocal(gameID, msg)
+	h.publish(Envelope{GameID: gameID, Msg: msg})
+}
+
+// SendToUser sends msg to one of the user's connections. Other instances are
+// only asked when the user is not connected to this one.
+func (h *Hub) SendToUser(gameID int32, userID uuid.UUID, msg []byte) {
+	if h.sendToUserLocal(gameID, userID, msg) {
+		return
+	}
+	h.publish(Envelope{GameID: gameID, UserID: userID, Msg: msg})
+}
+
+// Signal asks whichever instance the user is connected to to run the
+// HandleSignals function for their connections.
+func (h *Hub) Signal(gameID int32, userID uuid.UUID, signal string) {
+	h.signalLocal(gameID, userID, signal)
+	h.publish(Envelope{GameID: gameID, UserID: userID, Signal: signal})
+}
+
+// HasUser reports whether userID has a connection in the game's room on this
+// instance.
+func (h *Hub) HasUser(gameID int32, userID uuid.UUID) bool {
+	return len(h.userClients(gameID, userID)) > 0
+}
+
+// SpectatorCount returns the number of spectators in the game's room on
+// all instances. Those of other instances are known from their
+// announcements, see AnnounceSpectators.
+func (h *Hub) SpectatorCount(gameID int32) int {
+	n := h.localSpectators(gameID)
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, remote := range h.remoteSpectators[gameID] {
+		if time.Since(remote.seen) < spectatorsExpiry {
+			n += remote.count
+		}
+	}
+	return n
+}
+
+func (h *Hub) localSpectators(gameID int32) int {
+	h.mu.RLock()
+	r, ok := h.rooms[gameID]
+	h.mu.RUnlock()
+	if !ok {
+		return 0
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for c := range r.clients {
+		if c.Spectator {
+			n++
+		}
+	}
+	return n
+}
+
+// HandleSpectators sets the function called when another instance announces
+// a change of its spectators in a room.
+func (h *Hub) HandleSpectators(fn func(gameID int32)) {
+	h.mu.Lock()
+	h.onSpectators = fn
+	h.mu.Unlock()
+}
+
+// AnnounceSpectators tells the other instances how many spectators the
+// game's room has on this one, after a spectator joined or left. Rooms with
+// spectators are also announced every spectatorsInterval, so that they are
+// known to instances started since and not expired.
+func (h *Hub) AnnounceSpectators(gameID int32) {
+	n := h.localSpectators(gameID)
+	h.publish(Envelope{GameID: gameID, Spectators: &n})
+}
+
+func (h *Hub) announceSpectatorsLoop(ctx context.Context) {
+	ticker := time.NewTicker(spectatorsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			h.expireRemoteSpectators()
+			for _, gameID := range h.roomIDs() {
+				if h.localSpectators(gameID) > 0 {
+					h.AnnounceSpectators(gameID)
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// expireRemoteSpectators forgets the spectators of instances not heard from
+// for spectatorsExpiry.
+func (h *Hub) expireRemoteSpectators() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for gameID, remotes := range h.remoteSpectators {
+		for origin, remote := range remotes {
+			if time.Since(remote.seen) >= spectatorsExpiry {
+				delete(remotes, origin)
+			}
+		}
+		if len(remotes) == 0 {
+			delete(h.remoteSpectators, gameID)
+		}
+	}
+}
+
+func (h *Hub) roomIDs() []int32 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	gameIDs := make([]int32, 0, len(h.rooms))
+	for gameID := range h.rooms {
+		gameIDs = append(gameIDs, gameID)
+	}
+	return gameIDs
+}
+
+// setRemoteSpectators records the spectators another instance announced and
+// runs the HandleSpectators function if their number changed.
+func (h *Hub) setRemoteSpectators(gameID int32, origin string, count int) {
+	h.mu.Lock()
+	remotes := h.remoteSpectators[gameID]
+	prev := remotes[origin].count
+	if time.Since(remotes[origin].seen) >= spectatorsExpiry {
+		prev = 0
+	}
+	if count > 0 {
+		if remotes == nil {
+			remotes = make(map[string]remoteSpectators)
+			h.remoteSpectators[gameID] = remotes
+		}
+		remotes[origin] = remoteSpectators{count: count, seen: time.Now()}
+	} else {
+		delete(remotes, origin)
+		if len(remotes) == 0 {
+			delete(h.remoteSpectators, gameID)
+		}
+	}
+	fn := h.onSpectators
+	h.mu.Unlock()
+
+	if count != prev && fn != nil {
+		fn(gameID)
+	}
+}
+
+// BroadcastLocal sends msg to the room's clients on this instance only.
+func (h *Hub) BroadcastLocal(gameID int32, msg []byte) {
 	h.mu.RLock()
 	r, ok := h.rooms[gameID]
 	h.mu.RUnlock()
@@ -69,20 +336,95 @@ func (h *Hub) Broadcast(gameID int32, msg []byte) {
 	}
 }
 
-func (h *Hub) SendToUser(gameID int32, userID uuid.UUID, msg []byte) {
+func (h *Hub) sendToUserLocal(gameID int32, userID uuid.UUID, msg []byte) bool {
+	clients := h.userClients(gameID, userID)
+	if len(clients) == 0 {
+		return false
+	}
+	clients[0].Send(msg)
+	return true
+}
+
+func (h *Hub) signalLocal(gameID int32, userID uuid.UUID, signal string) {
+	h.mu.RLock()
+	fn := h.onSignal
+	h.mu.RUnlock()
+	if fn == nil {
+		return
+	}
+	for _, c := range h.userClients(gameID, userID) {
+		fn(gameID, c, signal)
+	}
+}
+
+func (h *Hub) userClients(gameID int32, userID uuid.UUID) []*Client {
 	h.mu.RLock()
 	r, ok := h.rooms[gameID]
 	h.mu.RUnlock()
 	if !ok {
-		return
+		return nil
 	}
 
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	var clients []*Client
 	for c := range r.clients {
-		if c.UserID == userID {
-			c.Send(msg)
-			return
+		if c.UserID == userID && !c.Spectator {
+			clients = append(clients, c)
 		}
 	}
+	return clients
+}
+
+// publish queues env for the other instances without waiting for the
+// broadcaster.
+func (h *Hub) publish(env Envelope) {
+	if h.broadcaster == nil {
+		return
+	}
+	env.Origin = h.origin
+	h.outboxMu.RLock()
+	defer h.outboxMu.RUnlock()
+	if h.closed {
+		return
+	}
+	select {
+	case h.outbox <- env:
+	default:
+		log.Printf("ws hub: publish queue full, dropping message to game %d (%d dropped)", env.GameID, h.dropped.Add(1))
+	}
+}
+
+// publishLoop hands the queued envelopes to the broadcaster one at a time,
+// in order, until the outbox is closed. Once ctx is done the rest are
+// dropped.
+func (h *Hub) publishLoop(ctx context.Context) {
+	defer close(h.published)
+	for env := range h.outbox {
+		if ctx.Err() != nil {
+			continue
+		}
+		pubCtx, cancel := context.WithTimeout(ctx, publishTimeout)
+		if err := h.broadcaster.Publish(pubCtx, env); err != nil && ctx.Err() == nil {
+			log.Printf("ws hub: publish to game %d: %v", env.GameID, err)
+		}
+		cancel()
+	}
+}
+
+// receive delivers an envelope published by another instance.
+func (h *Hub) receive(env Envelope) {
+	if env.Origin == h.origin {
+		return
+	}
+	switch {
+	case env.Signal != "":
+		h.signalLocal(env.GameID, env.UserID, env.Signal)
+	case env.Spectators != nil:
+		h.setRemoteSpectators(env.GameID, env.Origin, *env.Spectators)
+	case env.UserID == uuid.Nil:
+		h.BroadcastLocal(env.GameID, env.Msg)
+	default:
+		h.sendToUserLocal(env.GameID, env.UserID, env.Msg)
+	}
 }
@@ -4,6 +4,7 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -37,26 +38,124 @@ func TestHub_LeaveStopsBroadcast(t *testing.T) {
 	}
 }
 
+func TestHub_HasUser(t *testing.T) {
+	h := NewHub()
+	userID := uuid.New()
+	c := &Client{UserID: userID, send: make(chan []byte, 8)}
+
+	assert.False(t, h.HasUser(1, userID))
+	h.Join(1, c)
+	assert.True(t, h.HasUser(1, userID))
+	assert.False(t, h.HasUser(2, userID))
+	assert.False(t, h.HasUser(1, uuid.New()))
+	h.Leave(1, c)
+	assert.False(t, h.HasUser(1, userID))
+}
+
+func TestHub_Spectators(t *testing.T) {
+	h := NewHub()
+	userID := uuid.New()
+	spectator := &Client{UserID: userID, Spectator: true, send: make(chan []byte, 8)}
+	player := &Client{UserID: uuid.New(), send: make(chan []byte, 8)}
+	h.Join(1, spectator)
+	h.Join(1, player)
+
+	assert.Equal(t, 1, h.SpectatorCount(1))
+	assert.False(t, h.HasUser(1, userID), "spectators are not players")
+
+	h.SendToUser(1, userID, []byte("private"))
+	h.Broadcast(1, []byte("room"))
+	assert.Equal(t, []byte("room"), <-spectator.send)
+
+	h.Leave(1, spectator)
+	assert.Zero(t, h.SpectatorCount(1))
+}
+
 func TestHub_BroadcastNoRoom(t *testing.T) {
 	h := NewHub()
 	// should not panic on nonexistent room
 	h.Broadcast(999, []byte("hi"))
 }
 
-func TestHub_BroadcastFullBufferDoesNotBlock(t *testing.T) {
+func TestHub_BroadcastFullBufferClosesClient(t *testing.T) {
 	h := NewHub()
 	c := &Client{send: make(chan []byte, 1)}
 	h.Join(1, c)
 
 	h.Broadcast(1, []byte("msg1")) // fills buffer
-	h.Broadcast(1, []byte("msg2")) // dropped, must not block
+	h.Broadcast(1, []byte("msg2")) // must not block; closes the slow client
+	h.Broadcast(1, []byte("msg3")) // closed client, ignored
 
 	assert.Equal(t, []byte("msg1"), <-c.send)
-	select {
-	case <-c.send:
-		t.Fatal("second message should have been dropped")
-	default:
+	_, ok := <-c.send
+	assert.False(t, ok, "send channel should be closed")
+}
+
+func TestClient_SendAllWaitsForBuffer(t *testing.T) {
+	c := &Client{send: make(chan []byte, 1)}
+	msgs := [][]byte{[]byte("1"), []byte("2"), []byte("3")}
+
+	done := make(chan struct{})
+	go func() {
+		c.SendAll(msgs)
+		close(done)
+	}()
+
+	for _, want := range msgs {
+		assert.Equal(t, want, <-c.send)
 	}
+	<-done
+	c.Send([]byte("4"))
+	assert.Equal(t, []byte("4"), <-c.send, "client must stay open")
+}
+
+func TestHub_JoinAfterDoesNotHoldUpTheRoom(t *testing.T) {
+	h := NewHub()
+	other := &Client{send: make(chan []byte, 8)}
+	h.Join(1, other)
+	c := &Client{send: make(chan []byte, 8)}
+
+	replaying, replay := make(chan struct{}), make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		h.JoinAfter(1, c, func() {
+			close(replaying)
+			<-replay
+			c.SendAll([][]byte{[]byte("old")})
+		})
+		close(done)
+	}()
+
+	<-replaying
+	h.Broadcast(1, []byte("new")) // must not wait for the replay
+	assert.Equal(t, []byte("new"), <-other.send)
+	close(replay)
+	<-done
+
+	assert.Equal(t, []byte("old"), <-c.send)
+	assert.Equal(t, []byte("new"), <-c.send, "held back until the replay")
+	c.Send([]byte("next"))
+	assert.Equal(t, []byte("next"), <-c.send)
+}
+
+func TestHub_JoinAfterSkipsReplayedEvents(t *testing.T) {
+	h := NewHub()
+	c := &Client{send: make(chan []byte, 8)}
+
+	h.JoinAfter(1, c, func() {
+		// Events 2 and 3 are broadcast while the replay is being read, so
+		// it includes event 2 but not 3.
+		h.Broadcast(1, []byte(`{"type":"x","seq":2}`))
+		h.Broadcast(1, []byte(`{"type":"error"}`))
+		h.Broadcast(1, []byte(`{"type":"x","seq":3}`))
+		c.SendAll([][]byte{[]byte(`{"type":"x","seq":1}`), []byte(`{"type":"x","seq":2}`)})
+	})
+
+	assert.Equal(t, []byte(`{"type":"x","seq":1}`), <-c.send)
+	assert.Equal(t, []byte(`{"type":"x","seq":2}`), <-c.send)
+	assert.Equal(t, []byte(`{"type":"error"}`), <-c.send)
+	assert.Equal(t, []byte(`{"type":"x","seq":3}`), <-c.send)
+	assert.Empty(t, c.send)
 }
 
 func TestHub_ConcurrentJoinLeave(t *testing.T) {
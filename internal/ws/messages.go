@@ -1,28 +1,74 @@
 package ws
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 const (
 	TypeSubmit           = "submit"
+	TypeRun              = "run"
+	TypeRunResult        = "run_result"
 	TypeSubmissionResult = "submission_result"
 	TypePlayerAdvanced   = "player_advanced"
+	TypeScoreUpdated     = "score_updated"
 	TypePlayerState      = "player_state"
 	TypeGameFinished     = "game_finished"
 	TypePlayerJoined     = "player_joined"
-	TypeError            = "error"
+	TypeSpectatorState   = "spectator_state"
+	TypeSpectators       = "spectators"
+
+	// Lobby events, sent while the game is pending.
+	TypeParticipantJoined = "participant_joined"
+	TypeParticipantLeft   = "participant_left"
+	TypeGameStarted       = "game_started"
+	TypeGameCancelled     = "game_cancelled"
+	TypeError             = "error"
+
+	// FinishReasonTimeLimit marks a game_finished sent because the game ran
+	// out of time rather than because someone solved every problem.
+	FinishReasonTimeLimit = "time_limit"
 )
 
 type ClientMessage struct {
 	Type     string `json:"type"`
 	Code     string `json:"code,omitempty"`
 	Language string `json:"language,omitempty"`
+	// Input is the stdin of a "run"; without it the code is run on the
+	// problem's sample tests.
+	Input *string `json:"input,omitempty"`
+	// ProblemIndex selects the problem of a "submit" or "run" in a scoring
+	// game; in a race it is always the player's current problem.
+	ProblemIndex *int32 `json:"problem_index,omitempty"`
+}
+
+type TestResult struct {
+	Verdict  string `json:"verdict"`
+	TimeMs   int64  `json:"time_ms"`
+	MemoryKb int64  `json:"memory_kb"`
+}
+
+type GroupResult struct {
+	Name      string `json:"name"`
+	Points    int    `json:"points"`
+	MaxPoints int    `json:"max_points"`
+	Passed    bool   `json:"passed"`
 }
 
+// ServerMessage is sent to clients. Room events (messages broadcast to the
+// whole game) carry Seq, their position in the game's event log; a client
+// reconnects with ?last_seq= set to the highest Seq it has seen. Live events
+// that the replay already includes are not sent again.
 type ServerMessage struct {
 	Type       string           `json:"type"`
+	Seq        int64            `json:"seq,omitempty"`
 	UserID     uuid.UUID        `json:"user_id,omitempty"`
 	WinnerID   uuid.UUID        `json:"winner_id,omitempty"`
+	Reason     string           `json:"reason,omitempty"`
 	Accepted   bool             `json:"accepted"`
+	Verdict    string           `json:"verdict,omitempty"`
+	Tests      []TestResult     `json:"tests,omitempty"`
 	Stdout     string           `json:"stdout,omitempty"`
 	Stderr     string           `json:"stderr,omitempty"`
 	Message    string           `json:"message,omitempty"`
@@ -33,4 +79,22 @@ type ServerMessage struct {
 	Code       string           `json:"code,omitempty"`
 	Language   string           `json:"language,omitempty"`
 	Progress   map[string]int32 `json:"progress,omitempty"`
+	// SpectatorCount is set on spectators, player_state and spectator_state.
+	SpectatorCount *int       `json:"spectator_count,omitempty"`
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	// ExitCode, TimeMs and MemoryKb describe a run_result.
+	ExitCode *int  `json:"exit_code,omitempty"`
+	TimeMs   int64 `json:"time_ms,omitempty"`
+	MemoryKb int64 `json:"memory_kb,omitempty"`
+	// Input and Expected describe the failed test of a submission_result or
+	// run_result when it is a sample; hidden tests are never shown.
+	Input    string `json:"input,omitempty"`
+	Expected string `json:"expected,omitempty"`
+	// Score, MaxScore and Groups describe the points a submission_result
+	// earned. Scores holds every player's best score per problem in a
+	// scoring game; it is sent with score_updated and the state messages.
+	Score    *int             `json:"score,omitempty"`
+	MaxScore int              `json:"max_score,omitempty"`
+	Groups   []GroupResult    `json:"groups,omitempty"`
+	Scores   map[string][]int `json:"scores,omitempty"`
 }
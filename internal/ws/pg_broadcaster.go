@@ -0,0 +1,138 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	sqlcdb "bytebattle/internal/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	pgChannel = "ws_events"
+	// NOTIFY payloads are limited to 8000 bytes. Larger envelopes are stored
+	// in ws_payloads and the notification carries the row ID.
+	maxNotifyPayload = 7900
+	pgPayloadTTL     = time.Minute
+	pgRetryDelay     = time.Second
+)
+
+// pgNotification is the NOTIFY payload: an envelope or a reference to one.
+type pgNotification struct {
+	Envelope  *Envelope `json:"envelope,omitempty"`
+	PayloadID int64     `json:"payload_id,omitempty"`
+}
+
+// PGBroadcaster is a Broadcaster over Postgres LISTEN/NOTIFY.
+type PGBroadcaster struct {
+	pool *pgxpool.Pool
+	q    *sqlcdb.Queries
+}
+
+func NewPGBroadcaster(pool *pgxpool.Pool) *PGBroadcaster {
+	return &PGBroadcaster{pool: pool, q: sqlcdb.New(pool)}
+}
+
+func (b *PGBroadcaster) Publish(ctx context.Context, env Envelope) error {
+	payload, err := json.Marshal(pgNotification{Envelope: &env})
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxNotifyPayload {
+		envJSON, err := json.Marshal(env)
+		if err != nil {
+			return err
+		}
+		id, err := b.q.InsertWSPayload(ctx, envJSON)
+		if err != nil {
+			return fmt.Errorf("store ws payload: %w", err)
+		}
+		payload, _ = json.Marshal(pgNotification{PayloadID: id})
+	}
+	return b.q.NotifyWSEvent(ctx, sqlcdb.NotifyWSEventParams{Channel: pgChannel, Payload: string(payload)})
+}
+
+// Run listens on a dedicated connection and reconnects when it is lost.
+func (b *PGBroadcaster) Run(ctx context.Context, deliver func(Envelope)) {
+	go b.cleanupLoop(ctx)
+	for {
+		err := b.listen(ctx, deliver)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("ws broadcaster: listen: %v", err)
+		select {
+		case <-time.After(pgRetryDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (b *PGBroadcaster) listen(ctx context.Context, deliver func(Envelope)) error {
+	pooled, err := b.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// The connection keeps listening, so it must not go back to the pool.
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgChannel); err != nil {
+		return err
+	}
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		env, err := b.decode(ctx, n.Payload)
+		if err != nil {
+			log.Printf("ws broadcaster: %v", err)
+			continue
+		}
+		deliver(env)
+	}
+}
+
+func (b *PGBroadcaster) decode(ctx context.Context, payload string) (Envelope, error) {
+	var n pgNotification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		return Envelope{}, fmt.Errorf("decode notification: %w", err)
+	}
+	if n.Envelope != nil {
+		return *n.Envelope, nil
+	}
+	data, err := b.q.GetWSPayload(ctx, n.PayloadID)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("load ws payload %d: %w", n.PayloadID, err)
+	}
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return Envelope{}, fmt.Errorf("decode ws payload %d: %w", n.PayloadID, err)
+	}
+	return env, nil
+}
+
+// cleanupLoop deletes stored payloads once every instance has had time to
+// read them.
+func (b *PGBroadcaster) cleanupLoop(ctx context.Context) {
+	ticker := time.NewTicker(pgPayloadTTL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			before := pgtype.Timestamptz{Time: time.Now().Add(-pgPayloadTTL), Valid: true}
+			if _, err := b.q.DeleteWSPayloadsBefore(ctx, before); err != nil && ctx.Err() == nil {
+				log.Printf("ws broadcaster: delete old payloads: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}